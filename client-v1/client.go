@@ -123,12 +123,19 @@ func NewMultiPartFormDataRequest(config edgegrid.Config, uriPath, filePath strin
 
 // Do performs a given HTTP Request, signed with the Akamai OPEN Edgegrid
 // Authorization header. An edgegrid.Response or an error is returned.
+// When Limiter is set, Do first waits for it.
 func Do(config edgegrid.Config, req *http.Request) (*http.Response, error) {
 	Client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
 		req = edgegrid.AddRequestHeader(config, req)
 		return nil
 	}
 
+	if Limiter != nil {
+		if err := Limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+
 	req = edgegrid.AddRequestHeader(config, req)
 	res, err := Client.Do(req)
 	if err != nil {
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles the requests sent by Do
+//
+// Wait blocks until a request may be sent, or returns an error when ctx is
+// done first.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// Limiter is the RateLimiter used by Do, nil disables rate limiting
+//
+// It is shared by every package using this client, so concurrent callers
+// together stay within the limit of the API credentials.
+var Limiter RateLimiter
+
+// TokenBucket is a RateLimiter allowing Rate requests per second on average,
+// with bursts of up to Burst requests
+type TokenBucket struct {
+	Rate  float64
+	Burst int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a new, full, TokenBucket
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &TokenBucket{Rate: rate, Burst: burst, tokens: float64(burst)}
+}
+
+// Wait takes a token from the bucket, waiting for one to be available
+func (bucket *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		wait := bucket.take()
+		if wait == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// take removes a token, returning zero, or how long until one is available
+func (bucket *TokenBucket) take() time.Duration {
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	now := time.Now()
+	if !bucket.last.IsZero() {
+		bucket.tokens += now.Sub(bucket.last).Seconds() * bucket.Rate
+		if bucket.tokens > float64(bucket.Burst) {
+			bucket.tokens = float64(bucket.Burst)
+		}
+	}
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0
+	}
+
+	if bucket.Rate <= 0 {
+		return time.Second
+	}
+
+	return time.Duration((1 - bucket.tokens) / bucket.Rate * float64(time.Second))
+}
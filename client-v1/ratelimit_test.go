@@ -0,0 +1,32 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket_Wait(t *testing.T) {
+	bucket := NewTokenBucket(50, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, bucket.Wait(ctx))
+	}
+
+	// two requests burst, the next two wait 20ms each
+	assert.True(t, time.Since(start) >= 30*time.Millisecond)
+}
+
+func TestTokenBucket_WaitCancelled(t *testing.T) {
+	bucket := NewTokenBucket(0.001, 1)
+	assert.NoError(t, bucket.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, bucket.Wait(ctx))
+}
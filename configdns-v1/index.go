@@ -0,0 +1,125 @@
+package dns
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// recordIndex holds records by lower case name, then by type
+type recordIndex map[string]recordTypeIndex
+
+// recordTypeIndex holds the records of one name by type
+type recordTypeIndex map[string][]DNSRecord
+
+var (
+	// recordTypes lists the record types held in slices of Zone.Zone
+	recordTypes []string
+	// recordTypeFields maps record types to their Zone.Zone field
+	recordTypeFields = map[string]int{}
+	// recordOptionFields maps record struct types to their fields by
+	// FindRecords option name
+	recordOptionFields = map[reflect.Type]map[string]int{}
+)
+
+func init() {
+	zoneField, _ := reflect.TypeOf((*Zone)(nil)).Elem().FieldByName("Zone")
+	for i := 0; i < zoneField.Type.NumField(); i++ {
+		field := zoneField.Type.Field(i)
+		if field.Type.Kind() != reflect.Slice {
+			continue
+		}
+
+		recordType := strings.ToUpper(strings.Split(field.Tag.Get("json"), ",")[0])
+		recordTypes = append(recordTypes, recordType)
+		recordTypeFields[recordType] = i
+
+		recordStruct := field.Type.Elem().Elem()
+		options := map[string]int{}
+		for j := 0; j < recordStruct.NumField(); j++ {
+			tag := strings.Split(recordStruct.Field(j).Tag.Get("json"), ",")[0]
+			if tag != "" && tag != "-" {
+				options[optionName(tag)] = j
+			}
+		}
+		recordOptionFields[recordStruct] = options
+	}
+}
+
+// optionName normalizes a FindRecords option or JSON field name, so that
+// "fingerprintType" matches "fingerprint_type"
+func optionName(name string) string {
+	return strings.ToLower(strings.Replace(name, "_", "", -1))
+}
+
+// recordTypeOf returns the type of a record from its Go type, "NSEC3PARAM"
+// for a *Nsec3paramRecord
+func recordTypeOf(record DNSRecord) string {
+	return strings.ToUpper(strings.TrimSuffix(reflect.TypeOf(record).Elem().Name(), "Record"))
+}
+
+// recordMatches reports whether the fields of record equal all options
+func recordMatches(record DNSRecord, options map[string]interface{}) bool {
+	value := reflect.ValueOf(record).Elem()
+	fields := recordOptionFields[value.Type()]
+	for option, expected := range options {
+		field, ok := fields[optionName(option)]
+		if !ok || !reflect.DeepEqual(value.Field(field).Interface(), expected) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (index recordIndex) add(recordType string, record DNSRecord) {
+	host := strings.ToLower(reflect.ValueOf(record).Elem().FieldByName("Name").String())
+	types, ok := index[host]
+	if !ok {
+		types = recordTypeIndex{}
+		index[host] = types
+	}
+
+	types[recordType] = append(types[recordType], record)
+}
+
+func (index recordIndex) remove(recordType string, record DNSRecord) {
+	host := strings.ToLower(reflect.ValueOf(record).Elem().FieldByName("Name").String())
+	types := index[host]
+	records := types[recordType]
+	for i := range records {
+		if records[i] == record {
+			types[recordType] = append(records[:i:i], records[i+1:]...)
+			break
+		}
+	}
+
+	if len(types[recordType]) == 0 {
+		delete(types, recordType)
+	}
+	if len(types) == 0 {
+		delete(index, host)
+	}
+}
+
+// names returns the indexed names in order
+func (index recordIndex) names() []string {
+	names := make([]string, 0, len(index))
+	for host := range index {
+		names = append(names, host)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// types returns the types of the records of one name in order
+func (types recordTypeIndex) types() []string {
+	recordTypes := make([]string, 0, len(types))
+	for recordType := range types {
+		recordTypes = append(recordTypes, recordType)
+	}
+	sort.Strings(recordTypes)
+
+	return recordTypes
+}
@@ -1,6 +1,8 @@
 package dns
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/jsonhooks-v1"
@@ -271,46 +273,100 @@ func TestZone_AddRecord(t *testing.T) {
 	assert.Equal(t, records, zone.Zone.A)
 }
 
-func Test_removeNonCnameName(t *testing.T) {
-	backup := nonCnameNames
-	defer func() {
-		nonCnameNames = backup
-	}()
+func TestZone_validateCnames(t *testing.T) {
+	zone := NewZone("example.org")
+	other := NewZone("example.net")
+	zone.AddRecord(&CnameRecord{Name: "www", Target: "example.net.", Active: true})
+	other.AddRecord(&ARecord{Name: "www", Target: "1.2.3.4", Active: true})
 
-	names := []name{
-		{recordType: "TXT", name: "test.com"},
-		{recordType: "TXT", name: "foo.com"},
-		{recordType: "TXT", name: "bar.com"},
-		{recordType: "TXT", name: "test.com"},
-	}
+	valid, _ := zone.validateCnames()
+	assert.True(t, valid)
+	valid, _ = other.validateCnames()
+	assert.True(t, valid)
 
-	for _, n := range names {
-		nonCnameNames = append(nonCnameNames, n)
-	}
+	zone.AddRecord(&TxtRecord{Name: "www", Target: "text", Active: true})
+	zone.AddRecord(&CnameRecord{Name: "www", Target: "example.com.", Active: true})
+	valid, failed := zone.validateCnames()
+	assert.False(t, valid)
+	assert.Equal(t, []name{{recordType: "CNAME", name: "www"}, {recordType: "TXT", name: "www"}}, failed)
 
-	zone := Zone{}
-	zone.removeNonCnameName("test.com")
+	assert.NoError(t, zone.RemoveRecord(&TxtRecord{Name: "www", Target: "text", Active: true}))
+	assert.NoError(t, zone.RemoveRecord(&CnameRecord{Name: "www", Target: "example.com.", Active: true}))
+	valid, _ = zone.validateCnames()
+	assert.True(t, valid)
+	assert.Error(t, zone.RemoveRecord(&CnameRecord{Name: "www", Target: "example.com.", Active: true}))
 }
 
-func Test_removeCnameName(t *testing.T) {
-	backup := cnameNames
-	defer func() {
-		cnameNames = backup
-	}()
+func TestZone_validateCnames_CaseInsensitive(t *testing.T) {
+	zone := NewZone("example.org")
+	zone.AddRecord(&CnameRecord{Name: "www", Target: "example.net.", Active: true})
+	zone.AddRecord(&TxtRecord{Name: "WWW", Target: "text", Active: true})
+
+	valid, failed := zone.validateCnames()
+	assert.False(t, valid)
+	assert.Equal(t, []name{{recordType: "TXT", name: "www"}}, failed)
+}
 
-	names := []name{
-		{recordType: "TXT", name: "test.com"},
-		{recordType: "TXT", name: "foo.com"},
-		{recordType: "TXT", name: "bar.com"},
-		{recordType: "TXT", name: "test.com"},
+func TestZone_FindRecords(t *testing.T) {
+	zone := NewZone("example.org")
+	for _, record := range testZone_AddRecord_Provider() {
+		zone.AddRecord(record)
 	}
+	zone.AddRecord(&SrvRecord{Name: "_sip._tcp", Target: "sip.example.org.", Priority: 10, Weight: 5, Port: 5060})
+	zone.AddRecord(&SshfpRecord{Name: "host", Algorithm: 2, FingerprintType: 1, Fingerprint: "123456789ABCDEF"})
+
+	assert.Len(t, zone.FindRecords("a", map[string]interface{}{"name": "www"}), 3)
+	assert.Len(t, zone.FindRecords("A", map[string]interface{}{"name": "www", "target": "1.2.3.4"}), 2)
+	assert.Len(t, zone.FindRecords("A", map[string]interface{}{"target": "1.2.3.5"}), 1)
+	assert.Len(t, zone.FindRecords("A", map[string]interface{}{"name": "WWW"}), 0)
+	assert.Len(t, zone.FindRecords("A", map[string]interface{}{"name": "www", "unknown": 1}), 0)
+	assert.Len(t, zone.FindRecords("AAAA", map[string]interface{}{"name": "www"}), 0)
+	assert.Len(t, zone.FindRecords("SRV", map[string]interface{}{"port": uint16(5060), "priority": 10}), 1)
+	assert.Len(t, zone.FindRecords("SSHFP", map[string]interface{}{"name": "host", "fingerprintType": 1}), 1)
+	assert.Len(t, zone.FindRecords("BOGUS", nil), 0)
 
-	for _, n := range names {
-		cnameNames = append(cnameNames, n)
+	zone.Zone.A[0].Name = "web"
+	zone.Reindex()
+	assert.Len(t, zone.FindRecords("A", map[string]interface{}{"name": "web"}), 1)
+}
+
+func TestZone_RemoveRecord_Index(t *testing.T) {
+	zone := NewZone("example.org")
+	first := &ARecord{Name: "www", Target: "1.2.3.4", Active: true}
+	second := &ARecord{Name: "www", Target: "1.2.3.5", Active: true}
+	zone.AddRecord(first)
+	zone.AddRecord(second)
+	assert.Len(t, zone.FindRecords("A", map[string]interface{}{"name": "www"}), 2)
+
+	assert.NoError(t, zone.RemoveRecord(&ARecord{Name: "www", Target: "1.2.3.4", Active: true}))
+	assert.NotNil(t, zone.index)
+	assert.Equal(t, []DNSRecord{second}, zone.FindRecords("A", map[string]interface{}{"name": "www"}))
+
+	assert.NoError(t, zone.RemoveRecord(second))
+	assert.Empty(t, zone.index)
+	assert.Empty(t, zone.FindRecords("A", map[string]interface{}{"name": "www"}))
+}
+
+func TestZone_ConcurrentEdits(t *testing.T) {
+	zones := []*Zone{NewZone("example.org"), NewZone("example.net")}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for _, zone := range zones {
+			wg.Add(1)
+			go func(zone *Zone, i int) {
+				defer wg.Done()
+				zone.AddRecord(&ARecord{Name: fmt.Sprintf("host%d", i), Target: "1.2.3.4", Active: true})
+				zone.FindRecords("A", map[string]interface{}{"name": "host0"})
+			}(zone, i)
+		}
 	}
+	wg.Wait()
 
-	zone := Zone{}
-	zone.removeCnameName("test.com")
+	for _, zone := range zones {
+		assert.Len(t, zone.Zone.A, 50)
+		valid, _ := zone.validateCnames()
+		assert.True(t, valid)
+	}
 }
 
 func testZone_AddRecord_Provider() []*ARecord {
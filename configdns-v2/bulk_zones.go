@@ -0,0 +1,306 @@
+package dnsv2
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
+)
+
+// BulkZonesCreate is the request body of CreateBulkZones
+type BulkZonesCreate struct {
+	Zones []*ZoneCreate `json:"zones"`
+}
+
+// BulkZonesDelete is the request body of DeleteBulkZones
+type BulkZonesDelete struct {
+	Zones []string `json:"zones"`
+}
+
+// BulkZonesResponse identifies a bulk zone request
+type BulkZonesResponse struct {
+	RequestId      string `json:"requestId,omitempty"`
+	ExpirationDate string `json:"expirationDate,omitempty"`
+}
+
+// BulkStatusResponse is the progress of a bulk zone request
+type BulkStatusResponse struct {
+	RequestId      string `json:"requestId,omitempty"`
+	ZonesSubmitted int    `json:"zonesSubmitted"`
+	SuccessCount   int    `json:"successCount"`
+	FailureCount   int    `json:"failureCount"`
+	IsComplete     bool   `json:"isComplete"`
+	ExpirationDate string `json:"expirationDate,omitempty"`
+}
+
+// BulkFailedZone is a zone a bulk request failed to create or delete
+type BulkFailedZone struct {
+	Zone          string `json:"zone"`
+	FailureReason string `json:"failureReason"`
+}
+
+// BulkCreateResultResponse is the outcome of a bulk zone create request
+type BulkCreateResultResponse struct {
+	RequestId                string            `json:"requestId,omitempty"`
+	SuccessfullyCreatedZones []string          `json:"successfullyCreatedZones"`
+	FailedZones              []*BulkFailedZone `json:"failedZones"`
+}
+
+// BulkDeleteResultResponse is the outcome of a bulk zone delete request
+type BulkDeleteResultResponse struct {
+	RequestId                string            `json:"requestId,omitempty"`
+	SuccessfullyDeletedZones []string          `json:"successfullyDeletedZones"`
+	FailedZones              []*BulkFailedZone `json:"failedZones"`
+}
+
+// BulkZoneResult is the outcome of a bulk request for one zone, Err being
+// empty on success
+type BulkZoneResult struct {
+	Zone string
+	Err  string
+}
+
+// Results returns the outcome for each zone, successful zones first
+func (result *BulkCreateResultResponse) Results() []*BulkZoneResult {
+	return bulkZoneResults(result.SuccessfullyCreatedZones, result.FailedZones)
+}
+
+// Results returns the outcome for each zone, successful zones first
+func (result *BulkDeleteResultResponse) Results() []*BulkZoneResult {
+	return bulkZoneResults(result.SuccessfullyDeletedZones, result.FailedZones)
+}
+
+func bulkZoneResults(succeeded []string, failed []*BulkFailedZone) []*BulkZoneResult {
+	results := make([]*BulkZoneResult, 0, len(succeeded)+len(failed))
+	for _, zone := range succeeded {
+		results = append(results, &BulkZoneResult{Zone: zone})
+	}
+	for _, zone := range failed {
+		results = append(results, &BulkZoneResult{Zone: zone.Zone, Err: zone.FailureReason})
+	}
+
+	return results
+}
+
+// apiRequest sends a request not about a single zone, decoding the response
+// into out when set
+func apiRequest(method string, path string, body interface{}, out interface{}) error {
+	req, err := client.NewJSONRequest(Config, method, path, body)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	if out != nil {
+		return client.BodyJSON(res, out)
+	}
+
+	return nil
+}
+
+// CreateBulkZones submits a request creating many zones at once
+//
+// Endpoint: POST /config-dns/v2/zones/create-requests{?contractId,gid}
+func CreateBulkZones(zones *BulkZonesCreate, zonequerystring ZoneQueryString) (*BulkZonesResponse, error) {
+	path := "/config-dns/v2/zones/create-requests?contractId=" + zonequerystring.Contract
+	if zonequerystring.Group != "" {
+		path += "&gid=" + zonequerystring.Group
+	}
+
+	response := &BulkZonesResponse{}
+	if err := apiRequest("POST", path, zones, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetBulkZoneCreateStatus retrieves the progress of a bulk zone create request
+//
+// Endpoint: GET /config-dns/v2/zones/create-requests/{requestId}
+func GetBulkZoneCreateStatus(requestId string) (*BulkStatusResponse, error) {
+	status := &BulkStatusResponse{}
+	if err := apiRequest("GET", "/config-dns/v2/zones/create-requests/"+requestId, nil, status); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// GetBulkZoneCreateResult retrieves the outcome of a completed bulk zone create request
+//
+// Endpoint: GET /config-dns/v2/zones/create-requests/{requestId}/result
+func GetBulkZoneCreateResult(requestId string) (*BulkCreateResultResponse, error) {
+	result := &BulkCreateResultResponse{}
+	if err := apiRequest("GET", "/config-dns/v2/zones/create-requests/"+requestId+"/result", nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteBulkZones submits a request deleting many zones at once
+//
+// Unless bypassSafetyChecks is true, zones still delegated to Edge DNS are
+// not deleted.
+//
+// Endpoint: POST /config-dns/v2/zones/delete-requests{?bypassSafetyChecks}
+func DeleteBulkZones(zones *BulkZonesDelete, bypassSafetyChecks bool) (*BulkZonesResponse, error) {
+	response := &BulkZonesResponse{}
+	path := "/config-dns/v2/zones/delete-requests?bypassSafetyChecks=" + strconv.FormatBool(bypassSafetyChecks)
+	if err := apiRequest("POST", path, zones, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetBulkZoneDeleteStatus retrieves the progress of a bulk zone delete request
+//
+// Endpoint: GET /config-dns/v2/zones/delete-requests/{requestId}
+func GetBulkZoneDeleteStatus(requestId string) (*BulkStatusResponse, error) {
+	status := &BulkStatusResponse{}
+	if err := apiRequest("GET", "/config-dns/v2/zones/delete-requests/"+requestId, nil, status); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// GetBulkZoneDeleteResult retrieves the outcome of a completed bulk zone delete request
+//
+// Endpoint: GET /config-dns/v2/zones/delete-requests/{requestId}/result
+func GetBulkZoneDeleteResult(requestId string) (*BulkDeleteResultResponse, error) {
+	result := &BulkDeleteResultResponse{}
+	if err := apiRequest("GET", "/config-dns/v2/zones/delete-requests/"+requestId+"/result", nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// WaitBulkZoneRequest polls a bulk zone request every interval until it
+// completes, calling onStatus, when set, with each status
+//
+//	request, err := dnsv2.CreateBulkZones(zones, querystring)
+//	_, err = dnsv2.WaitBulkZoneRequest(ctx, request.RequestId, dnsv2.GetBulkZoneCreateStatus, 10*time.Second, nil)
+//	result, err := dnsv2.GetBulkZoneCreateResult(request.RequestId)
+func WaitBulkZoneRequest(ctx context.Context, requestId string, getStatus func(requestId string) (*BulkStatusResponse, error), interval time.Duration, onStatus func(status *BulkStatusResponse)) (*BulkStatusResponse, error) {
+	for {
+		status, err := getStatus(requestId)
+		if err != nil {
+			return nil, err
+		}
+
+		if onStatus != nil {
+			onStatus(status)
+		}
+
+		if status.IsComplete {
+			return status, nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return status, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// ZoneMigration is a zone to create, with the master zone file of a primary
+// zone to import
+type ZoneMigration struct {
+	Zone     *ZoneCreate
+	ZoneFile string
+}
+
+// ZoneMigrationResult is the outcome of migrating one zone
+type ZoneMigrationResult struct {
+	Zone       string
+	Recordsets int
+	Err        error
+}
+
+// MigrateZones creates zones one at a time and, for primary zones, imports
+// their zone file
+//
+// Each primary zone is seeded with SOA and NS records through
+// ZoneCreate.SaveChangelist, then the records of its zone file, except SOA
+// and apex NS records which belong to the previous provider, are added to
+// the changelist, which is submitted. A failed zone does not stop the
+// migration of the others.
+func MigrateZones(migrations []*ZoneMigration, zonequerystring ZoneQueryString) []*ZoneMigrationResult {
+	results := make([]*ZoneMigrationResult, len(migrations))
+	for i, migration := range migrations {
+		results[i] = migrateZone(migration, zonequerystring)
+	}
+
+	return results
+}
+
+func migrateZone(migration *ZoneMigration, zonequerystring ZoneQueryString) *ZoneMigrationResult {
+	zone := migration.Zone
+	result := &ZoneMigrationResult{Zone: zone.Zone}
+
+	var changes []*RecordsetChange
+	if migration.ZoneFile != "" {
+		records, err := ParseZoneFile(zone.Zone, strings.NewReader(migration.ZoneFile))
+		if err != nil {
+			result.Err = fmt.Errorf("parsing zone file: %s", err)
+			return result
+		}
+
+		apex := strings.ToLower(strings.TrimSuffix(zone.Zone, "."))
+		for _, record := range records {
+			if record.RecordType == "SOA" || record.RecordType == "NS" && record.Name == apex {
+				continue
+			}
+			recordset := record.ToRecordset()
+			changes = append(changes, &RecordsetChange{Op: ChangeOpAdd, Desired: &recordset})
+		}
+	}
+
+	if err := zone.Save(zonequerystring); err != nil {
+		result.Err = err
+		return result
+	}
+
+	if !strings.EqualFold(zone.Type, "PRIMARY") {
+		return result
+	}
+
+	if err := zone.SaveChangelist(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	changelist := NewChangelist(zone.Zone)
+	if err := changelist.AddChanges(changes...); err != nil {
+		changelist.Delete()
+		result.Err = err
+		return result
+	}
+
+	if err := changelist.Submit(); err != nil {
+		changelist.Delete()
+		result.Err = err
+		return result
+	}
+	result.Recordsets = len(changes)
+
+	return result
+}
@@ -0,0 +1,162 @@
+package dnsv2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestCreateBulkZones(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/zones/create-requests").
+		MatchParam("contractId", "C-1").
+		MatchParam("gid", "42").
+		JSON(map[string]interface{}{"zones": []map[string]interface{}{
+			{"zone": "a.com", "type": "PRIMARY", "signAndServe": false},
+			{"zone": "b.com", "type": "SECONDARY", "masters": []string{"10.0.0.1"}, "signAndServe": false},
+		}}).
+		Reply(201).
+		JSON(map[string]interface{}{"requestId": "req-1", "expirationDate": "2020-01-01T00:00:00Z"})
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones/create-requests/req-1$").
+		Reply(200).
+		JSON(map[string]interface{}{"requestId": "req-1", "zonesSubmitted": 2, "successCount": 1, "isComplete": false})
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones/create-requests/req-1$").
+		Reply(200).
+		JSON(map[string]interface{}{"requestId": "req-1", "zonesSubmitted": 2, "successCount": 1, "failureCount": 1, "isComplete": true})
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones/create-requests/req-1/result").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"requestId":                "req-1",
+			"successfullyCreatedZones": []string{"a.com"},
+			"failedZones":              []map[string]string{{"zone": "b.com", "failureReason": "ZONE_ALREADY_EXISTS"}},
+		})
+
+	Init(config)
+
+	request, err := CreateBulkZones(&BulkZonesCreate{Zones: []*ZoneCreate{
+		{Zone: "a.com", Type: "PRIMARY"},
+		{Zone: "b.com", Type: "SECONDARY", Masters: []string{"10.0.0.1"}},
+	}}, ZoneQueryString{Contract: "C-1", Group: "42"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "req-1", request.RequestId)
+
+	polls := 0
+	status, err := WaitBulkZoneRequest(context.Background(), request.RequestId, GetBulkZoneCreateStatus, time.Millisecond, func(*BulkStatusResponse) { polls++ })
+	assert.NoError(t, err)
+	assert.Equal(t, 2, polls)
+	assert.Equal(t, 1, status.FailureCount)
+
+	result, err := GetBulkZoneCreateResult(request.RequestId)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []*BulkZoneResult{{Zone: "a.com"}, {Zone: "b.com", Err: "ZONE_ALREADY_EXISTS"}}, result.Results())
+	assert.True(t, gock.IsDone())
+}
+
+func TestDeleteBulkZones(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/zones/delete-requests").
+		MatchParam("bypassSafetyChecks", "false").
+		JSON(map[string]interface{}{"zones": []string{"a.com"}}).
+		Reply(201).
+		JSON(map[string]interface{}{"requestId": "req-2"})
+
+	Init(config)
+
+	request, err := DeleteBulkZones(&BulkZonesDelete{Zones: []string{"a.com"}}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "req-2", request.RequestId)
+	assert.True(t, gock.IsDone())
+}
+
+func TestMigrateZones(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/zones/").
+		MatchParam("contractId", "C-1").
+		Reply(201)
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/changelists/").
+		MatchParam("zone", "a.com").
+		Reply(201)
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/changelists/a.com/recordsets/add-change").
+		JSON(map[string]interface{}{"name": "www.a.com", "type": "A", "op": "ADD", "ttl": 300, "rdata": []string{"10.0.0.1"}}).
+		Reply(204)
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/changelists/a.com$").
+		Reply(200).
+		JSON(map[string]interface{}{"zone": "a.com"})
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/changelists/a.com/submit").
+		Reply(204)
+
+	Init(config)
+
+	results := MigrateZones([]*ZoneMigration{
+		{
+			Zone: &ZoneCreate{Zone: "a.com", Type: "PRIMARY"},
+			ZoneFile: `$TTL 300
+@ SOA ns.old.net. hostmaster 1 2 3 4 5
+@ NS ns.old.net.
+www A 10.0.0.1
+`,
+		},
+		{Zone: &ZoneCreate{Zone: "b.com", Type: "PRIMARY"}, ZoneFile: "www A 10.0.0.1\n"},
+	}, ZoneQueryString{Contract: "C-1"})
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, 1, results[0].Recordsets)
+	assert.Error(t, results[1].Err)
+	assert.True(t, gock.IsDone())
+}
+
+func TestMigrateZones_SubmitFailure(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/zones/").
+		Reply(201)
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/changelists/").
+		MatchParam("zone", "a.com").
+		Reply(201)
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/changelists/a.com/recordsets/add-change").
+		Reply(204)
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/changelists/a.com$").
+		Reply(200).
+		JSON(map[string]interface{}{"zone": "a.com"})
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/changelists/a.com/submit").
+		Reply(500).
+		JSON(map[string]interface{}{"title": "Internal Server Error", "status": 500})
+	gock.New(changelistTestURL).
+		Delete("/config-dns/v2/changelists/a.com$").
+		Reply(204)
+
+	Init(config)
+
+	results := MigrateZones([]*ZoneMigration{
+		{Zone: &ZoneCreate{Zone: "a.com", Type: "PRIMARY"}, ZoneFile: "www 300 A 10.0.0.1\n"},
+	}, ZoneQueryString{Contract: "C-1"})
+
+	assert.Error(t, results[0].Err)
+	assert.Equal(t, 0, results[0].Recordsets)
+	assert.True(t, gock.IsDone())
+}
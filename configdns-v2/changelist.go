@@ -0,0 +1,291 @@
+package dnsv2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
+)
+
+// ChangelistOverwriteValue is used to create an "enum" of possible values
+// for the overwrite parameter of Changelist.Create
+type ChangelistOverwriteValue string
+
+const (
+	// ChangelistOverwriteNone Changelist.Create overwrite value NONE, fails if a changelist exists
+	ChangelistOverwriteNone ChangelistOverwriteValue = ""
+	// ChangelistOverwriteStale Changelist.Create overwrite value STALE
+	ChangelistOverwriteStale ChangelistOverwriteValue = "STALE"
+	// ChangelistOverwriteAlways Changelist.Create overwrite value ALWAYS
+	ChangelistOverwriteAlways ChangelistOverwriteValue = "ALWAYS"
+)
+
+// ErrStaleChangelist is returned when submitting a changelist based on a
+// zone version that is no longer current
+//
+// See: Changelist.Rebase()
+var ErrStaleChangelist = errors.New("changelist is stale")
+
+// Changelist is a pending set of changes to the recordsets of a zone
+//
+// Changes holds the changes added through this Changelist, so that they can
+// be replayed by Rebase.
+//
+//	changelist := dnsv2.NewChangelist("example.com")
+//	err := changelist.Create(dnsv2.ChangelistOverwriteStale)
+//	err = changelist.AddChanges(changes...)
+//	err = changelist.SubmitAndWait(ctx, 10*time.Second)
+type Changelist struct {
+	Zone             string             `json:"zone,omitempty"`
+	ChangeTag        string             `json:"changeTag,omitempty"`
+	ZoneVersionId    string             `json:"zoneVersionId,omitempty"`
+	LastModifiedDate string             `json:"lastModifiedDate,omitempty"`
+	Stale            bool               `json:"stale,omitempty"`
+	Changes          []*RecordsetChange `json:"-"`
+}
+
+// ChangelistDiff lists the recordsets added, updated and deleted by a changelist
+type ChangelistDiff struct {
+	Zone    string      `json:"zone,omitempty"`
+	Adds    []Recordset `json:"adds"`
+	Updates []Recordset `json:"updates"`
+	Deletes []Recordset `json:"deletes"`
+}
+
+// NewChangelist creates a new Changelist
+func NewChangelist(zone string) *Changelist {
+	return &Changelist{Zone: zone}
+}
+
+// changelistError wraps a network or API error of a changelist call
+func changelistError(zone string, res error) error {
+	if apiErr, ok := res.(client.APIError); ok {
+		return &ZoneError{zoneName: zone, apiErrorMessage: apiErr.Detail, err: apiErr}
+	}
+
+	return &ZoneError{zoneName: zone, httpErrorMessage: res.Error(), err: res}
+}
+
+// do sends a changelist request, decoding the response into out when set
+func (changelist *Changelist) do(method string, path string, body interface{}, out interface{}) error {
+	return zoneRequest(changelist.Zone, method, path, body, out)
+}
+
+// zoneRequest sends a request about a zone, decoding the response into out
+// when set and returning a ZoneError on failure
+func zoneRequest(zone string, method string, path string, body interface{}, out interface{}) error {
+	req, err := client.NewJSONRequest(Config, method, path, body)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return changelistError(zone, err)
+	}
+
+	if res.StatusCode == 404 {
+		return &ZoneError{zoneName: zone}
+	}
+
+	if client.IsError(res) {
+		return changelistError(zone, client.NewAPIError(res))
+	}
+
+	if out != nil {
+		return client.BodyJSON(res, out)
+	}
+
+	return nil
+}
+
+// GetChangelist retrieves the changelist of a zone
+//
+// Endpoint: GET /config-dns/v2/changelists/{zone}
+func GetChangelist(zone string) (*Changelist, error) {
+	changelist := NewChangelist(zone)
+	if err := changelist.GetChangelist(); err != nil {
+		return nil, err
+	}
+
+	return changelist, nil
+}
+
+// GetChangelist populates the changelist from the API
+//
+// Endpoint: GET /config-dns/v2/changelists/{zone}
+func (changelist *Changelist) GetChangelist() error {
+	changes := changelist.Changes
+	if err := changelist.do("GET", "/config-dns/v2/changelists/"+changelist.Zone, nil, changelist); err != nil {
+		return err
+	}
+	changelist.Changes = changes
+
+	return nil
+}
+
+// Create creates the changelist from the current version of the zone
+//
+// overwrite decides what happens when a changelist already exists for the
+// zone: with ChangelistOverwriteNone an error is returned, with
+// ChangelistOverwriteStale it is replaced only if stale.
+//
+// Endpoint: POST /config-dns/v2/changelists{?zone,overwrite}
+func (changelist *Changelist) Create(overwrite ChangelistOverwriteValue) error {
+	path := "/config-dns/v2/changelists?zone=" + changelist.Zone
+	if overwrite != ChangelistOverwriteNone {
+		path += "&overwrite=" + string(overwrite)
+	}
+
+	if err := changelist.do("POST", path, nil, nil); err != nil {
+		return err
+	}
+	changelist.Changes = nil
+
+	return changelist.GetChangelist()
+}
+
+// Delete discards the changelist
+//
+// Endpoint: DELETE /config-dns/v2/changelists/{zone}
+func (changelist *Changelist) Delete() error {
+	return changelist.do("DELETE", "/config-dns/v2/changelists/"+changelist.Zone, nil, nil)
+}
+
+// AddChange adds a recordset change to the changelist
+//
+// Endpoint: POST /config-dns/v2/changelists/{zone}/recordsets/add-change
+func (changelist *Changelist) AddChange(change *RecordsetChange) error {
+	recordset := change.Recordset()
+	body := map[string]interface{}{
+		"name":  recordset.Name,
+		"type":  recordset.Type,
+		"op":    change.Op,
+		"ttl":   recordset.TTL,
+		"rdata": recordset.Rdata,
+	}
+
+	if err := changelist.do("POST", "/config-dns/v2/changelists/"+changelist.Zone+"/recordsets/add-change", body, nil); err != nil {
+		return err
+	}
+	changelist.Changes = append(changelist.Changes, change)
+
+	return nil
+}
+
+// AddChanges adds recordset changes to the changelist, in order, stopping at
+// the first error
+//
+// See: Changelist.AddChange()
+func (changelist *Changelist) AddChanges(changes ...*RecordsetChange) error {
+	for _, change := range changes {
+		if err := changelist.AddChange(change); err != nil {
+			return fmt.Errorf("%s %s %s: %s", change.Op, change.Recordset().Name, change.Recordset().Type, err)
+		}
+	}
+
+	return nil
+}
+
+// GetRecordsets retrieves the recordsets of the zone as modified by the changelist
+//
+// Endpoint: GET /config-dns/v2/changelists/{zone}/recordsets
+func (changelist *Changelist) GetRecordsets() ([]Recordset, error) {
+	records := NewRecordSetResponse(changelist.Zone)
+	if err := changelist.do("GET", "/config-dns/v2/changelists/"+changelist.Zone+"/recordsets?showAll=true", nil, records); err != nil {
+		return nil, err
+	}
+
+	return records.Recordsets, nil
+}
+
+// GetDiff retrieves the recordsets the changelist adds, updates and deletes
+//
+// Endpoint: GET /config-dns/v2/changelists/{zone}/diff
+func (changelist *Changelist) GetDiff() (*ChangelistDiff, error) {
+	diff := &ChangelistDiff{Zone: changelist.Zone}
+	if err := changelist.do("GET", "/config-dns/v2/changelists/"+changelist.Zone+"/diff", nil, diff); err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+// Submit submits the changelist, making it the new version of the zone
+//
+// ErrStaleChangelist is returned, without submitting, when the zone changed
+// since the changelist was created.
+//
+// Endpoint: POST /config-dns/v2/changelists/{zone}/submit
+func (changelist *Changelist) Submit() error {
+	if err := changelist.GetChangelist(); err != nil {
+		return err
+	}
+
+	if changelist.Stale {
+		return ErrStaleChangelist
+	}
+
+	return changelist.do("POST", "/config-dns/v2/changelists/"+changelist.Zone+"/submit", nil, nil)
+}
+
+// SubmitAndWait submits the changelist and polls the zone every interval
+// until the version it creates is ACTIVE
+//
+// Right after the submission the zone may still report the previous version
+// as ACTIVE, so the zone is considered activated once its version differs
+// from the one the changelist was based on, or once its activation state
+// left ACTIVE and came back to it.
+//
+// An error is returned if the activation state becomes ERROR, or ctx is
+// done first.
+func (changelist *Changelist) SubmitAndWait(ctx context.Context, interval time.Duration) error {
+	if err := changelist.Submit(); err != nil {
+		return err
+	}
+
+	// Submit refreshed the changelist, and so the version it is based on
+	previousVersion := changelist.ZoneVersionId
+	leftActive := false
+	for {
+		zone, err := GetZone(changelist.Zone)
+		if err != nil {
+			return err
+		}
+
+		switch zone.ActivationState {
+		case "ACTIVE":
+			if leftActive || zone.VersionId != "" && zone.VersionId != previousVersion {
+				return nil
+			}
+		case "ERROR":
+			return fmt.Errorf("zone %s activation failed", changelist.Zone)
+		default:
+			leftActive = true
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Rebase recreates a stale changelist from the current version of the
+// zone and replays the changes added through this Changelist
+//
+// Changes that no longer apply, such as the deletion of a recordset removed
+// meanwhile, make Rebase fail.
+func (changelist *Changelist) Rebase() error {
+	changes := changelist.Changes
+	if err := changelist.Create(ChangelistOverwriteAlways); err != nil {
+		return err
+	}
+
+	return changelist.AddChanges(changes...)
+}
@@ -0,0 +1,139 @@
+package dnsv2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const changelistTestURL = "https://akaa-baseurl-xxxxxxxxxxx-xxxxxxxxxxxxx.luna.akamaiapis.net"
+
+func TestChangelist_Rebase(t *testing.T) {
+	defer gock.Off()
+
+	change := &RecordsetChange{Op: ChangeOpAdd, Desired: &Recordset{Name: "www.example.com", Type: "A", TTL: 300, Rdata: []string{"10.0.0.1"}}}
+
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/changelists").
+		MatchParam("zone", "example.com").
+		Reply(201)
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/changelists/example.com$").
+		Reply(200).
+		JSON(map[string]interface{}{"zone": "example.com", "changeTag": "tag1"})
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/changelists/example.com/recordsets/add-change").
+		JSON(map[string]interface{}{"name": "www.example.com", "type": "A", "op": "ADD", "ttl": 300, "rdata": []string{"10.0.0.1"}}).
+		Reply(204)
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/changelists/example.com$").
+		Reply(200).
+		JSON(map[string]interface{}{"zone": "example.com", "changeTag": "tag1", "stale": true})
+
+	Init(config)
+
+	changelist := NewChangelist("example.com")
+	if !assert.NoError(t, changelist.Create(ChangelistOverwriteNone)) {
+		return
+	}
+	assert.Equal(t, "tag1", changelist.ChangeTag)
+	assert.NoError(t, changelist.AddChanges(change))
+	assert.Equal(t, ErrStaleChangelist, changelist.Submit())
+	assert.True(t, gock.IsDone())
+
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/changelists").
+		MatchParam("zone", "example.com").
+		MatchParam("overwrite", "ALWAYS").
+		Reply(201)
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/changelists/example.com$").
+		Reply(200).
+		JSON(map[string]interface{}{"zone": "example.com", "changeTag": "tag2"})
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/changelists/example.com/recordsets/add-change").
+		JSON(map[string]interface{}{"name": "www.example.com", "type": "A", "op": "ADD", "ttl": 300, "rdata": []string{"10.0.0.1"}}).
+		Reply(204)
+
+	assert.NoError(t, changelist.Rebase())
+	assert.Equal(t, "tag2", changelist.ChangeTag)
+	assert.Len(t, changelist.Changes, 1)
+	assert.True(t, gock.IsDone())
+}
+
+func TestChangelist_GetDiff(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/changelists/example.com/diff").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"zone":    "example.com",
+			"adds":    []Recordset{{Name: "www.example.com", Type: "A", TTL: 300, Rdata: []string{"10.0.0.1"}}},
+			"updates": []Recordset{},
+			"deletes": []Recordset{{Name: "old.example.com", Type: "TXT", TTL: 300, Rdata: []string{`"x"`}}},
+		})
+
+	Init(config)
+
+	diff, err := NewChangelist("example.com").GetDiff()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, diff.Adds, 1)
+	assert.Empty(t, diff.Updates)
+	assert.Equal(t, "old.example.com", diff.Deletes[0].Name)
+}
+
+func TestChangelist_SubmitAndWait(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/changelists/example.com$").
+		Reply(200).
+		JSON(map[string]interface{}{"zone": "example.com", "changeTag": "tag1"})
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/changelists/example.com/submit").
+		Reply(204)
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones/example.com").
+		Reply(200).
+		JSON(map[string]interface{}{"zone": "example.com", "activationState": "PENDING"})
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones/example.com").
+		Reply(200).
+		JSON(map[string]interface{}{"zone": "example.com", "activationState": "ACTIVE"})
+
+	Init(config)
+
+	assert.NoError(t, NewChangelist("example.com").SubmitAndWait(context.Background(), time.Millisecond))
+	assert.True(t, gock.IsDone())
+}
+
+func TestChangelist_SubmitAndWait_PreviousVersionActive(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/changelists/example.com$").
+		Reply(200).
+		JSON(map[string]interface{}{"zone": "example.com", "changeTag": "tag1", "zoneVersionId": "v1"})
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/changelists/example.com/submit").
+		Reply(204)
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones/example.com").
+		Reply(200).
+		JSON(map[string]interface{}{"zone": "example.com", "activationState": "ACTIVE", "versionId": "v1"})
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones/example.com").
+		Reply(200).
+		JSON(map[string]interface{}{"zone": "example.com", "activationState": "ACTIVE", "versionId": "v2"})
+
+	Init(config)
+
+	assert.NoError(t, NewChangelist("example.com").SubmitAndWait(context.Background(), time.Millisecond))
+	assert.True(t, gock.IsDone())
+}
@@ -0,0 +1,447 @@
+package dnsv2
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	dnsv1 "github.com/akamai/AkamaiOPEN-edgegrid-golang/configdns-v1"
+)
+
+// V1DefaultTTL is the TTL given to configdns-v1 records without TTL when the
+// zone has no SOA TTL either
+const V1DefaultTTL = 3600
+
+// V1ConversionIssue is a configdns-v1 record that could not be converted as
+// is, Dropped telling whether it is missing from the recordsets
+type V1ConversionIssue struct {
+	Name    string
+	Type    string
+	Dropped bool
+	Reason  string
+}
+
+func (issue *V1ConversionIssue) String() string {
+	action := "converted"
+	if issue.Dropped {
+		action = "dropped"
+	}
+
+	return fmt.Sprintf("%s %s %s: %s", issue.Name, issue.Type, action, issue.Reason)
+}
+
+// V1ConversionReport lists the lossy conversions made by ConvertV1Zone
+type V1ConversionReport struct {
+	Zone   string
+	Issues []*V1ConversionIssue
+}
+
+// Lossy reports whether the recordsets differ from the configdns-v1 zone in
+// any way
+func (report *V1ConversionReport) Lossy() bool {
+	return len(report.Issues) > 0
+}
+
+// Dropped returns the issues of records missing from the recordsets
+func (report *V1ConversionReport) Dropped() []*V1ConversionIssue {
+	var dropped []*V1ConversionIssue
+	for _, issue := range report.Issues {
+		if issue.Dropped {
+			dropped = append(dropped, issue)
+		}
+	}
+
+	return dropped
+}
+
+func (report *V1ConversionReport) String() string {
+	lines := make([]string, len(report.Issues))
+	for i, issue := range report.Issues {
+		lines[i] = issue.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// v1Converter groups converted configdns-v1 records into recordsets
+type v1Converter struct {
+	zone       string
+	defaultTTL int
+	recordsets map[string]*Recordset
+	report     *V1ConversionReport
+}
+
+// ConvertV1Zone converts a configdns-v1 zone, with records of all of its 21
+// types, to the recordsets of a configdns-v2 zone
+//
+// Relative names and targets are made absolute within the zone, and rdata
+// is written in the form returned by the v2 API. Records are grouped into
+// recordsets by name and type. Everything the v2 model cannot carry over is
+// listed in the report:
+//   - inactive records, which are dropped as v2 has no inactive records
+//   - records outside the zone or with invalid data, which are dropped
+//   - duplicate records, which are merged
+//   - records without TTL, which get the SOA TTL or V1DefaultTTL
+//   - records of one recordset with different TTLs, which all get the lowest
+//   - CNAME records sharing their name with other records, which Edge DNS
+//     rejects
+//
+// The recordsets are in zone file order, SOA first.
+func ConvertV1Zone(zone *dnsv1.Zone) ([]Recordset, *V1ConversionReport, error) {
+	name := strings.ToLower(strings.TrimSuffix(zone.Zone.Name, "."))
+	if name == "" {
+		return nil, nil, fmt.Errorf("zone without name")
+	}
+
+	c := &v1Converter{
+		zone:       name,
+		defaultTTL: V1DefaultTTL,
+		recordsets: map[string]*Recordset{},
+		report:     &V1ConversionReport{Zone: name},
+	}
+	if soa := zone.Zone.Soa; soa != nil {
+		if soa.TTL > 0 {
+			c.defaultTTL = soa.TTL
+		}
+		rdata, err := c.typed(&SOARdata{
+			MName:   c.target(soa.Originserver),
+			RName:   c.target(soa.Contact),
+			Serial:  uint32(soa.Serial),
+			Refresh: uint32(soa.Refresh),
+			Retry:   uint32(soa.Retry),
+			Expire:  uint32(soa.Expire),
+			Minimum: uint32(soa.Minimum),
+		})
+		c.add("", "SOA", soa.TTL, true, rdata, err)
+	}
+
+	for _, record := range zone.Zone.A {
+		rdata, err := c.typed(&ARdata{Address: net.ParseIP(record.Target)})
+		c.add(record.Name, "A", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Aaaa {
+		rdata, err := c.typed(&AAAARdata{Address: net.ParseIP(record.Target)})
+		c.add(record.Name, "AAAA", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Afsdb {
+		rdata, err := c.afsdb(record.Subtype, record.Target)
+		c.add(record.Name, "AFSDB", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Cname {
+		rdata, err := c.typed(&CNAMERdata{Target: c.target(record.Target)})
+		c.add(record.Name, "CNAME", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Dnskey {
+		rdata, err := c.typed(&DNSKEYRdata{Flags: uint16(record.Flags), Protocol: uint8(record.Protocol), Algorithm: uint8(record.Algorithm), Key: strings.Join(strings.Fields(record.Key), "")})
+		c.add(record.Name, "DNSKEY", record.TTL, record.Active, rdata, v1Range(err, record.Flags, 65535, record.Protocol, 255, record.Algorithm, 255))
+	}
+	for _, record := range zone.Zone.Ds {
+		rdata, err := c.typed(&DSRdata{KeyTag: uint16(record.Keytag), Algorithm: uint8(record.Algorithm), DigestType: uint8(record.DigestType), Digest: strings.ToUpper(strings.Join(strings.Fields(record.Digest), ""))})
+		c.add(record.Name, "DS", record.TTL, record.Active, rdata, v1Range(err, record.Keytag, 65535, record.Algorithm, 255, record.DigestType, 255))
+	}
+	for _, record := range zone.Zone.Hinfo {
+		rdata, err := characterStrings(record.Hardware, record.Software)
+		c.add(record.Name, "HINFO", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Loc {
+		rdata, err := c.loc(record.Target)
+		c.add(record.Name, "LOC", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Mx {
+		rdata, err := c.typed(&MXRdata{Preference: uint16(record.Priority), Exchange: c.target(record.Target)})
+		c.add(record.Name, "MX", record.TTL, record.Active, rdata, v1Range(err, record.Priority, 65535))
+	}
+	for _, record := range zone.Zone.Naptr {
+		rdata, err := c.naptr(record)
+		c.add(record.Name, "NAPTR", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Ns {
+		rdata, err := c.typed(&NSRdata{Target: c.target(record.Target)})
+		c.add(record.Name, "NS", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Nsec3 {
+		rdata, err := requiredFields(strconv.Itoa(record.Algorithm), strconv.Itoa(record.Flags), strconv.Itoa(record.Iterations), v1Salt(record.Salt), record.NextHashedOwnerName, record.TypeBitmaps)
+		c.add(record.Name, "NSEC3", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Nsec3param {
+		rdata, err := requiredFields(strconv.Itoa(record.Algorithm), strconv.Itoa(record.Flags), strconv.Itoa(record.Iterations), v1Salt(record.Salt))
+		c.add(record.Name, "NSEC3PARAM", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Ptr {
+		rdata, err := c.typed(&PTRRdata{Target: c.target(record.Target)})
+		c.add(record.Name, "PTR", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Rp {
+		rdata, err := c.rp(record.Mailbox, record.Txt)
+		c.add(record.Name, "RP", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Rrsig {
+		rdata, err := requiredFields(strings.ToUpper(record.TypeCovered), strconv.Itoa(record.Algorithm), strconv.Itoa(record.Labels), strconv.Itoa(record.OriginalTTL), record.Expiration, record.Inception, strconv.Itoa(record.Keytag), c.target(record.Signer), strings.Join(strings.Fields(record.Signature), ""))
+		c.add(record.Name, "RRSIG", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Spf {
+		rdata, err := c.txt(record.Target)
+		c.add(record.Name, "SPF", record.TTL, record.Active, rdata, err)
+	}
+	for _, record := range zone.Zone.Srv {
+		rdata, err := c.typed(&SRVRdata{Priority: uint16(record.Priority), Weight: record.Weight, Port: record.Port, Target: c.target(record.Target)})
+		c.add(record.Name, "SRV", record.TTL, record.Active, rdata, v1Range(err, record.Priority, 65535))
+	}
+	for _, record := range zone.Zone.Sshfp {
+		rdata, err := c.typed(&SSHFPRdata{Algorithm: uint8(record.Algorithm), FingerprintType: uint8(record.FingerprintType), Fingerprint: strings.ToUpper(strings.Join(strings.Fields(record.Fingerprint), ""))})
+		c.add(record.Name, "SSHFP", record.TTL, record.Active, rdata, v1Range(err, record.Algorithm, 255, record.FingerprintType, 255))
+	}
+	for _, record := range zone.Zone.Txt {
+		rdata, err := c.txt(record.Target)
+		c.add(record.Name, "TXT", record.TTL, record.Active, rdata, err)
+	}
+
+	return c.result(), c.report, nil
+}
+
+// issue records a lossy conversion
+func (c *v1Converter) issue(name string, recordType string, dropped bool, format string, args ...interface{}) {
+	c.report.Issues = append(c.report.Issues, &V1ConversionIssue{
+		Name:    name,
+		Type:    recordType,
+		Dropped: dropped,
+		Reason:  fmt.Sprintf(format, args...),
+	})
+}
+
+// add adds a record to its recordset, rdata being the result of one of the
+// rdata conversions
+func (c *v1Converter) add(name string, recordType string, ttl int, active bool, rdata string, err error) {
+	name, inZone := c.name(name)
+	switch {
+	case !active:
+		c.issue(name, recordType, true, "inactive")
+		return
+	case !inZone:
+		c.issue(name, recordType, true, "outside of zone %s", c.zone)
+		return
+	case err != nil:
+		c.issue(name, recordType, true, "%s", err)
+		return
+	}
+
+	if ttl <= 0 {
+		c.issue(name, recordType, false, "no TTL, %d used", c.defaultTTL)
+		ttl = c.defaultTTL
+	}
+
+	key := name + " " + recordType
+	recordset, exists := c.recordsets[key]
+	if !exists {
+		c.recordsets[key] = &Recordset{Name: name, Type: recordType, TTL: ttl, Rdata: []string{rdata}}
+		return
+	}
+
+	if ttl != recordset.TTL {
+		if ttl < recordset.TTL {
+			recordset.TTL = ttl
+		}
+		c.issue(name, recordType, false, "records with different TTLs, %d used", recordset.TTL)
+	}
+
+	for _, existing := range recordset.Rdata {
+		if existing == rdata {
+			c.issue(name, recordType, false, "duplicate record %s merged", rdata)
+			return
+		}
+	}
+	recordset.Rdata = append(recordset.Rdata, rdata)
+}
+
+// result returns the recordsets in zone file order, reporting CNAME
+// conflicts
+func (c *v1Converter) result() []Recordset {
+	recordsets := make([]Recordset, 0, len(c.recordsets))
+	types := map[string][]string{}
+	for _, recordset := range c.recordsets {
+		recordsets = append(recordsets, *recordset)
+		types[recordset.Name] = append(types[recordset.Name], recordset.Type)
+	}
+	sort.Slice(recordsets, func(i, j int) bool {
+		return zoneFileLess(c.zone, recordsets[i], recordsets[j])
+	})
+
+	for _, recordset := range recordsets {
+		if recordset.Type == "CNAME" && len(types[recordset.Name]) > 1 {
+			c.issue(recordset.Name, "CNAME", false, "name also has records of other types, which Edge DNS rejects")
+		}
+	}
+
+	return recordsets
+}
+
+// name returns the absolute name of a v1 record without trailing dot, and
+// whether it is within the zone
+func (c *v1Converter) name(name string) (string, bool) {
+	name = strings.ToLower(name)
+	if name == "" || name == "@" {
+		return c.zone, true
+	}
+
+	if isAbsoluteName(name) {
+		name = strings.TrimSuffix(name, ".")
+		return name, name == c.zone || strings.HasSuffix(name, "."+c.zone)
+	}
+
+	return name + "." + c.zone, true
+}
+
+// target returns a v1 target name as an absolute name
+func (c *v1Converter) target(target string) string {
+	if target == "" {
+		return ""
+	}
+
+	name, err := absoluteName(target, c.zone+".")
+	if err != nil {
+		return target
+	}
+
+	return name
+}
+
+// typed validates and formats rdata
+func (c *v1Converter) typed(rdata Rdata) (string, error) {
+	if err := rdata.Validate(); err != nil {
+		return "", err
+	}
+
+	return rdata.String(), nil
+}
+
+// v1Range returns err, or an error if a v1 integer field does not fit the
+// narrower rdata field it was converted to, values being pairs of value and
+// maximum
+func v1Range(err error, values ...int) error {
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i+1 < len(values); i += 2 {
+		if values[i] < 0 || values[i] > values[i+1] {
+			return fmt.Errorf("field value %d out of range", values[i])
+		}
+	}
+
+	return nil
+}
+
+// requiredFields formats rdata of types without typed model
+func requiredFields(fields ...string) (string, error) {
+	for _, field := range fields {
+		if field == "" {
+			return "", fmt.Errorf("missing rdata field")
+		}
+	}
+
+	return strings.Join(fields, " "), nil
+}
+
+// characterStrings formats rdata made of character strings
+func characterStrings(values ...string) (string, error) {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		if len(value) > 255 {
+			return "", fmt.Errorf("string longer than 255 characters")
+		}
+		quoted[i] = quoteRdataString(value)
+	}
+
+	return strings.Join(quoted, " "), nil
+}
+
+// afsdb formats the rdata of an AFSDB record
+func (c *v1Converter) afsdb(subtype int, target string) (string, error) {
+	target = c.target(target)
+	if err := validateDomainName(target); err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(subtype) + " " + target, nil
+}
+
+// rp formats the rdata of an RP record
+func (c *v1Converter) rp(mailbox string, txt string) (string, error) {
+	mailbox = c.target(mailbox)
+	txt = c.target(txt)
+	for _, name := range []string{mailbox, txt} {
+		if err := validateDomainName(name); err != nil {
+			return "", err
+		}
+	}
+
+	return mailbox + " " + txt, nil
+}
+
+// loc formats the rdata of a LOC record with the padded coordinates
+// returned by the API
+func (c *v1Converter) loc(target string) (string, error) {
+	fields := strings.Fields(target)
+	if len(fields) < 4 {
+		return "", fmt.Errorf("invalid location %q", target)
+	}
+
+	return normalizeRdata(c.zone, "LOC", strings.Join(fields, " ")), nil
+}
+
+// naptr formats the rdata of a NAPTR record
+func (c *v1Converter) naptr(record *dnsv1.NaptrRecord) (string, error) {
+	naptr := &NAPTRRdata{
+		Order:       record.Order,
+		Preference:  record.Preference,
+		Flags:       record.Flags,
+		Service:     record.Service,
+		Regexp:      record.Regexp,
+		Replacement: c.target(record.Replacement),
+	}
+	if record.Replacement == "" {
+		naptr.Replacement = "."
+	}
+	if err := naptr.Validate(); err != nil {
+		return "", err
+	}
+
+	formatted, err := characterStrings(naptr.Flags, naptr.Service, naptr.Regexp)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d %d %s %s", naptr.Order, naptr.Preference, formatted, naptr.Replacement), nil
+}
+
+// txt formats the rdata of a TXT or SPF record
+//
+// A v1 target already in presentation form, starting with a quote, is
+// parsed. Any other target is text, split into strings of 255 characters.
+func (c *v1Converter) txt(target string) (string, error) {
+	if strings.HasPrefix(target, `"`) {
+		rdata, err := ParseRdata("TXT", target)
+		if err != nil {
+			return "", err
+		}
+		return rdata.String(), nil
+	}
+
+	if target == "" {
+		return "", fmt.Errorf("empty text")
+	}
+
+	return c.typed(&TXTRdata{Strings: splitText(target)})
+}
+
+// v1Salt returns the salt of an NSEC3 or NSEC3PARAM record, "-" standing
+// for no salt
+func v1Salt(salt string) string {
+	if salt == "" {
+		return "-"
+	}
+
+	return salt
+}
@@ -0,0 +1,79 @@
+package dnsv2
+
+import (
+	"strings"
+	"testing"
+
+	dnsv1 "github.com/akamai/AkamaiOPEN-edgegrid-golang/configdns-v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertV1Zone(t *testing.T) {
+	zone := &dnsv1.Zone{}
+	zone.Zone.Name = "Example.com"
+	zone.Zone.Soa = &dnsv1.SoaRecord{TTL: 900, Originserver: "ns1", Contact: "hostmaster.example.com.", Serial: 7, Refresh: 3600, Retry: 600, Expire: 604800, Minimum: 300}
+	zone.Zone.A = []*dnsv1.ARecord{
+		{Name: "www", TTL: 300, Active: true, Target: "10.0.0.1"},
+		{Name: "www", TTL: 60, Active: true, Target: "10.0.0.2"},
+		{Name: "www", TTL: 60, Active: true, Target: "10.0.0.2"},
+		{Name: "old", TTL: 300, Active: false, Target: "10.0.0.3"},
+		{Name: "bad", TTL: 300, Active: true, Target: "2001:db8::1"},
+		{Name: "other.net.", TTL: 300, Active: true, Target: "10.0.0.4"},
+	}
+	zone.Zone.Aaaa = []*dnsv1.AaaaRecord{{Name: "www", TTL: 300, Active: true, Target: "2001:db8::1"}}
+	zone.Zone.Cname = []*dnsv1.CnameRecord{{Name: "alias", Active: true, Target: "www"}}
+	zone.Zone.Mx = []*dnsv1.MxRecord{{Name: "", TTL: 300, Active: true, Target: "mail.example.net.", Priority: 10}}
+	zone.Zone.Ns = []*dnsv1.NsRecord{{Name: "@", TTL: 86400, Active: true, Target: "ns1"}}
+	zone.Zone.Hinfo = []*dnsv1.HinfoRecord{{Name: "host", TTL: 300, Active: true, Hardware: "INTEL-386", Software: "Unix"}}
+	zone.Zone.Naptr = []*dnsv1.NaptrRecord{{Name: "sip", TTL: 300, Active: true, Order: 100, Preference: 10, Flags: "S", Service: "SIP+D2U", Replacement: "_sip._udp"}}
+	zone.Zone.Srv = []*dnsv1.SrvRecord{{Name: "_sip._tcp", TTL: 300, Active: true, Target: "sip", Priority: 70000, Port: 5060}}
+	zone.Zone.Txt = []*dnsv1.TxtRecord{
+		{Name: "long", TTL: 300, Active: true, Target: strings.Repeat("a", 300)},
+		{Name: "quoted", TTL: 300, Active: true, Target: `"v=spf1" "-all"`},
+	}
+
+	recordsets, report, err := ConvertV1Zone(zone)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []Recordset{
+		{Name: "example.com", Type: "SOA", TTL: 900, Rdata: []string{"ns1.example.com. hostmaster.example.com. 7 3600 600 604800 300"}},
+		{Name: "example.com", Type: "NS", TTL: 86400, Rdata: []string{"ns1.example.com."}},
+		{Name: "example.com", Type: "MX", TTL: 300, Rdata: []string{"10 mail.example.net."}},
+		{Name: "alias.example.com", Type: "CNAME", TTL: 900, Rdata: []string{"www.example.com."}},
+		{Name: "host.example.com", Type: "HINFO", TTL: 300, Rdata: []string{`"INTEL-386" "Unix"`}},
+		{Name: "long.example.com", Type: "TXT", TTL: 300, Rdata: []string{`"` + strings.Repeat("a", 255) + `" "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"`}},
+		{Name: "quoted.example.com", Type: "TXT", TTL: 300, Rdata: []string{`"v=spf1" "-all"`}},
+		{Name: "sip.example.com", Type: "NAPTR", TTL: 300, Rdata: []string{`100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`}},
+		{Name: "www.example.com", Type: "A", TTL: 60, Rdata: []string{"10.0.0.1", "10.0.0.2"}},
+		{Name: "www.example.com", Type: "AAAA", TTL: 300, Rdata: []string{"2001:0db8:0000:0000:0000:0000:0000:0001"}},
+	}, recordsets)
+
+	assert.True(t, report.Lossy())
+	assert.Equal(t, []string{
+		"www.example.com A converted: records with different TTLs, 60 used",
+		"www.example.com A converted: duplicate record 10.0.0.2 merged",
+		"old.example.com A dropped: inactive",
+		"bad.example.com A dropped: invalid IPv4 address",
+		"other.net A dropped: outside of zone example.com",
+		"alias.example.com CNAME converted: no TTL, 900 used",
+		"_sip._tcp.example.com SRV dropped: field value 70000 out of range",
+	}, strings.Split(report.String(), "\n"))
+	assert.Len(t, report.Dropped(), 4)
+}
+
+func TestConvertV1Zone_CnameConflict(t *testing.T) {
+	zone := &dnsv1.Zone{}
+	zone.Zone.Name = "example.com"
+	zone.Zone.Cname = []*dnsv1.CnameRecord{{Name: "www", TTL: 300, Active: true, Target: "example.net."}}
+	zone.Zone.Txt = []*dnsv1.TxtRecord{{Name: "www", TTL: 300, Active: true, Target: "x"}}
+
+	recordsets, report, err := ConvertV1Zone(zone)
+	assert.NoError(t, err)
+	assert.Len(t, recordsets, 2)
+	assert.Equal(t, []*V1ConversionIssue{{Name: "www.example.com", Type: "CNAME", Reason: "name also has records of other types, which Edge DNS rejects"}}, report.Issues)
+
+	_, _, err = ConvertV1Zone(&dnsv1.Zone{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,583 @@
+package dnsv2
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
+)
+
+// SignAndServeAlgorithmValue is the algorithm Edge DNS signs a zone with
+type SignAndServeAlgorithmValue string
+
+const (
+	SignAndServeRSASHA1         SignAndServeAlgorithmValue = "RSA_SHA1"
+	SignAndServeRSASHA256       SignAndServeAlgorithmValue = "RSA_SHA256"
+	SignAndServeRSASHA512       SignAndServeAlgorithmValue = "RSA_SHA512"
+	SignAndServeECDSAP256SHA256 SignAndServeAlgorithmValue = "ECDSA_P256_SHA256"
+	SignAndServeECDSAP384SHA384 SignAndServeAlgorithmValue = "ECDSA_P384_SHA384"
+)
+
+// signAndServeAlgorithmNumbers maps sign-and-serve algorithms to their
+// DNSSEC algorithm numbers
+var signAndServeAlgorithmNumbers = map[SignAndServeAlgorithmValue]uint8{
+	SignAndServeRSASHA1:         5,
+	SignAndServeRSASHA256:       8,
+	SignAndServeRSASHA512:       10,
+	SignAndServeECDSAP256SHA256: 13,
+	SignAndServeECDSAP384SHA384: 14,
+}
+
+// Number returns the DNSSEC algorithm number, as found in DNSKEY, DS and
+// RRSIG records, or 0 for an unknown algorithm
+func (algorithm SignAndServeAlgorithmValue) Number() uint8 {
+	return signAndServeAlgorithmNumbers[algorithm]
+}
+
+// EnableSignAndServe has Edge DNS sign a zone and serve it with DNSSEC,
+// with the given algorithm or, if empty, the default one
+//
+// Once the zone is signed, the DS records returned by GetDnsSecStatus must
+// be published at the registrar to complete the chain of trust.
+func EnableSignAndServe(zone string, algorithm SignAndServeAlgorithmValue) error {
+	if algorithm != "" && algorithm.Number() == 0 {
+		return &ZoneError{zoneName: zone, apiErrorMessage: fmt.Sprintf("unknown sign and serve algorithm %s", algorithm)}
+	}
+
+	return setSignAndServe(zone, true, algorithm)
+}
+
+// DisableSignAndServe stops signing a zone
+//
+// The DS records of the zone must be removed at the registrar first, or
+// validating resolvers fail to resolve the zone.
+func DisableSignAndServe(zone string) error {
+	return setSignAndServe(zone, false, "")
+}
+
+func setSignAndServe(zone string, signAndServe bool, algorithm SignAndServeAlgorithmValue) error {
+	current, err := GetZone(zone)
+	if err != nil {
+		return err
+	}
+
+	update := &ZoneCreate{
+		Zone:                  current.Zone,
+		Type:                  current.Type,
+		Masters:               current.Masters,
+		Comment:               current.Comment,
+		SignAndServe:          signAndServe,
+		SignAndServeAlgorithm: string(algorithm),
+		TsigKey:               current.TsigKey,
+	}
+
+	return update.Update(ZoneQueryString{Contract: current.ContractId})
+}
+
+// DnsSecRecords are the DNSKEY and DS records of a signed zone, in zone
+// file format
+type DnsSecRecords struct {
+	DnskeyRecord     string `json:"dnskeyRecord,omitempty"`
+	DsRecord         string `json:"dsRecord,omitempty"`
+	ExpectedTtl      int    `json:"expectedTtl,omitempty"`
+	LastModifiedDate string `json:"lastModifiedDate,omitempty"`
+}
+
+// DnsSecStatus is the DNSSEC status of a zone
+//
+// NewRecords is only set during a key rollover, until the new keys replace
+// CurrentRecords.
+type DnsSecStatus struct {
+	Zone           string         `json:"zone"`
+	Alerts         []string       `json:"alerts,omitempty"`
+	CurrentRecords *DnsSecRecords `json:"currentRecords,omitempty"`
+	NewRecords     *DnsSecRecords `json:"newRecords,omitempty"`
+}
+
+// DnsSecStatusRequest is the request body of GetDnsSecStatus
+type DnsSecStatusRequest struct {
+	Zones []string `json:"zones"`
+}
+
+// DnsSecStatusResponse is the response of GetDnsSecStatus
+type DnsSecStatusResponse struct {
+	DnsSecStatuses []*DnsSecStatus `json:"dnsSecStatuses"`
+}
+
+// GetDnsSecStatus retrieves the DNSSEC status and the DNSKEY and DS records
+// of signed zones
+//
+// Endpoint: POST /config-dns/v2/zones/dns-sec-status
+func GetDnsSecStatus(zones ...string) ([]*DnsSecStatus, error) {
+	req, err := client.NewJSONRequest(
+		Config,
+		"POST",
+		"/config-dns/v2/zones/dns-sec-status",
+		&DnsSecStatusRequest{Zones: zones},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.IsError(res) {
+		return nil, client.NewAPIError(res)
+	}
+
+	response := &DnsSecStatusResponse{}
+	if err := client.BodyJSON(res, response); err != nil {
+		return nil, err
+	}
+
+	return response.DnsSecStatuses, nil
+}
+
+// DS returns the DS records to publish at the registrar
+func (records *DnsSecRecords) DS(zone string) ([]*DSRdata, error) {
+	parsed, err := records.parse(zone, records.DsRecord, "DS")
+	if err != nil {
+		return nil, err
+	}
+
+	ds := make([]*DSRdata, len(parsed))
+	for i, rdata := range parsed {
+		ds[i] = rdata.(*DSRdata)
+	}
+
+	return ds, nil
+}
+
+// DNSKEY returns the DNSKEY records of the zone
+func (records *DnsSecRecords) DNSKEY(zone string) ([]*DNSKEYRdata, error) {
+	parsed, err := records.parse(zone, records.DnskeyRecord, "DNSKEY")
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*DNSKEYRdata, len(parsed))
+	for i, rdata := range parsed {
+		keys[i] = rdata.(*DNSKEYRdata)
+	}
+
+	return keys, nil
+}
+
+func (records *DnsSecRecords) parse(zone string, zoneFile string, recordType string) ([]Rdata, error) {
+	bodies, err := ParseZoneFile(zone, strings.NewReader(zoneFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed []Rdata
+	for _, body := range bodies {
+		if body.RecordType != recordType {
+			continue
+		}
+		rdata, err := body.GetRdata()
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, rdata...)
+	}
+
+	return parsed, nil
+}
+
+// KeyRolloverStateValue is the state of the keys of a signed zone, as seen
+// from Edge DNS and the registrar
+type KeyRolloverStateValue string
+
+const (
+	// KeyRolloverNone means no rollover is in progress and the DS records
+	// of the current keys are published
+	KeyRolloverNone KeyRolloverStateValue = "NONE"
+	// KeyRolloverDSNotPublished means no rollover is in progress but the DS
+	// records of the current keys are not published at the registrar
+	KeyRolloverDSNotPublished KeyRolloverStateValue = "DS_NOT_PUBLISHED"
+	// KeyRolloverPendingDS means new keys were generated and their DS
+	// records must be published at the registrar
+	KeyRolloverPendingDS KeyRolloverStateValue = "PENDING_DS"
+	// KeyRolloverDSPublished means the DS records of the new keys are
+	// published and Edge DNS has yet to switch to the new keys
+	KeyRolloverDSPublished KeyRolloverStateValue = "DS_PUBLISHED"
+)
+
+// RolloverState returns the key rollover state of a zone, given the DS
+// records currently published at the registrar
+func (status *DnsSecStatus) RolloverState(published []*DSRdata) (KeyRolloverStateValue, error) {
+	if status.NewRecords != nil && status.NewRecords.DsRecord != "" {
+		ds, err := status.NewRecords.DS(status.Zone)
+		if err != nil {
+			return "", err
+		}
+		if containsAllDS(published, ds) {
+			return KeyRolloverDSPublished, nil
+		}
+		return KeyRolloverPendingDS, nil
+	}
+
+	if status.CurrentRecords == nil {
+		return KeyRolloverDSNotPublished, nil
+	}
+
+	ds, err := status.CurrentRecords.DS(status.Zone)
+	if err != nil {
+		return "", err
+	}
+	if len(ds) > 0 && containsAllDS(published, ds) {
+		return KeyRolloverNone, nil
+	}
+
+	return KeyRolloverDSNotPublished, nil
+}
+
+func containsAllDS(published []*DSRdata, ds []*DSRdata) bool {
+	for _, wanted := range ds {
+		found := false
+		for _, p := range published {
+			if p.String() == wanted.String() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// KeyTag returns the key tag of a DNSKEY record, as found in the DS and
+// RRSIG records referring to it
+func (rdata *DNSKEYRdata) KeyTag() uint16 {
+	wire := rdata.wire()
+	var sum uint32
+	for i, b := range wire {
+		if i%2 == 0 {
+			sum += uint32(b) << 8
+		} else {
+			sum += uint32(b)
+		}
+	}
+	sum += sum >> 16 & 0xFFFF
+
+	return uint16(sum)
+}
+
+// ToDS computes the DS record of the DNSKEY record of a zone with the given
+// digest type: 1 for SHA-1, 2 for SHA-256 or 4 for SHA-384
+func (rdata *DNSKEYRdata) ToDS(zone string, digestType uint8) (*DSRdata, error) {
+	var h hash.Hash
+	switch digestType {
+	case 1:
+		h = sha1.New()
+	case 2:
+		h = sha256.New()
+	case 4:
+		h = sha512.New384()
+	default:
+		return nil, fmt.Errorf("unsupported digest type %d", digestType)
+	}
+
+	owner, err := wireName(zone)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(owner)
+	h.Write(rdata.wire())
+
+	return &DSRdata{
+		KeyTag:     rdata.KeyTag(),
+		Algorithm:  rdata.Algorithm,
+		DigestType: digestType,
+		Digest:     strings.ToUpper(hex.EncodeToString(h.Sum(nil))),
+	}, nil
+}
+
+// wire returns the DNSKEY rdata in wire format
+func (rdata *DNSKEYRdata) wire() []byte {
+	key, _ := base64.StdEncoding.DecodeString(rdata.Key)
+
+	return append([]byte{byte(rdata.Flags >> 8), byte(rdata.Flags), rdata.Protocol, rdata.Algorithm}, key...)
+}
+
+// wireName returns a domain name in canonical wire format
+func wireName(name string) ([]byte, error) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if strings.Contains(name, `\`) {
+		return nil, fmt.Errorf("escaped domain name %q not supported", name)
+	}
+
+	var wire []byte
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			if label == "" || len(label) > 63 {
+				return nil, fmt.Errorf("invalid domain name %q", name)
+			}
+			wire = append(wire, byte(len(label)))
+			wire = append(wire, label...)
+		}
+	}
+
+	return append(wire, 0), nil
+}
+
+// DnsSecSeverityValue tells whether a DNSSEC problem breaks validation
+type DnsSecSeverityValue string
+
+const (
+	DnsSecError   DnsSecSeverityValue = "ERROR"
+	DnsSecWarning DnsSecSeverityValue = "WARNING"
+)
+
+// DnsSecProblem is an inconsistency found by ValidateDnsSec
+type DnsSecProblem struct {
+	Name     string
+	Type     string
+	Severity DnsSecSeverityValue
+	Message  string
+}
+
+func (problem *DnsSecProblem) String() string {
+	return fmt.Sprintf("%s: %s %s: %s", problem.Severity, problem.Name, problem.Type, problem.Message)
+}
+
+// DnsSecValidation is the outcome of ValidateDnsSec
+type DnsSecValidation struct {
+	Zone     string
+	Problems []*DnsSecProblem
+}
+
+// Valid reports whether no error was found, warnings aside
+func (validation *DnsSecValidation) Valid() bool {
+	for _, problem := range validation.Problems {
+		if problem.Severity == DnsSecError {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (validation *DnsSecValidation) String() string {
+	lines := make([]string, len(validation.Problems))
+	for i, problem := range validation.Problems {
+		lines[i] = problem.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// DnsSecValidator checks the DNSSEC records of a zone for consistency and
+// expiry, without verifying signatures
+type DnsSecValidator struct {
+	// Now is the time signatures are checked at, the current time if zero
+	Now time.Time
+	// ExpiryWarning warns about signatures expiring within this duration
+	ExpiryWarning time.Duration
+	// DS are the DS records published at the registrar, which must match a
+	// key signing key of the zone, if set
+	DS []*DSRdata
+}
+
+// NewDnsSecValidator creates a DnsSecValidator warning about signatures
+// expiring within 3 days
+func NewDnsSecValidator() *DnsSecValidator {
+	return &DnsSecValidator{ExpiryWarning: 72 * time.Hour}
+}
+
+// ValidateDnsSec checks the DNSSEC records of a zone file
+//
+// See: DnsSecValidator.Validate()
+func ValidateDnsSec(zone string, zoneFile string) (*DnsSecValidation, error) {
+	records, err := ParseZoneFile(zone, strings.NewReader(zoneFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDnsSecValidator().Validate(zone, records), nil
+}
+
+// Validate checks the DNSSEC records of a zone
+//
+// The apex must have DNSKEY records, including a key signing key. Every
+// RRSIG record must cover an existing recordset of its name, with its TTL,
+// be signed by the zone with one of its keys, and be valid at Now. Every
+// recordset must be covered by an RRSIG record, except glue and the NS
+// records of delegations. DS records must match a key signing key.
+func (validator *DnsSecValidator) Validate(zone string, records []RecordBody) *DnsSecValidation {
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+	validation := &DnsSecValidation{Zone: zone}
+	problem := func(name string, recordType string, severity DnsSecSeverityValue, format string, args ...interface{}) {
+		validation.Problems = append(validation.Problems, &DnsSecProblem{
+			Name:     name,
+			Type:     recordType,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	now := validator.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	recordsets := map[string]RecordBody{}
+	delegations := map[string]bool{}
+	var keys []*DNSKEYRdata
+	var signatures []RecordBody
+	for _, record := range records {
+		name := strings.ToLower(strings.TrimSuffix(record.Name, "."))
+		record.Name = name
+		switch {
+		case record.RecordType == "RRSIG":
+			signatures = append(signatures, record)
+			continue
+		case record.RecordType == "NS" && name != zone:
+			delegations[name] = true
+		case record.RecordType == "DNSKEY" && name == zone:
+			rdata, err := record.GetRdata()
+			if err != nil {
+				problem(name, "DNSKEY", DnsSecError, "%s", err)
+				continue
+			}
+			for _, key := range rdata {
+				keys = append(keys, key.(*DNSKEYRdata))
+			}
+		}
+		recordsets[name+" "+record.RecordType] = record
+	}
+
+	keySigning := 0
+	for _, key := range keys {
+		switch {
+		case key.Flags&0x0100 == 0:
+			problem(zone, "DNSKEY", DnsSecError, "key %d is not a zone key", key.KeyTag())
+		case key.Flags&0x0001 != 0:
+			keySigning++
+		}
+	}
+	if len(keys) == 0 {
+		problem(zone, "DNSKEY", DnsSecError, "no DNSKEY records at the zone apex")
+	} else if keySigning == 0 {
+		problem(zone, "DNSKEY", DnsSecError, "no key signing key")
+	}
+
+	covered := map[string]bool{}
+	for _, record := range signatures {
+		for _, target := range record.Target {
+			rdata, err := ParseRdata("RRSIG", target)
+			if err != nil {
+				problem(record.Name, "RRSIG", DnsSecError, "%s", err)
+				continue
+			}
+			validator.validateSignature(zone, record.Name, rdata.(*RRSIGRdata), recordsets, keys, now, problem)
+			covered[record.Name+" "+rdata.(*RRSIGRdata).TypeCovered] = true
+		}
+	}
+
+	names := make([]string, 0, len(recordsets))
+	for key := range recordsets {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	for _, key := range names {
+		recordset := recordsets[key]
+		if covered[key] || !signedRecordset(recordset, delegations) {
+			continue
+		}
+		problem(recordset.Name, recordset.RecordType, DnsSecError, "recordset not signed")
+	}
+
+	for _, ds := range validator.DS {
+		if !dsMatchesKey(zone, ds, keys) {
+			problem(zone, "DS", DnsSecError, "DS record %s matches no key signing key", ds)
+		}
+	}
+
+	return validation
+}
+
+// validateSignature checks one RRSIG record
+func (validator *DnsSecValidator) validateSignature(zone string, name string, rrsig *RRSIGRdata, recordsets map[string]RecordBody, keys []*DNSKEYRdata, now time.Time, problem func(string, string, DnsSecSeverityValue, string, ...interface{})) {
+	recordset, exists := recordsets[name+" "+rrsig.TypeCovered]
+	if !exists {
+		problem(name, "RRSIG", DnsSecError, "covers missing %s recordset", rrsig.TypeCovered)
+	} else if int(rrsig.OriginalTTL) != recordset.TTL {
+		problem(name, "RRSIG", DnsSecWarning, "original TTL %d differs from %s TTL %d", rrsig.OriginalTTL, rrsig.TypeCovered, recordset.TTL)
+	}
+
+	if signer := strings.ToLower(strings.TrimSuffix(rrsig.SignerName, ".")); signer != zone {
+		problem(name, "RRSIG", DnsSecError, "signed by %s rather than the zone", rrsig.SignerName)
+	}
+
+	labels := len(strings.Split(strings.TrimPrefix(name, "*."), "."))
+	if int(rrsig.Labels) > labels {
+		problem(name, "RRSIG", DnsSecError, "%d labels, more than the %d of its name", rrsig.Labels, labels)
+	}
+
+	keyFound := false
+	for _, key := range keys {
+		if key.KeyTag() == rrsig.KeyTag && key.Algorithm == rrsig.Algorithm {
+			keyFound = true
+			break
+		}
+	}
+	if !keyFound {
+		problem(name, "RRSIG", DnsSecError, "%s signed with unknown key %d algorithm %d", rrsig.TypeCovered, rrsig.KeyTag, rrsig.Algorithm)
+	}
+
+	switch {
+	case now.Before(rrsig.Inception):
+		problem(name, "RRSIG", DnsSecError, "%s signature not valid before %s", rrsig.TypeCovered, rrsig.Inception.Format(time.RFC3339))
+	case !now.Before(rrsig.Expiration):
+		problem(name, "RRSIG", DnsSecError, "%s signature expired at %s", rrsig.TypeCovered, rrsig.Expiration.Format(time.RFC3339))
+	case now.Add(validator.ExpiryWarning).After(rrsig.Expiration):
+		problem(name, "RRSIG", DnsSecWarning, "%s signature expires at %s", rrsig.TypeCovered, rrsig.Expiration.Format(time.RFC3339))
+	}
+}
+
+// signedRecordset reports whether a recordset must be signed, which glue
+// and the NS records of delegations are not
+func signedRecordset(recordset RecordBody, delegations map[string]bool) bool {
+	for name := recordset.Name; name != ""; {
+		if delegations[name] {
+			return name == recordset.Name && recordset.RecordType == "DS"
+		}
+		i := strings.Index(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[i+1:]
+	}
+
+	return true
+}
+
+// dsMatchesKey reports whether a DS record matches a key signing key
+func dsMatchesKey(zone string, ds *DSRdata, keys []*DNSKEYRdata) bool {
+	for _, key := range keys {
+		if key.Flags&0x0001 == 0 || key.KeyTag() != ds.KeyTag || key.Algorithm != ds.Algorithm {
+			continue
+		}
+		computed, err := key.ToDS(zone, ds.DigestType)
+		if err == nil && computed.Digest == strings.ToUpper(ds.Digest) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,149 @@
+package dnsv2
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// dnssecTestKey is the DNSKEY of RFC 4034 section 5.4
+const dnssecTestKey = "AQOeiiR0GOMYkDshWoSKz9XzfwJr1AYtsmx3TGkJaNXVbfi/2pHm822aJ5iI9BMzNXxeYCmZDRD99WYwYqUSdjMmmAphXdvxegXd/M5+X7OrzKBaMbCVdFLUUh6DhweJBjEVv5f2wwjM9XzcnOf+EPbtG9DMBmADjFDc2w/rljwvFw=="
+
+func TestDNSKEYRdata_ToDS(t *testing.T) {
+	key := &DNSKEYRdata{Flags: 256, Protocol: 3, Algorithm: 5, Key: dnssecTestKey}
+	assert.Equal(t, uint16(60485), key.KeyTag())
+
+	ds, err := key.ToDS("dskey.example.com.", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "60485 5 1 2BB183AF5F22588179A53B0A98631FAD1A292118", ds.String())
+
+	_, err = key.ToDS("dskey.example.com", 3)
+	assert.Error(t, err)
+}
+
+func TestEnableSignAndServe(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones/example.com").
+		Reply(200).
+		JSON(map[string]interface{}{"zone": "example.com", "type": "PRIMARY", "contractId": "C-1", "signAndServe": false})
+	gock.New(changelistTestURL).
+		Put("/config-dns/v2/zones/example.com").
+		JSON(map[string]interface{}{"zone": "example.com", "type": "PRIMARY", "signAndServe": true, "signAndServeAlgorithm": "ECDSA_P256_SHA256"}).
+		Reply(200)
+
+	Init(config)
+
+	assert.Error(t, EnableSignAndServe("example.com", "DSA"))
+	assert.NoError(t, EnableSignAndServe("example.com", SignAndServeECDSAP256SHA256))
+	assert.True(t, gock.IsDone())
+}
+
+func TestGetDnsSecStatus(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/zones/dns-sec-status").
+		JSON(map[string]interface{}{"zones": []string{"example.com"}}).
+		Reply(200).
+		JSON(map[string]interface{}{"dnsSecStatuses": []map[string]interface{}{{
+			"zone": "example.com",
+			"currentRecords": map[string]interface{}{
+				"dnskeyRecord": "example.com. 7200 IN DNSKEY 257 3 5 " + dnssecTestKey,
+				"dsRecord":     "example.com. 7200 IN DS 60485 5 1 2BB183AF5F22588179A53B0A98631FAD1A292118",
+				"expectedTtl":  7200,
+			},
+			"newRecords": map[string]interface{}{
+				"dsRecord": "example.com. 7200 IN DS 60486 5 2 ABCDEF",
+			},
+		}}})
+
+	Init(config)
+
+	statuses, err := GetDnsSecStatus("example.com")
+	if !assert.NoError(t, err) || !assert.Len(t, statuses, 1) {
+		return
+	}
+	status := statuses[0]
+
+	keys, err := status.CurrentRecords.DNSKEY(status.Zone)
+	assert.NoError(t, err)
+	assert.Equal(t, []*DNSKEYRdata{{Flags: 257, Protocol: 3, Algorithm: 5, Key: dnssecTestKey}}, keys)
+
+	current, err := status.CurrentRecords.DS(status.Zone)
+	assert.NoError(t, err)
+	state, err := status.RolloverState(current)
+	assert.NoError(t, err)
+	assert.Equal(t, KeyRolloverPendingDS, state)
+
+	next, _ := status.NewRecords.DS(status.Zone)
+	state, _ = status.RolloverState(append(current, next...))
+	assert.Equal(t, KeyRolloverDSPublished, state)
+
+	status.NewRecords = nil
+	state, _ = status.RolloverState(current)
+	assert.Equal(t, KeyRolloverNone, state)
+	state, _ = status.RolloverState(nil)
+	assert.Equal(t, KeyRolloverDSNotPublished, state)
+}
+
+func TestDnsSecValidator_Validate(t *testing.T) {
+	ksk := &DNSKEYRdata{Flags: 257, Protocol: 3, Algorithm: 8, Key: dnssecTestKey}
+	zsk := &DNSKEYRdata{Flags: 256, Protocol: 3, Algorithm: 8, Key: strings.Replace(dnssecTestKey, "AQOe", "AQOf", 1)}
+	rrsig := func(covered string, ttl int, expiration string, key *DNSKEYRdata) string {
+		return fmt.Sprintf("RRSIG %s 8 2 %d %s 20200101000000 %d example.com. c2lnbmF0dXJl", covered, ttl, expiration, key.KeyTag())
+	}
+
+	zoneFile := strings.Join([]string{
+		"$TTL 3600",
+		"@ SOA ns1 hostmaster 1 3600 600 604800 300",
+		"@ " + rrsig("SOA", 3600, "20200301000000", zsk),
+		"@ NS ns1",
+		"@ " + rrsig("NS", 3600, "20200301000000", zsk),
+		"@ DNSKEY " + ksk.String(),
+		"@ DNSKEY " + zsk.String(),
+		"@ " + rrsig("DNSKEY", 3600, "20200301000000", ksk),
+		"ns1 A 10.0.0.1",
+		"ns1 " + rrsig("A", 3600, "20200301000000", zsk),
+		"www A 10.0.0.2",
+		"www " + rrsig("A", 300, "20200115000000", zsk),
+		"mail A 10.0.0.3",
+		"old " + rrsig("TXT", 3600, "20200301000000", zsk),
+		"sub NS ns.sub",
+		"ns.sub A 10.0.0.4",
+	}, "\n") + "\n"
+
+	records, err := ParseZoneFile("example.com", strings.NewReader(zoneFile))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	kskDS, _ := ksk.ToDS("example.com", 2)
+	zskDS, _ := zsk.ToDS("example.com", 2)
+	validator := NewDnsSecValidator()
+	validator.Now = time.Date(2020, 1, 14, 0, 0, 0, 0, time.UTC)
+	validator.DS = []*DSRdata{kskDS, zskDS}
+
+	validation := validator.Validate("example.com", records)
+	assert.False(t, validation.Valid())
+	assert.Equal(t, []string{
+		"WARNING: www.example.com RRSIG: original TTL 300 differs from A TTL 3600",
+		"WARNING: www.example.com RRSIG: A signature expires at 2020-01-15T00:00:00Z",
+		"ERROR: old.example.com RRSIG: covers missing TXT recordset",
+		"ERROR: mail.example.com A: recordset not signed",
+		fmt.Sprintf("ERROR: example.com DS: DS record %s matches no key signing key", zskDS),
+	}, strings.Split(validation.String(), "\n"))
+
+	validator.Now = time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC)
+	validation = validator.Validate("example.com", records)
+	assert.Contains(t, validation.String(), "ERROR: example.com RRSIG: SOA signature expired at 2020-03-01T00:00:00Z")
+
+	validation, err = ValidateDnsSec("example.com", "@ 3600 NS ns1.example.com.\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "ERROR: example.com DNSKEY: no DNSKEY records at the zone apex\nERROR: example.com NS: recordset not signed", validation.String())
+}
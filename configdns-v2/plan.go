@@ -0,0 +1,357 @@
+package dnsv2
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// ChangeOpValue is used to create an "enum" of possible RecordsetChange.Op values
+type ChangeOpValue string
+
+const (
+	// ChangeOpAdd RecordsetChange.Op value ADD
+	ChangeOpAdd ChangeOpValue = "ADD"
+	// ChangeOpEdit RecordsetChange.Op value EDIT
+	ChangeOpEdit ChangeOpValue = "EDIT"
+	// ChangeOpDelete RecordsetChange.Op value DELETE
+	ChangeOpDelete ChangeOpValue = "DELETE"
+)
+
+// RecordsetChange is a change to a recordset of a zone
+//
+// Current is nil for ChangeOpAdd, Desired is nil for ChangeOpDelete.
+type RecordsetChange struct {
+	Op      ChangeOpValue
+	Current *Recordset
+	Desired *Recordset
+}
+
+// Recordset returns the recordset sent to the API for the change
+func (change *RecordsetChange) Recordset() *Recordset {
+	if change.Desired != nil {
+		return change.Desired
+	}
+
+	return change.Current
+}
+
+// OwnershipFilter decides whether a recordset is managed by a plan
+type OwnershipFilter func(name string, recordType string) bool
+
+// OwnNames returns an OwnershipFilter owning the given names, of any type
+//
+// A pattern starting with "*." owns every name below it, but not the name
+// itself.
+func OwnNames(patterns ...string) OwnershipFilter {
+	return func(name string, recordType string) bool {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		for _, pattern := range patterns {
+			pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+			if strings.HasPrefix(pattern, "*.") && strings.HasSuffix(name, pattern[1:]) || name == pattern {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// OwnTypes returns an OwnershipFilter owning the given record types
+func OwnTypes(recordTypes ...string) OwnershipFilter {
+	return func(name string, recordType string) bool {
+		for _, owned := range recordTypes {
+			if strings.EqualFold(owned, recordType) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// PlanOptions configures Plan
+type PlanOptions struct {
+	// Owns limits the recordsets managed; nil manages all of them
+	Owns OwnershipFilter
+	// DefaultTTL is used for desired recordsets without TTL that do not exist yet
+	DefaultTTL int
+}
+
+// ZonePlan is the list of changes reconciling a zone with desired recordsets
+//
+// The SOA record, which Edge DNS maintains, is never changed, and the apex
+// NS records are never deleted.
+type ZonePlan struct {
+	Zone      string
+	Changes   []*RecordsetChange
+	Unchanged int
+	Ignored   int
+}
+
+// Plan fetches the recordsets of a zone and computes the changes to make
+// them match desired
+//
+// Every recordset of the zone is managed, see PlanWithOptions() to manage
+// only some of them.
+func Plan(zone string, desired []Recordset) (*ZonePlan, error) {
+	return PlanWithOptions(zone, desired, nil)
+}
+
+// PlanWithOptions fetches the recordsets of a zone and computes the changes
+// to make them match desired
+//
+// See: ComputePlan()
+func PlanWithOptions(zone string, desired []Recordset, options *PlanOptions) (*ZonePlan, error) {
+	current, err := GetRecordsets(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	return ComputePlan(zone, current, desired, options)
+}
+
+// ComputePlan computes the changes to make the current recordsets of a zone
+// match desired
+//
+// Names may be relative to the zone, "@" being the apex. Names, rdata and
+// rdata order are normalized before comparing, so that, for example,
+// "2001:db8::1" matches the expanded form returned by the API. A desired
+// recordset without TTL keeps its current TTL.
+func ComputePlan(zone string, current []Recordset, desired []Recordset, options *PlanOptions) (*ZonePlan, error) {
+	if options == nil {
+		options = &PlanOptions{}
+	}
+
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+	plan := &ZonePlan{Zone: zone}
+	owns := func(recordset Recordset) bool {
+		return recordset.Type != "SOA" && (options.Owns == nil || options.Owns(recordset.Name, recordset.Type))
+	}
+
+	existing := map[string]Recordset{}
+	for _, recordset := range current {
+		recordset = normalizeRecordset(zone, recordset)
+		existing[recordset.Name+" "+recordset.Type] = recordset
+	}
+
+	seen := map[string]bool{}
+	for _, recordset := range desired {
+		recordset = normalizeRecordset(zone, recordset)
+		key := recordset.Name + " " + recordset.Type
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate recordset %s %s", recordset.Name, recordset.Type)
+		}
+		seen[key] = true
+
+		if !owns(recordset) {
+			return nil, fmt.Errorf("recordset %s %s is not managed by this plan", recordset.Name, recordset.Type)
+		}
+
+		if len(recordset.Rdata) == 0 {
+			return nil, fmt.Errorf("recordset %s %s has no rdata", recordset.Name, recordset.Type)
+		}
+
+		currentRecordset, exists := existing[key]
+		if recordset.TTL == 0 {
+			if exists {
+				recordset.TTL = currentRecordset.TTL
+			} else if options.DefaultTTL > 0 {
+				recordset.TTL = options.DefaultTTL
+			} else {
+				return nil, fmt.Errorf("recordset %s %s has no TTL", recordset.Name, recordset.Type)
+			}
+		}
+
+		desiredRecordset := recordset
+		switch {
+		case !exists:
+			plan.Changes = append(plan.Changes, &RecordsetChange{Op: ChangeOpAdd, Desired: &desiredRecordset})
+		case currentRecordset.TTL != recordset.TTL || !equalRdata(currentRecordset.Rdata, recordset.Rdata):
+			plan.Changes = append(plan.Changes, &RecordsetChange{Op: ChangeOpEdit, Current: &currentRecordset, Desired: &desiredRecordset})
+		default:
+			plan.Unchanged++
+		}
+	}
+
+	for _, recordset := range current {
+		recordset = normalizeRecordset(zone, recordset)
+		if seen[recordset.Name+" "+recordset.Type] {
+			continue
+		}
+
+		if !owns(recordset) || recordset.Name == zone && recordset.Type == "NS" {
+			plan.Ignored++
+			continue
+		}
+
+		currentRecordset := recordset
+		plan.Changes = append(plan.Changes, &RecordsetChange{Op: ChangeOpDelete, Current: &currentRecordset})
+	}
+
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		return zoneFileLess(zone, *plan.Changes[i].Recordset(), *plan.Changes[j].Recordset())
+	})
+
+	return plan, nil
+}
+
+// normalizeRecordset returns recordset with an absolute lower case name
+// without trailing dot, an upper case type and normalized rdata
+func normalizeRecordset(zone string, recordset Recordset) Recordset {
+	name := strings.ToLower(strings.TrimSuffix(recordset.Name, "."))
+	switch {
+	case name == "@" || name == "":
+		name = zone
+	case name != zone && !strings.HasSuffix(name, "."+zone):
+		name = name + "." + zone
+	}
+
+	normalized := Recordset{
+		Name:  name,
+		Type:  strings.ToUpper(recordset.Type),
+		TTL:   recordset.TTL,
+		Rdata: make([]string, len(recordset.Rdata)),
+	}
+	for i, rdata := range recordset.Rdata {
+		normalized.Rdata[i] = normalizeRdata(zone, normalized.Type, rdata)
+	}
+
+	return normalized
+}
+
+// normalizeRdata returns rdata in the form returned by the API
+func normalizeRdata(zone string, recordType string, rdata string) string {
+	switch recordType {
+	case "AAAA":
+		if ip := net.ParseIP(rdata); ip != nil {
+			return FullIPv6(ip)
+		}
+		return rdata
+	case "LOC":
+		if len(strings.Fields(rdata)) == 12 {
+			return padCoordinates(strings.Join(strings.Fields(rdata), " "))
+		}
+		return rdata
+	case "TXT", "SPF":
+		if !strings.HasPrefix(rdata, `"`) {
+			return (&TXTRdata{Strings: splitText(rdata)}).String()
+		}
+		return rdata
+	}
+
+	fields := strings.Fields(rdata)
+	for _, i := range zoneFileRdataFormats[recordType].names {
+		if i < len(fields) {
+			if name, err := absoluteName(fields[i], zone+"."); err == nil {
+				fields[i] = name
+			}
+		}
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// equalRdata compares rdata regardless of order
+func equalRdata(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Empty reports whether the plan has no changes
+func (plan *ZonePlan) Empty() bool {
+	return len(plan.Changes) == 0
+}
+
+// Count returns the number of changes of op
+func (plan *ZonePlan) Count(op ChangeOpValue) int {
+	count := 0
+	for _, change := range plan.Changes {
+		if change.Op == op {
+			count++
+		}
+	}
+
+	return count
+}
+
+// String returns a human-readable plan
+//
+//	Zone example.com: 1 to add, 1 to change, 1 to delete
+//	+ www.example.com 300 A 10.0.0.1
+//	~ api.example.com CNAME
+//	    - 300 a.example.net.
+//	    + 600 b.example.net.
+//	- old.example.com 300 TXT "obsolete"
+func (plan *ZonePlan) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Zone %s: %d to add, %d to change, %d to delete\n",
+		plan.Zone, plan.Count(ChangeOpAdd), plan.Count(ChangeOpEdit), plan.Count(ChangeOpDelete))
+
+	for _, change := range plan.Changes {
+		switch change.Op {
+		case ChangeOpAdd:
+			for _, rdata := range change.Desired.Rdata {
+				fmt.Fprintf(&buf, "+ %s %d %s %s\n", change.Desired.Name, change.Desired.TTL, change.Desired.Type, rdata)
+			}
+		case ChangeOpDelete:
+			for _, rdata := range change.Current.Rdata {
+				fmt.Fprintf(&buf, "- %s %d %s %s\n", change.Current.Name, change.Current.TTL, change.Current.Type, rdata)
+			}
+		case ChangeOpEdit:
+			fmt.Fprintf(&buf, "~ %s %s\n", change.Desired.Name, change.Desired.Type)
+			for _, rdata := range change.Current.Rdata {
+				fmt.Fprintf(&buf, "    - %d %s\n", change.Current.TTL, rdata)
+			}
+			for _, rdata := range change.Desired.Rdata {
+				fmt.Fprintf(&buf, "    + %d %s\n", change.Desired.TTL, rdata)
+			}
+		}
+	}
+
+	return buf.String()
+}
+
+// Apply makes the changes of the plan through a single changelist, which is
+// submitted once all changes are added
+//
+// The changelist is discarded if a change can not be added or it became
+// stale. The plan is not recomputed: changes made to the zone since it was
+// computed may be overwritten.
+func (plan *ZonePlan) Apply() error {
+	if plan.Empty() {
+		return nil
+	}
+
+	changelist := NewChangelist(plan.Zone)
+	if err := changelist.Create(ChangelistOverwriteStale); err != nil {
+		return err
+	}
+
+	if err := changelist.AddChanges(plan.Changes...); err != nil {
+		changelist.Delete()
+		return err
+	}
+
+	if err := changelist.Submit(); err != nil {
+		changelist.Delete()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package dnsv2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+var planTestCurrent = []Recordset{
+	{Name: "example.com", Type: "SOA", TTL: 86400, Rdata: []string{"a1.akam.net. hostmaster.example.com. 1 3600 600 604800 300"}},
+	{Name: "example.com", Type: "NS", TTL: 86400, Rdata: []string{"a1.akam.net.", "a2.akam.net."}},
+	{Name: "www.example.com", Type: "AAAA", TTL: 300, Rdata: []string{"2001:0db8:0000:0000:0000:0000:0000:0001"}},
+	{Name: "www.example.com", Type: "A", TTL: 300, Rdata: []string{"10.0.0.2", "10.0.0.1"}},
+	{Name: "api.example.com", Type: "CNAME", TTL: 300, Rdata: []string{"a.example.net."}},
+	{Name: "old.example.com", Type: "TXT", TTL: 300, Rdata: []string{`"obsolete"`}},
+	{Name: "legacy.example.com", Type: "A", TTL: 300, Rdata: []string{"10.0.0.9"}},
+}
+
+func TestComputePlan(t *testing.T) {
+	desired := []Recordset{
+		{Name: "www", Type: "aaaa", Rdata: []string{"2001:db8::1"}},
+		{Name: "www.example.com.", Type: "A", TTL: 300, Rdata: []string{"10.0.0.1", "10.0.0.2"}},
+		{Name: "api", Type: "CNAME", TTL: 600, Rdata: []string{"b.example.net."}},
+		{Name: "@", Type: "MX", TTL: 3600, Rdata: []string{"10 mail"}},
+	}
+
+	plan, err := ComputePlan("example.com", planTestCurrent, desired, &PlanOptions{Owns: OwnNames("example.com", "*.example.com")})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 2, plan.Unchanged)
+	assert.Equal(t, 2, plan.Ignored)
+	assert.Equal(t, `Zone example.com: 1 to add, 1 to change, 2 to delete
++ example.com 3600 MX 10 mail.example.com.
+~ api.example.com CNAME
+    - 300 a.example.net.
+    + 600 b.example.net.
+- legacy.example.com 300 A 10.0.0.9
+- old.example.com 300 TXT "obsolete"
+`, plan.String())
+
+	plan, err = ComputePlan("example.com", planTestCurrent, nil, &PlanOptions{Owns: OwnNames("*.example.com")})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 5, plan.Count(ChangeOpDelete))
+
+	_, err = ComputePlan("example.com", planTestCurrent, desired, &PlanOptions{Owns: OwnTypes("A")})
+	assert.Error(t, err)
+
+	_, err = ComputePlan("example.com", nil, []Recordset{{Name: "new", Type: "A", Rdata: []string{"10.0.0.1"}}}, nil)
+	assert.Error(t, err)
+
+	_, err = ComputePlan("example.com", nil, []Recordset{{Name: "new", Type: "A", TTL: 60, Rdata: []string{"10.0.0.1"}}, {Name: "new.example.com", Type: "A", TTL: 60, Rdata: []string{"10.0.0.2"}}}, nil)
+	assert.Error(t, err)
+}
+
+func TestComputePlan_Text(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	current := []Recordset{
+		{Name: "example.com", Type: "TXT", TTL: 300, Rdata: []string{`"caf\195\169\009bar"`, `"` + long[:255] + `" "` + long[255:] + `"`}},
+	}
+	desired := []Recordset{
+		{Name: "@", Type: "TXT", TTL: 300, Rdata: []string{"caf\u00e9\tbar", long}},
+	}
+
+	plan, err := ComputePlan("example.com", current, desired, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, plan.Unchanged)
+	assert.True(t, plan.Empty(), plan.String())
+}
+
+func TestZonePlan_Apply(t *testing.T) {
+	defer gock.Off()
+
+	mock := gock.New("https://akaa-baseurl-xxxxxxxxxxx-xxxxxxxxxxxxx.luna.akamaiapis.net")
+	mock.
+		Get("/config-dns/v2/zones/example.com/recordsets").
+		MatchParam("showAll", "true").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		JSON(map[string]interface{}{"recordsets": planTestCurrent[:3]})
+
+	gock.New("https://akaa-baseurl-xxxxxxxxxxx-xxxxxxxxxxxxx.luna.akamaiapis.net").
+		Post("/config-dns/v2/changelists").
+		MatchParam("zone", "example.com").
+		MatchParam("overwrite", "STALE").
+		Reply(201)
+
+	gock.New("https://akaa-baseurl-xxxxxxxxxxx-xxxxxxxxxxxxx.luna.akamaiapis.net").
+		Get("/config-dns/v2/changelists/example.com$").
+		Times(2).
+		Reply(200).
+		JSON(map[string]interface{}{"zone": "example.com", "changeTag": "tag", "stale": false})
+
+	gock.New("https://akaa-baseurl-xxxxxxxxxxx-xxxxxxxxxxxxx.luna.akamaiapis.net").
+		Post("/config-dns/v2/changelists/example.com/recordsets/add-change").
+		JSON(map[string]interface{}{"name": "www.example.com", "type": "AAAA", "op": "DELETE", "ttl": 300, "rdata": []string{"2001:0db8:0000:0000:0000:0000:0000:0001"}}).
+		Reply(204)
+
+	gock.New("https://akaa-baseurl-xxxxxxxxxxx-xxxxxxxxxxxxx.luna.akamaiapis.net").
+		Post("/config-dns/v2/changelists/example.com/submit").
+		Reply(204)
+
+	Init(config)
+
+	plan, err := Plan("example.com", []Recordset{planTestCurrent[1]})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, plan.Count(ChangeOpDelete))
+
+	assert.NoError(t, plan.Apply())
+	assert.True(t, gock.IsDone())
+}
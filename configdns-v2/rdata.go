@@ -0,0 +1,845 @@
+package dnsv2
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rrsigTimeFormat is the format of the signature times of RRSIG records
+const rrsigTimeFormat = "20060102150405"
+
+// Rdata is the typed data of one record, as opposed to the flat RecordBody
+//
+// String returns the form used in the rdata of the API and in zone files.
+type Rdata interface {
+	Type() string
+	Validate() error
+	String() string
+}
+
+// ARdata is the data of an A record
+type ARdata struct {
+	Address net.IP
+}
+
+// AAAARdata is the data of an AAAA record
+type AAAARdata struct {
+	Address net.IP
+}
+
+// CNAMERdata is the data of a CNAME record
+type CNAMERdata struct {
+	Target string
+}
+
+// NSRdata is the data of an NS record
+type NSRdata struct {
+	Target string
+}
+
+// PTRRdata is the data of a PTR record
+type PTRRdata struct {
+	Target string
+}
+
+// MXRdata is the data of an MX record
+type MXRdata struct {
+	Preference uint16
+	Exchange   string
+}
+
+// SRVRdata is the data of an SRV record
+type SRVRdata struct {
+	Priority uint16
+	Weight   uint16
+	Port     uint16
+	Target   string
+}
+
+// TXTRdata is the data of a TXT record, its strings unescaped
+type TXTRdata struct {
+	Strings []string
+}
+
+// CAARdata is the data of a CAA record
+type CAARdata struct {
+	Flags uint8
+	Tag   string
+	Value string
+}
+
+// TLSARdata is the data of a TLSA record, the certificate data in hex
+type TLSARdata struct {
+	Usage        uint8
+	Selector     uint8
+	MatchingType uint8
+	Certificate  string
+}
+
+// SVCBParam is a key=value parameter of an SVCB or HTTPS record, Value
+// being empty for keys without value
+type SVCBParam struct {
+	Key   string
+	Value string
+}
+
+// SVCBRdata is the data of an SVCB record
+type SVCBRdata struct {
+	Priority uint16
+	Target   string
+	Params   []SVCBParam
+}
+
+// HTTPSRdata is the data of an HTTPS record
+type HTTPSRdata struct {
+	SVCBRdata
+}
+
+// DSRdata is the data of a DS record, the digest in hex
+type DSRdata struct {
+	KeyTag     uint16
+	Algorithm  uint8
+	DigestType uint8
+	Digest     string
+}
+
+// DNSKEYRdata is the data of a DNSKEY record, the key in base64
+type DNSKEYRdata struct {
+	Flags     uint16
+	Protocol  uint8
+	Algorithm uint8
+	Key       string
+}
+
+// NAPTRRdata is the data of a NAPTR record
+type NAPTRRdata struct {
+	Order       uint16
+	Preference  uint16
+	Flags       string
+	Service     string
+	Regexp      string
+	Replacement string
+}
+
+// SSHFPRdata is the data of an SSHFP record, the fingerprint in hex
+type SSHFPRdata struct {
+	Algorithm       uint8
+	FingerprintType uint8
+	Fingerprint     string
+}
+
+// SOARdata is the data of an SOA record
+type SOARdata struct {
+	MName   string
+	RName   string
+	Serial  uint32
+	Refresh uint32
+	Retry   uint32
+	Expire  uint32
+	Minimum uint32
+}
+
+// RRSIGRdata is the data of an RRSIG record, the signature in base64
+type RRSIGRdata struct {
+	TypeCovered string
+	Algorithm   uint8
+	Labels      uint8
+	OriginalTTL uint32
+	Expiration  time.Time
+	Inception   time.Time
+	KeyTag      uint16
+	SignerName  string
+	Signature   string
+}
+
+// ParseRdata parses the rdata of a record of the given type
+//
+// The rdata is validated. Hex data is returned in upper case, and base64
+// and hex data split by spaces are joined.
+func ParseRdata(recordType string, rdata string) (Rdata, error) {
+	lines, scanErr := scanZoneFile([]byte(rdata))
+	if scanErr != nil {
+		return nil, fmt.Errorf("%s rdata: %s", recordType, scanErr.Err)
+	}
+
+	var fields []zoneFileToken
+	for _, line := range lines {
+		fields = append(fields, line.tokens...)
+	}
+
+	parsed, err := parseRdataFields(strings.ToUpper(recordType), &rdataFields{fields: fields})
+	if err != nil {
+		return nil, fmt.Errorf("%s rdata %q: %s", strings.ToUpper(recordType), rdata, err)
+	}
+
+	if err := parsed.Validate(); err != nil {
+		return nil, fmt.Errorf("%s rdata %q: %s", parsed.Type(), rdata, err)
+	}
+
+	return parsed, nil
+}
+
+func parseRdataFields(recordType string, f *rdataFields) (Rdata, error) {
+	var parsed Rdata
+	switch recordType {
+	case "A":
+		parsed = &ARdata{Address: net.ParseIP(f.word())}
+	case "AAAA":
+		parsed = &AAAARdata{Address: net.ParseIP(f.word())}
+	case "CNAME":
+		parsed = &CNAMERdata{Target: f.word()}
+	case "NS":
+		parsed = &NSRdata{Target: f.word()}
+	case "PTR":
+		parsed = &PTRRdata{Target: f.word()}
+	case "MX":
+		parsed = &MXRdata{Preference: f.uint16(), Exchange: f.word()}
+	case "SRV":
+		parsed = &SRVRdata{Priority: f.uint16(), Weight: f.uint16(), Port: f.uint16(), Target: f.word()}
+	case "TXT":
+		txt := &TXTRdata{}
+		for !f.done() {
+			txt.Strings = append(txt.Strings, f.string())
+		}
+		parsed = txt
+	case "CAA":
+		parsed = &CAARdata{Flags: f.uint8(), Tag: f.word(), Value: f.string()}
+	case "TLSA":
+		parsed = &TLSARdata{Usage: f.uint8(), Selector: f.uint8(), MatchingType: f.uint8(), Certificate: strings.ToUpper(f.rest())}
+	case "SVCB", "HTTPS":
+		svcb := SVCBRdata{Priority: f.uint16(), Target: f.word()}
+		for !f.done() {
+			svcb.Params = append(svcb.Params, f.param())
+		}
+		if recordType == "HTTPS" {
+			parsed = &HTTPSRdata{svcb}
+		} else {
+			parsed = &svcb
+		}
+	case "DS":
+		parsed = &DSRdata{KeyTag: f.uint16(), Algorithm: f.uint8(), DigestType: f.uint8(), Digest: strings.ToUpper(f.rest())}
+	case "DNSKEY":
+		parsed = &DNSKEYRdata{Flags: f.uint16(), Protocol: f.uint8(), Algorithm: f.uint8(), Key: f.rest()}
+	case "NAPTR":
+		parsed = &NAPTRRdata{Order: f.uint16(), Preference: f.uint16(), Flags: f.string(), Service: f.string(), Regexp: f.string(), Replacement: f.word()}
+	case "SSHFP":
+		parsed = &SSHFPRdata{Algorithm: f.uint8(), FingerprintType: f.uint8(), Fingerprint: strings.ToUpper(f.rest())}
+	case "RRSIG":
+		parsed = &RRSIGRdata{TypeCovered: strings.ToUpper(f.word()), Algorithm: f.uint8(), Labels: f.uint8(), OriginalTTL: f.uint32(),
+			Expiration: f.time(), Inception: f.time(), KeyTag: f.uint16(), SignerName: f.word(), Signature: f.rest()}
+	case "SOA":
+		parsed = &SOARdata{MName: f.word(), RName: f.word(), Serial: f.uint32(), Refresh: f.uint32(), Retry: f.uint32(), Expire: f.uint32(), Minimum: f.uint32()}
+	default:
+		return nil, fmt.Errorf("unsupported record type")
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	if !f.done() {
+		return nil, fmt.Errorf("unexpected field %q", f.fields[f.next].text)
+	}
+
+	return parsed, nil
+}
+
+// rdataFields reads the fields of rdata in turn, keeping the first error
+type rdataFields struct {
+	fields []zoneFileToken
+	next   int
+	err    error
+}
+
+func (f *rdataFields) done() bool {
+	return f.err != nil || f.next >= len(f.fields)
+}
+
+func (f *rdataFields) token() (zoneFileToken, bool) {
+	if f.err != nil {
+		return zoneFileToken{}, false
+	}
+	if f.next >= len(f.fields) {
+		f.err = fmt.Errorf("missing fields")
+		return zoneFileToken{}, false
+	}
+
+	f.next++
+	return f.fields[f.next-1], true
+}
+
+func (f *rdataFields) word() string {
+	token, ok := f.token()
+	if ok && token.quoted {
+		f.err = fmt.Errorf("unexpected quoted string %q", token.text)
+	}
+
+	return token.text
+}
+
+func (f *rdataFields) string() string {
+	token, _ := f.token()
+	value, err := unescapeRdataString(token.text)
+	if err != nil && f.err == nil {
+		f.err = err
+	}
+
+	return value
+}
+
+func (f *rdataFields) rest() string {
+	var parts []string
+	for !f.done() {
+		parts = append(parts, f.word())
+	}
+	if len(parts) == 0 && f.err == nil {
+		f.err = fmt.Errorf("missing fields")
+	}
+
+	return strings.Join(parts, "")
+}
+
+func (f *rdataFields) param() SVCBParam {
+	token, ok := f.token()
+	if !ok {
+		return SVCBParam{}
+	}
+
+	if token.quoted {
+		f.err = fmt.Errorf("unexpected quoted string %q", token.text)
+		return SVCBParam{}
+	}
+
+	eq := strings.Index(token.text, "=")
+	if eq < 0 {
+		return SVCBParam{Key: token.text}
+	}
+
+	param := SVCBParam{Key: token.text[:eq], Value: token.text[eq+1:]}
+	if param.Value == "" && f.next < len(f.fields) && f.fields[f.next].quoted {
+		param.Value = f.fields[f.next].text
+		f.next++
+	}
+
+	value, err := unescapeRdataString(param.Value)
+	if err != nil {
+		f.err = err
+	}
+	param.Value = value
+
+	return param
+}
+
+func (f *rdataFields) uint(bits int) uint64 {
+	token, ok := f.token()
+	if !ok {
+		return 0
+	}
+
+	value, err := strconv.ParseUint(token.text, 10, bits)
+	if err != nil {
+		f.err = fmt.Errorf("invalid %d bit number %q", bits, token.text)
+	}
+
+	return value
+}
+
+func (f *rdataFields) uint8() uint8 {
+	return uint8(f.uint(8))
+}
+
+func (f *rdataFields) uint16() uint16 {
+	return uint16(f.uint(16))
+}
+
+func (f *rdataFields) uint32() uint32 {
+	return uint32(f.uint(32))
+}
+
+// time reads a signature time, either YYYYMMDDHHmmSS in UTC or seconds
+// since the epoch
+func (f *rdataFields) time() time.Time {
+	token, ok := f.token()
+	if !ok {
+		return time.Time{}
+	}
+
+	if len(token.text) == 14 {
+		value, err := time.Parse(rrsigTimeFormat, token.text)
+		if err != nil {
+			f.err = fmt.Errorf("invalid time %q", token.text)
+		}
+		return value
+	}
+
+	value, err := strconv.ParseUint(token.text, 10, 32)
+	if err != nil {
+		f.err = fmt.Errorf("invalid time %q", token.text)
+	}
+
+	return time.Unix(int64(value), 0).UTC()
+}
+
+// unescapeRdataString decodes the \X and \DDD escapes of a character string
+func unescapeRdataString(value string) (string, error) {
+	if !strings.Contains(value, `\`) {
+		return value, nil
+	}
+
+	var buf bytes.Buffer
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			buf.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		if i >= len(value) {
+			return "", fmt.Errorf("trailing backslash in %q", value)
+		}
+
+		if value[i] >= '0' && value[i] <= '9' {
+			if i+3 > len(value) {
+				return "", fmt.Errorf("invalid escape in %q", value)
+			}
+			code, err := strconv.ParseUint(value[i:i+3], 10, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape in %q", value)
+			}
+			buf.WriteByte(byte(code))
+			i += 2
+			continue
+		}
+
+		buf.WriteByte(value[i])
+	}
+
+	return buf.String(), nil
+}
+
+// quoteRdataString returns value as a quoted character string
+func quoteRdataString(value string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '"' || c == '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&buf, "\\%03d", c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte('"')
+
+	return buf.String()
+}
+
+// splitText splits text into character strings of at most 255 bytes
+func splitText(text string) []string {
+	var strs []string
+	for len(text) > 255 {
+		strs = append(strs, text[:255])
+		text = text[255:]
+	}
+
+	return append(strs, text)
+}
+
+// validateDomainName checks the length of a domain name and its labels
+func validateDomainName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty domain name")
+	}
+	if name == "." {
+		return nil
+	}
+
+	if len(name) > 255 {
+		return fmt.Errorf("domain name %q longer than 255 characters", name)
+	}
+
+	label := 0
+	for i := 0; i < len(name); i++ {
+		switch {
+		case name[i] == '\\':
+			i++
+			label++
+		case name[i] == '.':
+			if label == 0 {
+				return fmt.Errorf("empty label in domain name %q", name)
+			}
+			label = 0
+			continue
+		case name[i] <= ' ' || name[i] == '"' || name[i] > '~':
+			return fmt.Errorf("invalid character in domain name %q", name)
+		default:
+			label++
+		}
+		if label > 63 {
+			return fmt.Errorf("label longer than 63 characters in domain name %q", name)
+		}
+	}
+
+	return nil
+}
+
+func validateHex(value string, what string) error {
+	if value == "" {
+		return fmt.Errorf("empty %s", what)
+	}
+	if _, err := hex.DecodeString(value); err != nil {
+		return fmt.Errorf("invalid %s: not hex", what)
+	}
+
+	return nil
+}
+
+func (rdata *ARdata) Type() string {
+	return "A"
+}
+
+func (rdata *ARdata) Validate() error {
+	if rdata.Address == nil || rdata.Address.To4() == nil {
+		return fmt.Errorf("invalid IPv4 address")
+	}
+
+	return nil
+}
+
+func (rdata *ARdata) String() string {
+	return rdata.Address.String()
+}
+
+func (rdata *AAAARdata) Type() string {
+	return "AAAA"
+}
+
+func (rdata *AAAARdata) Validate() error {
+	if rdata.Address == nil || rdata.Address.To16() == nil || rdata.Address.To4() != nil {
+		return fmt.Errorf("invalid IPv6 address")
+	}
+
+	return nil
+}
+
+// String returns the address in the expanded form used by the API
+func (rdata *AAAARdata) String() string {
+	return FullIPv6(rdata.Address.To16())
+}
+
+func (rdata *CNAMERdata) Type() string {
+	return "CNAME"
+}
+
+func (rdata *CNAMERdata) Validate() error {
+	return validateDomainName(rdata.Target)
+}
+
+func (rdata *CNAMERdata) String() string {
+	return rdata.Target
+}
+
+func (rdata *NSRdata) Type() string {
+	return "NS"
+}
+
+func (rdata *NSRdata) Validate() error {
+	return validateDomainName(rdata.Target)
+}
+
+func (rdata *NSRdata) String() string {
+	return rdata.Target
+}
+
+func (rdata *PTRRdata) Type() string {
+	return "PTR"
+}
+
+func (rdata *PTRRdata) Validate() error {
+	return validateDomainName(rdata.Target)
+}
+
+func (rdata *PTRRdata) String() string {
+	return rdata.Target
+}
+
+func (rdata *MXRdata) Type() string {
+	return "MX"
+}
+
+func (rdata *MXRdata) Validate() error {
+	return validateDomainName(rdata.Exchange)
+}
+
+func (rdata *MXRdata) String() string {
+	return fmt.Sprintf("%d %s", rdata.Preference, rdata.Exchange)
+}
+
+func (rdata *SRVRdata) Type() string {
+	return "SRV"
+}
+
+func (rdata *SRVRdata) Validate() error {
+	return validateDomainName(rdata.Target)
+}
+
+func (rdata *SRVRdata) String() string {
+	return fmt.Sprintf("%d %d %d %s", rdata.Priority, rdata.Weight, rdata.Port, rdata.Target)
+}
+
+func (rdata *TXTRdata) Type() string {
+	return "TXT"
+}
+
+func (rdata *CAARdata) Type() string {
+	return "CAA"
+}
+
+func (rdata *CAARdata) String() string {
+	return fmt.Sprintf("%d %s %s", rdata.Flags, rdata.Tag, quoteRdataString(rdata.Value))
+}
+
+func (rdata *TLSARdata) Type() string {
+	return "TLSA"
+}
+
+func (rdata *TLSARdata) Validate() error {
+	return validateHex(rdata.Certificate, "certificate data")
+}
+
+func (rdata *TLSARdata) String() string {
+	return fmt.Sprintf("%d %d %d %s", rdata.Usage, rdata.Selector, rdata.MatchingType, rdata.Certificate)
+}
+
+func (rdata *SVCBRdata) Type() string {
+	return "SVCB"
+}
+
+func (rdata *HTTPSRdata) Type() string {
+	return "HTTPS"
+}
+
+func (rdata *DSRdata) Type() string {
+	return "DS"
+}
+
+func (rdata *DSRdata) String() string {
+	return fmt.Sprintf("%d %d %d %s", rdata.KeyTag, rdata.Algorithm, rdata.DigestType, rdata.Digest)
+}
+
+func (rdata *DNSKEYRdata) Type() string {
+	return "DNSKEY"
+}
+
+func (rdata *DNSKEYRdata) String() string {
+	return fmt.Sprintf("%d %d %d %s", rdata.Flags, rdata.Protocol, rdata.Algorithm, rdata.Key)
+}
+
+func (rdata *NAPTRRdata) Type() string {
+	return "NAPTR"
+}
+
+func (rdata *NAPTRRdata) Validate() error {
+	return validateDomainName(rdata.Replacement)
+}
+
+func (rdata *SSHFPRdata) Type() string {
+	return "SSHFP"
+}
+
+func (rdata *SSHFPRdata) Validate() error {
+	return validateHex(rdata.Fingerprint, "fingerprint")
+}
+
+func (rdata *SSHFPRdata) String() string {
+	return fmt.Sprintf("%d %d %s", rdata.Algorithm, rdata.FingerprintType, rdata.Fingerprint)
+}
+
+func (rdata *SOARdata) Type() string {
+	return "SOA"
+}
+
+func (rdata *SOARdata) String() string {
+	return fmt.Sprintf("%s %s %d %d %d %d %d", rdata.MName, rdata.RName, rdata.Serial, rdata.Refresh, rdata.Retry, rdata.Expire, rdata.Minimum)
+}
+
+func (rdata *TXTRdata) Validate() error {
+	if len(rdata.Strings) == 0 {
+		return fmt.Errorf("no strings")
+	}
+
+	for _, s := range rdata.Strings {
+		if len(s) > 255 {
+			return fmt.Errorf("string longer than 255 characters")
+		}
+	}
+
+	return nil
+}
+
+func (rdata *TXTRdata) String() string {
+	quoted := make([]string, len(rdata.Strings))
+	for i, s := range rdata.Strings {
+		quoted[i] = quoteRdataString(s)
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+func (rdata *CAARdata) Validate() error {
+	if rdata.Tag == "" || len(rdata.Tag) > 15 {
+		return fmt.Errorf("invalid tag %q", rdata.Tag)
+	}
+
+	for _, c := range rdata.Tag {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return fmt.Errorf("invalid tag %q", rdata.Tag)
+		}
+	}
+
+	return nil
+}
+
+func (rdata *SVCBRdata) Validate() error {
+	if err := validateDomainName(rdata.Target); err != nil {
+		return err
+	}
+
+	if rdata.Priority == 0 && len(rdata.Params) > 0 {
+		return fmt.Errorf("alias mode (priority 0) does not take parameters")
+	}
+
+	seen := map[string]bool{}
+	for _, param := range rdata.Params {
+		if param.Key == "" || seen[param.Key] {
+			return fmt.Errorf("invalid or duplicate parameter %q", param.Key)
+		}
+		seen[param.Key] = true
+
+		for _, c := range param.Key {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+				return fmt.Errorf("invalid parameter %q", param.Key)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (rdata *SVCBRdata) String() string {
+	fields := []string{strconv.Itoa(int(rdata.Priority)), rdata.Target}
+	for _, param := range rdata.Params {
+		if param.Value == "" {
+			fields = append(fields, param.Key)
+			continue
+		}
+
+		value := param.Value
+		if strings.ContainsAny(value, " \t\"\\;()") || strings.IndexFunc(value, func(c rune) bool { return c < ' ' || c > '~' }) >= 0 {
+			value = quoteRdataString(value)
+		}
+		fields = append(fields, param.Key+"="+value)
+	}
+
+	return strings.Join(fields, " ")
+}
+
+func (rdata *DSRdata) Validate() error {
+	return validateHex(rdata.Digest, "digest")
+}
+
+func (rdata *DNSKEYRdata) Validate() error {
+	if rdata.Protocol != 3 {
+		return fmt.Errorf("protocol must be 3")
+	}
+
+	if _, err := base64.StdEncoding.DecodeString(rdata.Key); err != nil || rdata.Key == "" {
+		return fmt.Errorf("invalid key: not base64")
+	}
+
+	return nil
+}
+
+func (rdata *NAPTRRdata) String() string {
+	return fmt.Sprintf("%d %d %s %s %s %s", rdata.Order, rdata.Preference,
+		quoteRdataString(rdata.Flags), quoteRdataString(rdata.Service), quoteRdataString(rdata.Regexp), rdata.Replacement)
+}
+
+func (rdata *SOARdata) Validate() error {
+	if err := validateDomainName(rdata.MName); err != nil {
+		return err
+	}
+
+	return validateDomainName(rdata.RName)
+}
+
+func (rdata *RRSIGRdata) Type() string {
+	return "RRSIG"
+}
+
+func (rdata *RRSIGRdata) Validate() error {
+	if rdata.TypeCovered == "" || strings.IndexFunc(rdata.TypeCovered, func(c rune) bool { return (c < 'A' || c > 'Z') && (c < '0' || c > '9') }) >= 0 {
+		return fmt.Errorf("invalid type covered %q", rdata.TypeCovered)
+	}
+
+	if err := validateDomainName(rdata.SignerName); err != nil {
+		return err
+	}
+
+	if _, err := base64.StdEncoding.DecodeString(rdata.Signature); err != nil || rdata.Signature == "" {
+		return fmt.Errorf("invalid signature: not base64")
+	}
+
+	return nil
+}
+
+func (rdata *RRSIGRdata) String() string {
+	return fmt.Sprintf("%s %d %d %d %s %s %d %s %s", rdata.TypeCovered, rdata.Algorithm, rdata.Labels, rdata.OriginalTTL,
+		rdata.Expiration.UTC().Format(rrsigTimeFormat), rdata.Inception.UTC().Format(rrsigTimeFormat), rdata.KeyTag, rdata.SignerName, rdata.Signature)
+}
+
+// GetRdata parses the rdata of the record
+//
+// See: ParseRdata()
+func (record *RecordBody) GetRdata() ([]Rdata, error) {
+	parsed := make([]Rdata, len(record.Target))
+	for i, target := range record.Target {
+		rdata, err := ParseRdata(record.RecordType, target)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = rdata
+	}
+
+	return parsed, nil
+}
+
+// NewRecordBodyFromRdata creates a new RecordBody from typed rdata, all of
+// the same type
+func NewRecordBodyFromRdata(name string, ttl int, rdata ...Rdata) (*RecordBody, error) {
+	if len(rdata) == 0 {
+		return nil, fmt.Errorf("record %s has no rdata", name)
+	}
+
+	record := &RecordBody{Name: name, RecordType: rdata[0].Type(), TTL: ttl, Active: true}
+	for _, r := range rdata {
+		if r.Type() != record.RecordType {
+			return nil, fmt.Errorf("record %s mixes %s and %s rdata", name, record.RecordType, r.Type())
+		}
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("%s record %s: %s", r.Type(), name, err)
+		}
+		record.Target = append(record.Target, r.String())
+	}
+
+	return record, nil
+}
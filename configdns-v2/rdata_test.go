@@ -0,0 +1,110 @@
+package dnsv2
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var rdataTestValid = []struct {
+	recordType string
+	rdata      string
+	expected   string
+}{
+	{"A", "10.0.0.1", "10.0.0.1"},
+	{"AAAA", "2001:db8::1", "2001:0db8:0000:0000:0000:0000:0000:0001"},
+	{"CNAME", "www.example.com.", "www.example.com."},
+	{"MX", "0 mail.example.com.", "0 mail.example.com."},
+	{"SRV", "10 60 5060 sip.example.com.", "10 60 5060 sip.example.com."},
+	{"TXT", `"v=spf1 -all" "a \"quoted\" \059 string"`, `"v=spf1 -all" "a \"quoted\" ; string"`},
+	{"TXT", `unquoted`, `"unquoted"`},
+	{"CAA", `0 issue "letsencrypt.org"`, `0 issue "letsencrypt.org"`},
+	{"TLSA", "3 1 1 0c72ac70b745ac19998811b131d662c9 ac69dbdbe7cb23e5b514b56664c5d3d6", "3 1 1 0C72AC70B745AC19998811B131D662C9AC69DBDBE7CB23E5B514B56664C5D3D6"},
+	{"HTTPS", `1 . alpn=h2,h3 port=443 ech="AEn+DQBFKwAgACABWIHUGj4u"`, `1 . alpn=h2,h3 port=443 ech=AEn+DQBFKwAgACABWIHUGj4u`},
+	{"SVCB", `0 svc.example.com.`, `0 svc.example.com.`},
+	{"DS", "60485 5 1 2BB183AF5F22588179A53B0A98631FAD1A292118", "60485 5 1 2BB183AF5F22588179A53B0A98631FAD1A292118"},
+	{"DNSKEY", "257 3 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0d xCjjnopKl+GqJxpVXckHAeF+KkxLbxIL fDLUT0rAK9iUzy1L53eKGQ==", "257 3 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ=="},
+	{"NAPTR", `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`, `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`},
+	{"SSHFP", "4 2 123456789abcdef67890123456789abcdef67890123456789abcdef123456789", "4 2 123456789ABCDEF67890123456789ABCDEF67890123456789ABCDEF123456789"},
+	{"SOA", "a1.akam.net. hostmaster.example.com. 1 3600 600 604800 300", "a1.akam.net. hostmaster.example.com. 1 3600 600 604800 300"},
+	{"RRSIG", "a 8 2 3600 1583020800 20200101000000 60485 example.com. c2ln bmF0dXJl", "A 8 2 3600 20200301000000 20200101000000 60485 example.com. c2lnbmF0dXJl"},
+}
+
+func TestParseRdata(t *testing.T) {
+	for _, test := range rdataTestValid {
+		rdata, err := ParseRdata(test.recordType, test.rdata)
+		if !assert.NoError(t, err, test.rdata) {
+			continue
+		}
+		assert.Equal(t, test.recordType, rdata.Type())
+		assert.Equal(t, test.expected, rdata.String())
+	}
+
+	mx, _ := ParseRdata("mx", "0 mail.example.com.")
+	assert.Equal(t, &MXRdata{Preference: 0, Exchange: "mail.example.com."}, mx)
+}
+
+func TestParseRdata_Invalid(t *testing.T) {
+	for recordType, rdata := range map[string]string{
+		"A":      "2001:db8::1",
+		"AAAA":   "10.0.0.1",
+		"MX":     "65536 mail.example.com.",
+		"CNAME":  `"www.example.com."`,
+		"SRV":    "10 60 sip.example.com.",
+		"TXT":    "",
+		"CAA":    `0 is-sue "ca.example.net"`,
+		"DS":     "60485 5 1 XYZ",
+		"DNSKEY": "257 2 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0d",
+		"HTTPS":  "0 . alpn=h2",
+		"NS":     "a..example.com.",
+		"LOC":    "52 22 23.000 N 4 53 32.000 E -2.00m 0.00m 10000m 10m",
+	} {
+		_, err := ParseRdata(recordType, rdata)
+		assert.Error(t, err, recordType)
+	}
+}
+
+func TestNewRecordBodyFromRdata(t *testing.T) {
+	record, err := NewRecordBodyFromRdata("www.example.com", 300,
+		&ARdata{Address: net.ParseIP("10.0.0.1")},
+		&ARdata{Address: net.ParseIP("10.0.0.2")})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, &RecordBody{Name: "www.example.com", RecordType: "A", TTL: 300, Active: true, Target: []string{"10.0.0.1", "10.0.0.2"}}, record)
+
+	rdata, err := record.GetRdata()
+	assert.NoError(t, err)
+	assert.Equal(t, &ARdata{Address: net.ParseIP("10.0.0.2")}, rdata[1])
+
+	_, err = NewRecordBodyFromRdata("www.example.com", 300, &ARdata{Address: net.ParseIP("10.0.0.1")}, &CNAMERdata{Target: "a."})
+	assert.Error(t, err)
+
+	_, err = NewRecordBodyFromRdata("www.example.com", 300, &MXRdata{Preference: 10})
+	assert.Error(t, err)
+}
+
+func FuzzParseRdata(f *testing.F) {
+	for _, test := range rdataTestValid {
+		f.Add(test.recordType, test.rdata)
+	}
+	f.Add("TXT", `"\255\000" ( "multi"`+"\n"+`"line" )`)
+	f.Add("SVCB", `1 svc. key65000="a b" no-default-alpn`)
+
+	f.Fuzz(func(t *testing.T, recordType string, rdata string) {
+		parsed, err := ParseRdata(recordType, rdata)
+		if err != nil {
+			return
+		}
+
+		formatted := parsed.String()
+		reparsed, err := ParseRdata(recordType, formatted)
+		if err != nil {
+			t.Fatalf("%s %q formatted as %q does not parse: %s", recordType, rdata, formatted, err)
+		}
+		if reparsed.String() != formatted {
+			t.Fatalf("%s %q formatted as %q, then %q", recordType, rdata, formatted, reparsed.String())
+		}
+	})
+}
@@ -65,6 +65,16 @@ func (record *RecordBody) ToMap() map[string]interface{} {
 	}
 }
 
+// ToRecordset returns the record as a Recordset
+func (record *RecordBody) ToRecordset() Recordset {
+	return Recordset{
+		Name:  record.Name,
+		Type:  record.RecordType,
+		TTL:   record.TTL,
+		Rdata: record.Target,
+	}
+}
+
 func NewRecordBody(params RecordBody) *RecordBody {
 	recordbody := &RecordBody{Name: params.Name}
 	return recordbody
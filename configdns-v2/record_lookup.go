@@ -145,6 +145,37 @@ func GetRecordList(zone string, name string, record_type string) (*RecordSetResp
 	}
 }
 
+// GetRecordsets retrieves all the recordsets of a zone
+func GetRecordsets(zone string) ([]Recordset, error) {
+	records := NewRecordSetResponse(zone)
+
+	req, err := client.NewRequest(
+		Config,
+		"GET",
+		"/config-dns/v2/zones/"+zone+"/recordsets?showAll=true",
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return nil, err
+	}
+	if client.IsError(res) && res.StatusCode != 404 {
+		return nil, client.NewAPIError(res)
+	} else if res.StatusCode == 404 {
+		return nil, &ZoneError{zoneName: zone}
+	}
+
+	if err = client.BodyJSON(res, records); err != nil {
+		return nil, err
+	}
+
+	return records.Recordsets, nil
+}
+
 func GetRdata(zone string, name string, record_type string) ([]string, error) {
 	records, err := GetRecordList(zone, name, record_type)
 	if err != nil {
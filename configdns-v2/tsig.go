@@ -0,0 +1,347 @@
+package dnsv2
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TSIGKey is a key authenticating the zone transfers of secondary zones
+// from their masters
+type TSIGKey struct {
+	Name      string `json:"name"`
+	Algorithm string `json:"algorithm"`
+	Secret    string `json:"secret"`
+}
+
+// TSIGKeyResponse is a TSIG key with the number of zones using it
+type TSIGKeyResponse struct {
+	TSIGKey
+	ZoneCount int `json:"zonesCount,omitempty"`
+}
+
+// TSIGKeyListResponse is the response of ListTSIGKeys
+type TSIGKeyListResponse struct {
+	Metadata *TSIGKeyListMetadata `json:"metadata,omitempty"`
+	Keys     []*TSIGKeyResponse   `json:"keys"`
+}
+
+// TSIGKeyListMetadata describes the keys returned by ListTSIGKeys
+type TSIGKeyListMetadata struct {
+	ContractIds   []string `json:"contractIds,omitempty"`
+	TotalElements int      `json:"totalElements"`
+}
+
+// TSIGKeyQueryString filters the keys returned by ListTSIGKeys
+type TSIGKeyQueryString struct {
+	ContractIds []string
+	Search      string
+	SortBy      []string
+	Gid         string
+}
+
+// TSIGKeyBulkUpdate is the request body of UpdateTSIGKey
+type TSIGKeyBulkUpdate struct {
+	Key   *TSIGKey `json:"key"`
+	Zones []string `json:"zones"`
+}
+
+// TSIGZonesResponse lists the zones using a TSIG key
+type TSIGZonesResponse struct {
+	Zones []string `json:"zones"`
+}
+
+// tsigAlgorithms maps the TSIG algorithms to their digest length, in bytes,
+// the minimum secret length recommended by RFC 2845 and RFC 4635
+var tsigAlgorithms = map[string]int{
+	"hmac-md5":    16,
+	"hmac-sha1":   20,
+	"hmac-sha224": 28,
+	"hmac-sha256": 32,
+	"hmac-sha384": 48,
+	"hmac-sha512": 64,
+}
+
+// NewTSIGKey creates a new TSIGKey
+func NewTSIGKey(name string, algorithm string, secret string) *TSIGKey {
+	return &TSIGKey{Name: name, Algorithm: algorithm, Secret: secret}
+}
+
+// Validate checks the name, algorithm and secret format of a key, as Edge
+// DNS and BIND do
+//
+// The algorithm may be written as in BIND, with the HMAC-MD5.SIG-ALG.REG.INT
+// name accepted for hmac-md5. The secret must be base64.
+//
+// See: TSIGKey.ShortSecret()
+func (key *TSIGKey) Validate() error {
+	if key.Name == "" {
+		return fmt.Errorf("TSIG key without name")
+	}
+	if err := validateDomainName(key.Name); err != nil {
+		return fmt.Errorf("TSIG key %s: %s", key.Name, err)
+	}
+
+	if _, ok := tsigAlgorithms[normalizeTSIGAlgorithm(key.Algorithm)]; !ok {
+		return fmt.Errorf("TSIG key %s: unsupported algorithm %q", key.Name, key.Algorithm)
+	}
+
+	if key.Secret == "" {
+		return fmt.Errorf("TSIG key %s: empty secret", key.Name)
+	}
+	if _, err := base64.StdEncoding.DecodeString(key.Secret); err != nil {
+		return fmt.Errorf("TSIG key %s: secret is not base64", key.Name)
+	}
+
+	return nil
+}
+
+// ShortSecret reports whether the secret is shorter than the algorithm
+// digest, the minimum length recommended by RFC 2845 and RFC 4635
+//
+// Such keys are accepted by Edge DNS and BIND, but are weaker than the
+// algorithm allows.
+func (key *TSIGKey) ShortSecret() bool {
+	secret, err := base64.StdEncoding.DecodeString(key.Secret)
+	if err != nil {
+		return false
+	}
+
+	return len(secret) < tsigAlgorithms[normalizeTSIGAlgorithm(key.Algorithm)]
+}
+
+// normalizeTSIGAlgorithm returns the algorithm name used by Edge DNS
+func normalizeTSIGAlgorithm(algorithm string) string {
+	algorithm = strings.ToLower(strings.TrimSuffix(algorithm, "."))
+	if algorithm == "hmac-md5.sig-alg.reg.int" {
+		return "hmac-md5"
+	}
+
+	return algorithm
+}
+
+// ParseBINDTSIGKeys parses the key statements of a BIND configuration, such
+// as the output of tsig-keygen
+//
+//	key "transfer.example.com" {
+//		algorithm hmac-sha256;
+//		secret "hbM1zgrLYmJBbaW0CpQ1FVV0iA3eHI9a+VMPR1jl5ew=";
+//	};
+func ParseBINDTSIGKeys(conf string) ([]*TSIGKey, error) {
+	var keys []*TSIGKey
+	tokens := bindTokens(conf)
+	for i := 0; i < len(tokens); i++ {
+		// key references, as in allow-transfer { key name; }, are skipped
+		if tokens[i] != "key" || i+2 >= len(tokens) || tokens[i+2] != "{" {
+			continue
+		}
+
+		key := &TSIGKey{Name: strings.Trim(tokens[i+1], `"`)}
+		for i += 3; i < len(tokens) && tokens[i] != "}"; i++ {
+			if i+2 >= len(tokens) || tokens[i+2] != ";" {
+				return nil, fmt.Errorf("key %s: invalid %s clause", key.Name, tokens[i])
+			}
+			switch tokens[i] {
+			case "algorithm":
+				key.Algorithm = normalizeTSIGAlgorithm(strings.Trim(tokens[i+1], `"`))
+			case "secret":
+				key.Secret = strings.Trim(tokens[i+1], `"`)
+			default:
+				return nil, fmt.Errorf("key %s: unknown clause %s", key.Name, tokens[i])
+			}
+			i += 2
+		}
+		if i >= len(tokens) {
+			return nil, fmt.Errorf("key %s: missing }", key.Name)
+		}
+
+		if err := key.Validate(); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// bindTokens splits a BIND configuration into words, quoted strings and
+// punctuation, dropping comments
+func bindTokens(conf string) []string {
+	var tokens []string
+	for i := 0; i < len(conf); i++ {
+		c := conf[i]
+		switch {
+		case c == '#' || c == '/' && i+1 < len(conf) && conf[i+1] == '/':
+			for i < len(conf) && conf[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(conf) && conf[i+1] == '*':
+			end := strings.Index(conf[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 3
+		case c == '{' || c == '}' || c == ';':
+			tokens = append(tokens, string(c))
+		case c == '"':
+			end := strings.IndexByte(conf[i+1:], '"')
+			if end < 0 {
+				return append(tokens, conf[i:])
+			}
+			tokens = append(tokens, conf[i:i+end+2])
+			i += end + 1
+		case c > ' ':
+			start := i
+			for i+1 < len(conf) && conf[i+1] > ' ' && !strings.ContainsRune(`{};"`, rune(conf[i+1])) {
+				i++
+			}
+			tokens = append(tokens, conf[start:i+1])
+		}
+	}
+
+	return tokens
+}
+
+// ListTSIGKeys retrieves the TSIG keys of the zones the credentials can
+// access
+//
+// Endpoint: GET /config-dns/v2/keys{?contractIds,search,sortBy,gid}
+func ListTSIGKeys(querystring TSIGKeyQueryString) (*TSIGKeyListResponse, error) {
+	params := url.Values{}
+	if len(querystring.ContractIds) > 0 {
+		params.Set("contractIds", strings.Join(querystring.ContractIds, ","))
+	}
+	if querystring.Search != "" {
+		params.Set("search", querystring.Search)
+	}
+	if len(querystring.SortBy) > 0 {
+		params.Set("sortBy", strings.Join(querystring.SortBy, ","))
+	}
+	if querystring.Gid != "" {
+		params.Set("gid", querystring.Gid)
+	}
+
+	path := "/config-dns/v2/keys"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	list := &TSIGKeyListResponse{}
+	if err := apiRequest("GET", path, nil, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// GetZoneTSIGKey retrieves the TSIG key of a secondary zone
+//
+// Endpoint: GET /config-dns/v2/zones/{zone}/key
+func GetZoneTSIGKey(zone string) (*TSIGKeyResponse, error) {
+	key := &TSIGKeyResponse{}
+	if err := zoneRequest(zone, "GET", "/config-dns/v2/zones/"+zone+"/key", nil, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Save creates or replaces the TSIG key of a secondary zone
+//
+// Endpoint: PUT /config-dns/v2/zones/{zone}/key
+func (key *TSIGKey) Save(zone string) error {
+	if err := key.Validate(); err != nil {
+		return &ZoneError{zoneName: zone, apiErrorMessage: err.Error()}
+	}
+
+	return zoneRequest(zone, "PUT", "/config-dns/v2/zones/"+zone+"/key", key, nil)
+}
+
+// DeleteZoneTSIGKey removes the TSIG key of a secondary zone, whose
+// transfers are then no longer authenticated
+//
+// Endpoint: DELETE /config-dns/v2/zones/{zone}/key
+func DeleteZoneTSIGKey(zone string) error {
+	return zoneRequest(zone, "DELETE", "/config-dns/v2/zones/"+zone+"/key", nil, nil)
+}
+
+// GetTSIGKeyZones retrieves the zones using a TSIG key
+//
+// Endpoint: POST /config-dns/v2/keys/used-by
+func GetTSIGKeyZones(key *TSIGKey) ([]string, error) {
+	zones := &TSIGZonesResponse{}
+	if err := apiRequest("POST", "/config-dns/v2/keys/used-by", key, zones); err != nil {
+		return nil, err
+	}
+
+	return zones.Zones, nil
+}
+
+// UpdateTSIGKey assigns a key to secondary zones, creating the key or
+// updating the algorithm and secret of the key of the same name
+//
+// To rotate a secret, configure the new secret on the masters, then update
+// the key of all the zones using it:
+//
+//	zones, err := dnsv2.GetTSIGKeyZones(oldKey)
+//	err = dnsv2.UpdateTSIGKey(newKey, zones)
+//
+// Endpoint: POST /config-dns/v2/keys/bulk-update
+func UpdateTSIGKey(key *TSIGKey, zones []string) error {
+	if err := key.Validate(); err != nil {
+		return err
+	}
+
+	return apiRequest("POST", "/config-dns/v2/keys/bulk-update", &TSIGKeyBulkUpdate{Key: key, Zones: zones}, nil)
+}
+
+// ZoneTransferStatus is the outcome of the last zone transfers of a
+// secondary zone from its masters
+type ZoneTransferStatus struct {
+	Zone               string   `json:"zone"`
+	MasterIps          []string `json:"masterIps,omitempty"`
+	LastAttempt        string   `json:"lastAttempt,omitempty"`
+	LastSuccess        string   `json:"lastSuccess,omitempty"`
+	LastSerial         uint32   `json:"lastSerial,omitempty"`
+	LastTransferResult string   `json:"lastTransferResult,omitempty"`
+	LastTransferError  string   `json:"lastTransferError,omitempty"`
+}
+
+// ZoneTransferStatusRequest is the request body of GetZoneTransferStatus
+type ZoneTransferStatusRequest struct {
+	Zones []string `json:"zones"`
+}
+
+// ZoneTransferStatusResponse is the response of GetZoneTransferStatus
+type ZoneTransferStatusResponse struct {
+	Zones []*ZoneTransferStatus `json:"zones"`
+}
+
+// GetZoneTransferStatus retrieves the transfer status of secondary zones
+//
+// Endpoint: POST /config-dns/v2/zones/zone-transfer-status
+func GetZoneTransferStatus(zones ...string) ([]*ZoneTransferStatus, error) {
+	response := &ZoneTransferStatusResponse{}
+	if err := apiRequest("POST", "/config-dns/v2/zones/zone-transfer-status", &ZoneTransferStatusRequest{Zones: zones}, response); err != nil {
+		return nil, err
+	}
+
+	return response.Zones, nil
+}
+
+// LastTransfer returns the time of the last successful transfer, the zero
+// time if the zone was never transferred
+func (status *ZoneTransferStatus) LastTransfer() (time.Time, error) {
+	if status.LastSuccess == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, status.LastSuccess)
+}
+
+// Failing reports whether the last transfer attempt failed
+func (status *ZoneTransferStatus) Failing() bool {
+	return status.LastTransferError != "" || status.LastAttempt != "" && status.LastAttempt != status.LastSuccess
+}
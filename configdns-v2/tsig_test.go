@@ -0,0 +1,153 @@
+package dnsv2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const tsigTestSecret = "hbM1zgrLYmJBbaW0CpQ1FVV0iA3eHI9a+VMPR1jl5ew="
+
+func TestTSIGKey_Validate(t *testing.T) {
+	assert.NoError(t, NewTSIGKey("transfer.example.com", "hmac-sha256", tsigTestSecret).Validate())
+	assert.NoError(t, NewTSIGKey("transfer", "HMAC-MD5.SIG-ALG.REG.INT.", tsigTestSecret).Validate())
+
+	for _, key := range []*TSIGKey{
+		NewTSIGKey("", "hmac-sha256", tsigTestSecret),
+		NewTSIGKey("a..b", "hmac-sha256", tsigTestSecret),
+		NewTSIGKey("transfer", "hmac-sha3", tsigTestSecret),
+		NewTSIGKey("transfer", "hmac-sha256", ""),
+		NewTSIGKey("transfer", "hmac-sha256", "not base64!"),
+	} {
+		assert.Error(t, key.Validate(), key.Name+" "+key.Algorithm)
+	}
+
+	// secrets shorter than the digest are weak, but valid
+	short := NewTSIGKey("transfer", "hmac-sha512", tsigTestSecret)
+	assert.NoError(t, short.Validate())
+	assert.True(t, short.ShortSecret())
+	assert.False(t, NewTSIGKey("transfer", "hmac-sha256", tsigTestSecret).ShortSecret())
+}
+
+func TestParseBINDTSIGKeys(t *testing.T) {
+	keys, err := ParseBINDTSIGKeys(`
+# generated by tsig-keygen
+key "transfer.example.com" {
+	algorithm hmac-sha256;
+	secret "` + tsigTestSecret + `";
+};
+/* legacy key */
+key legacy { algorithm HMAC-MD5.SIG-ALG.REG.INT; secret "` + tsigTestSecret + `"; }; // inline
+options { directory "/var/named"; allow-transfer { key transfer.example.com; }; };
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, []*TSIGKey{
+		{Name: "transfer.example.com", Algorithm: "hmac-sha256", Secret: tsigTestSecret},
+		{Name: "legacy", Algorithm: "hmac-md5", Secret: tsigTestSecret},
+	}, keys)
+
+	_, err = ParseBINDTSIGKeys(`key "k" { algorithm hmac-sha256; secret "` + tsigTestSecret + `";`)
+	assert.Error(t, err)
+	keys, err = ParseBINDTSIGKeys(`key "k" { algorithm hmac-sha256; secret "c2hvcnQ="; };`)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
+
+func TestTSIGKey_Save(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Put("/config-dns/v2/zones/example.com/key").
+		JSON(map[string]interface{}{"name": "transfer", "algorithm": "hmac-sha256", "secret": tsigTestSecret}).
+		Reply(204)
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones/example.com/key").
+		Reply(200).
+		JSON(map[string]interface{}{"name": "transfer", "algorithm": "hmac-sha256", "secret": tsigTestSecret, "zonesCount": 3})
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones/missing.com/key").
+		Reply(404)
+
+	Init(config)
+
+	assert.Error(t, NewTSIGKey("transfer", "hmac-sha256", "").Save("example.com"))
+	assert.NoError(t, NewTSIGKey("transfer", "hmac-sha256", tsigTestSecret).Save("example.com"))
+
+	key, err := GetZoneTSIGKey("example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, key.ZoneCount)
+	assert.Equal(t, "transfer", key.Name)
+
+	_, err = GetZoneTSIGKey("missing.com")
+	assert.True(t, err.(*ZoneError).NotFound())
+	assert.True(t, gock.IsDone())
+}
+
+func TestUpdateTSIGKey(t *testing.T) {
+	defer gock.Off()
+
+	key := NewTSIGKey("transfer", "hmac-sha256", tsigTestSecret)
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/keys").
+		MatchParam("contractIds", "C-1,C-2").
+		MatchParam("search", "trans").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"metadata": map[string]interface{}{"totalElements": 1},
+			"keys":     []map[string]interface{}{{"name": "transfer", "algorithm": "hmac-sha256", "secret": tsigTestSecret, "zonesCount": 2}},
+		})
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/keys/used-by").
+		JSON(key).
+		Reply(200).
+		JSON(map[string]interface{}{"zones": []string{"a.com", "b.com"}})
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/keys/bulk-update").
+		JSON(map[string]interface{}{"key": key, "zones": []string{"a.com", "b.com"}}).
+		Reply(204)
+
+	Init(config)
+
+	list, err := ListTSIGKeys(TSIGKeyQueryString{ContractIds: []string{"C-1", "C-2"}, Search: "trans"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, list.Metadata.TotalElements)
+	assert.Equal(t, 2, list.Keys[0].ZoneCount)
+
+	zones, err := GetTSIGKeyZones(key)
+	assert.NoError(t, err)
+	assert.NoError(t, UpdateTSIGKey(key, zones))
+	assert.True(t, gock.IsDone())
+}
+
+func TestGetZoneTransferStatus(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Post("/config-dns/v2/zones/zone-transfer-status").
+		JSON(map[string]interface{}{"zones": []string{"a.com", "b.com"}}).
+		Reply(200).
+		JSON(map[string]interface{}{"zones": []map[string]interface{}{
+			{"zone": "a.com", "masterIps": []string{"10.0.0.1"}, "lastAttempt": "2020-01-02T10:00:00Z", "lastSuccess": "2020-01-02T10:00:00Z", "lastSerial": 2020010201},
+			{"zone": "b.com", "masterIps": []string{"10.0.0.1"}, "lastAttempt": "2020-01-02T10:00:00Z", "lastSuccess": "2020-01-01T10:00:00Z", "lastTransferError": "TSIG verification failure"},
+		}})
+
+	Init(config)
+
+	statuses, err := GetZoneTransferStatus("a.com", "b.com")
+	if !assert.NoError(t, err) || !assert.Len(t, statuses, 2) {
+		return
+	}
+
+	assert.False(t, statuses[0].Failing())
+	assert.Equal(t, uint32(2020010201), statuses[0].LastSerial)
+	last, err := statuses[0].LastTransfer()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC), last)
+
+	assert.True(t, statuses[1].Failing())
+	assert.Equal(t, "TSIG verification failure", statuses[1].LastTransferError)
+}
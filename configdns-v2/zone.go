@@ -4,6 +4,7 @@ import (
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
 	"io/ioutil"
 	"log"
+	"strings"
 	"sync"
 )
 
@@ -42,25 +43,29 @@ type ZoneQueryString struct {
 }
 
 type ZoneCreate struct {
-	Zone         string   `json:"zone,omitempty"`
-	Type         string   `json:"type,omitempty"`
-	Masters      []string `json:"masters,omitempty"`
-	Comment      string   `json:"comment,omitempty"`
-	SignAndServe bool     `json:"signAndServe"`
+	Zone                  string   `json:"zone,omitempty"`
+	Type                  string   `json:"type,omitempty"`
+	Masters               []string `json:"masters,omitempty"`
+	Comment               string   `json:"comment,omitempty"`
+	SignAndServe          bool     `json:"signAndServe"`
+	SignAndServeAlgorithm string   `json:"signAndServeAlgorithm,omitempty"`
+	TsigKey               *TSIGKey `json:"tsigKey,omitempty"`
 }
 
 type ZoneResponse struct {
-	Zone               string   `json:"zone,omitempty"`
-	Type               string   `json:"type,omitempty"`
-	Masters            []string `json:"masters,omitempty"`
-	Comment            string   `json:"comment,omitempty"`
-	ActivationState    string   `json:"activationstate,omitempty"`
-	ContractId         string   `json:"contractid,omitempty"`
-	LastActivationDate string   `json:"lastactivationdate,omitempty"`
-	LastModifiedBy     string   `json:"lastmodifiedby,omitempty"`
-	LastModifiedDate   string   `json:"lastmodifieddate,omitempty"`
-	SignAndServe       bool     `json:"signandserve"`
-	VersionId          string   `json:"versionid,omitempty"`
+	Zone                  string   `json:"zone,omitempty"`
+	Type                  string   `json:"type,omitempty"`
+	Masters               []string `json:"masters,omitempty"`
+	Comment               string   `json:"comment,omitempty"`
+	ActivationState       string   `json:"activationstate,omitempty"`
+	ContractId            string   `json:"contractid,omitempty"`
+	LastActivationDate    string   `json:"lastactivationdate,omitempty"`
+	LastModifiedBy        string   `json:"lastmodifiedby,omitempty"`
+	LastModifiedDate      string   `json:"lastmodifieddate,omitempty"`
+	SignAndServe          bool     `json:"signandserve"`
+	SignAndServeAlgorithm string   `json:"signandservealgorithm,omitempty"`
+	TsigKey               *TSIGKey `json:"tsigkey,omitempty"`
+	VersionId             string   `json:"versionid,omitempty"`
 }
 
 type ChangeListResponse struct {
@@ -73,7 +78,7 @@ type ChangeListResponse struct {
 
 // NewZone creates a new Zone
 func NewZone(params ZoneCreate) *ZoneCreate {
-	zone := &ZoneCreate{Zone: params.Zone, Type: params.Type, Masters: params.Masters, Comment: params.Comment, SignAndServe: params.SignAndServe}
+	zone := &ZoneCreate{Zone: params.Zone, Type: params.Type, Masters: params.Masters, Comment: params.Comment, SignAndServe: params.SignAndServe, SignAndServeAlgorithm: params.SignAndServeAlgorithm, TsigKey: params.TsigKey}
 	return zone
 }
 
@@ -191,6 +196,46 @@ func GetMasterZoneFile(zone string) (string, error) {
 	}
 }
 
+// PostMasterZoneFile replaces the records of a zone with those of a master
+// zone file
+//
+// See: WriteZoneFile()
+func PostMasterZoneFile(zone string, masterZone string) error {
+	req, err := client.NewRequest(
+		Config,
+		"POST",
+		"/config-dns/v2/zones/"+zone+"/zone-file",
+		strings.NewReader(masterZone),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/dns")
+
+	res, err := client.Do(Config, req)
+
+	// Network error
+	if err != nil {
+		return &ZoneError{
+			zoneName:         zone,
+			httpErrorMessage: err.Error(),
+			err:              err,
+		}
+	}
+
+	if res.StatusCode == 404 {
+		return &ZoneError{zoneName: zone}
+	}
+
+	// API error
+	if client.IsError(res) {
+		err := client.NewAPIError(res)
+		return &ZoneError{zoneName: zone, apiErrorMessage: err.Detail, err: err}
+	}
+
+	return nil
+}
+
 // Save updates the Zone
 func (zone *ZoneCreate) Save(zonequerystring ZoneQueryString) error {
 	// This lock will restrict the concurrency of API calls
@@ -0,0 +1,203 @@
+package dnsv2
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ZoneListQueryArgs filters and pages the zones returned by ListZones
+//
+// Types takes PRIMARY, SECONDARY and ALIAS. SortBy takes zone field names,
+// prefixed with "-" for descending order, such as "zone" or
+// "-lastModifiedDate". Page starts at 1; zero values use the API defaults.
+type ZoneListQueryArgs struct {
+	ContractIds []string
+	Types       []string
+	Search      string
+	ShowAll     bool
+	SortBy      []string
+	Page        int
+	PageSize    int
+}
+
+// ZoneListMetadata describes a page of zones
+type ZoneListMetadata struct {
+	ContractIds   []string `json:"contractIds,omitempty"`
+	Page          int      `json:"page"`
+	PageSize      int      `json:"pageSize"`
+	ShowAll       bool     `json:"showAll"`
+	TotalElements int      `json:"totalElements"`
+}
+
+// ZoneListResponse is a page of zones
+type ZoneListResponse struct {
+	Metadata *ZoneListMetadata `json:"metadata"`
+	Zones    []*ZoneResponse   `json:"zones"`
+}
+
+// LastPage reports whether no zone comes after this page
+func (list *ZoneListResponse) LastPage() bool {
+	if list.Metadata == nil || list.Metadata.ShowAll || list.Metadata.PageSize == 0 {
+		return true
+	}
+
+	return list.Metadata.Page*list.Metadata.PageSize >= list.Metadata.TotalElements
+}
+
+// ListZones retrieves a page of the zones matching the query
+//
+// Endpoint: GET /config-dns/v2/zones{?contractIds,types,search,showAll,sortBy,page,pageSize}
+func ListZones(args ZoneListQueryArgs) (*ZoneListResponse, error) {
+	params := url.Values{}
+	if len(args.ContractIds) > 0 {
+		params.Set("contractIds", strings.Join(args.ContractIds, ","))
+	}
+	if len(args.Types) > 0 {
+		params.Set("types", strings.ToUpper(strings.Join(args.Types, ",")))
+	}
+	if args.Search != "" {
+		params.Set("search", args.Search)
+	}
+	if args.ShowAll {
+		params.Set("showAll", "true")
+	}
+	if len(args.SortBy) > 0 {
+		params.Set("sortBy", strings.Join(args.SortBy, ","))
+	}
+	if args.Page > 0 {
+		params.Set("page", strconv.Itoa(args.Page))
+	}
+	if args.PageSize > 0 {
+		params.Set("pageSize", strconv.Itoa(args.PageSize))
+	}
+
+	path := "/config-dns/v2/zones"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	list := &ZoneListResponse{}
+	if err := apiRequest("GET", path, nil, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ListAllZones retrieves the zones matching the query page after page,
+// starting at args.Page, calling onPage, when set, with each page
+//
+// Returning false from onPage stops the listing.
+func ListAllZones(args ZoneListQueryArgs, onPage func(page *ZoneListResponse) bool) ([]*ZoneResponse, error) {
+	if args.Page == 0 {
+		args.Page = 1
+	}
+
+	var zones []*ZoneResponse
+	for {
+		list, err := ListZones(args)
+		if err != nil {
+			return zones, err
+		}
+		zones = append(zones, list.Zones...)
+
+		if onPage != nil && !onPage(list) || list.LastPage() || len(list.Zones) == 0 {
+			return zones, nil
+		}
+		args.Page++
+	}
+}
+
+// ZoneSummary is an inventory view of a zone
+//
+// Record counts are only set for zones with recordsets, which alias zones
+// do not have.
+type ZoneSummary struct {
+	Zone               string
+	Type               string
+	ContractId         string
+	ActivationState    string
+	LastActivationDate string
+	LastModifiedDate   string
+	SignAndServe       bool
+	RecordsetCount     int
+	RecordCount        int
+	RecordsetsByType   map[string]int
+}
+
+// NewZoneSummary summarizes a zone and its recordsets
+func NewZoneSummary(zone *ZoneResponse, recordsets []Recordset) *ZoneSummary {
+	summary := &ZoneSummary{
+		Zone:               zone.Zone,
+		Type:               strings.ToUpper(zone.Type),
+		ContractId:         zone.ContractId,
+		ActivationState:    zone.ActivationState,
+		LastActivationDate: zone.LastActivationDate,
+		LastModifiedDate:   zone.LastModifiedDate,
+		SignAndServe:       zone.SignAndServe,
+		RecordsetCount:     len(recordsets),
+		RecordsetsByType:   map[string]int{},
+	}
+	for _, recordset := range recordsets {
+		summary.RecordCount += len(recordset.Rdata)
+		summary.RecordsetsByType[strings.ToUpper(recordset.Type)]++
+	}
+
+	return summary
+}
+
+// Types returns the record types of the zone in order
+func (summary *ZoneSummary) Types() []string {
+	types := make([]string, 0, len(summary.RecordsetsByType))
+	for recordType := range summary.RecordsetsByType {
+		types = append(types, recordType)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// GetZoneSummary retrieves a zone and its recordsets and summarizes them
+func GetZoneSummary(zonename string) (*ZoneSummary, error) {
+	zone, err := GetZone(zonename)
+	if err != nil {
+		return nil, err
+	}
+
+	return summarizeZone(zone)
+}
+
+// SummarizeZones summarizes listed zones, retrieving their recordsets
+//
+// A zone whose recordsets cannot be retrieved stops the summary, returning
+// the summaries of the zones before it.
+//
+//	zones, err := dnsv2.ListAllZones(dnsv2.ZoneListQueryArgs{ContractIds: []string{"C-1"}, PageSize: 100}, nil)
+//	summaries, err := dnsv2.SummarizeZones(zones)
+func SummarizeZones(zones []*ZoneResponse) ([]*ZoneSummary, error) {
+	summaries := make([]*ZoneSummary, 0, len(zones))
+	for _, zone := range zones {
+		summary, err := summarizeZone(zone)
+		if err != nil {
+			return summaries, err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+func summarizeZone(zone *ZoneResponse) (*ZoneSummary, error) {
+	if strings.EqualFold(zone.Type, "ALIAS") {
+		return NewZoneSummary(zone, nil), nil
+	}
+
+	recordsets, err := GetRecordsets(zone.Zone)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewZoneSummary(zone, recordsets), nil
+}
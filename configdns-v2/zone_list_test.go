@@ -0,0 +1,118 @@
+package dnsv2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestListAllZones(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones$").
+		MatchParam("contractIds", "C-1").
+		MatchParam("types", "PRIMARY,ALIAS").
+		MatchParam("search", "example").
+		MatchParam("sortBy", "-zone").
+		MatchParam("page", "1").
+		MatchParam("pageSize", "2").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"metadata": map[string]interface{}{"page": 1, "pageSize": 2, "totalElements": 3},
+			"zones": []map[string]interface{}{
+				{"zone": "c.example", "type": "PRIMARY"},
+				{"zone": "b.example", "type": "ALIAS"},
+			},
+		})
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones$").
+		MatchParam("page", "2").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"metadata": map[string]interface{}{"page": 2, "pageSize": 2, "totalElements": 3},
+			"zones":    []map[string]interface{}{{"zone": "a.example", "type": "PRIMARY"}},
+		})
+
+	Init(config)
+
+	pages := 0
+	zones, err := ListAllZones(ZoneListQueryArgs{
+		ContractIds: []string{"C-1"},
+		Types:       []string{"primary", "alias"},
+		Search:      "example",
+		SortBy:      []string{"-zone"},
+		PageSize:    2,
+	}, func(*ZoneListResponse) bool {
+		pages++
+		return true
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pages)
+	if assert.Len(t, zones, 3) {
+		assert.Equal(t, "a.example", zones[2].Zone)
+	}
+	assert.True(t, gock.IsDone())
+}
+
+func TestListZones_ShowAll(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones$").
+		MatchParam("showAll", "true").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"metadata": map[string]interface{}{"showAll": true, "totalElements": 1},
+			"zones":    []map[string]interface{}{{"zone": "a.example", "type": "SECONDARY"}},
+		})
+
+	Init(config)
+
+	list, err := ListZones(ZoneListQueryArgs{ShowAll: true})
+	if assert.NoError(t, err) {
+		assert.True(t, list.LastPage())
+		assert.Equal(t, "SECONDARY", list.Zones[0].Type)
+	}
+}
+
+func TestSummarizeZones(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(changelistTestURL).
+		Get("/config-dns/v2/zones/a.example/recordsets").
+		MatchParam("showAll", "true").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"metadata": map[string]interface{}{"showAll": true, "totalElements": 3},
+			"recordsets": []Recordset{
+				{Name: "a.example", Type: "SOA", TTL: 3600, Rdata: []string{"a1.akam.net. hostmaster.a.example. 1 3600 600 604800 300"}},
+				{Name: "a.example", Type: "NS", TTL: 3600, Rdata: []string{"a1.akam.net.", "a2.akam.net."}},
+				{Name: "www.a.example", Type: "a", TTL: 300, Rdata: []string{"10.0.0.1"}},
+			},
+		})
+
+	Init(config)
+
+	summaries, err := SummarizeZones([]*ZoneResponse{
+		{Zone: "a.example", Type: "PRIMARY", ActivationState: "ACTIVE", SignAndServe: true},
+		{Zone: "b.example", Type: "ALIAS", ActivationState: "ACTIVE"},
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, summaries, 2) {
+		return
+	}
+
+	assert.Equal(t, &ZoneSummary{
+		Zone:             "a.example",
+		Type:             "PRIMARY",
+		ActivationState:  "ACTIVE",
+		SignAndServe:     true,
+		RecordsetCount:   3,
+		RecordCount:      4,
+		RecordsetsByType: map[string]int{"SOA": 1, "NS": 1, "A": 1},
+	}, summaries[0])
+	assert.Equal(t, []string{"A", "NS", "SOA"}, summaries[0].Types())
+	assert.Equal(t, 0, summaries[1].RecordsetCount)
+	assert.True(t, gock.IsDone())
+}
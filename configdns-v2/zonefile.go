@@ -0,0 +1,652 @@
+package dnsv2
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ZoneFileError is returned when a zone file can not be parsed
+type ZoneFileError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *ZoneFileError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Err)
+	}
+
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// ZoneFileParser parses RFC 1035 master zone files
+//
+// $ORIGIN, $TTL and $INCLUDE directives, relative names, parentheses
+// spanning lines and escapes are supported. $GENERATE and classes other
+// than IN are not.
+type ZoneFileParser struct {
+	// Origin is the initial origin, usually the zone name
+	Origin string
+	// TTL is used for records without TTL before any $TTL directive; when
+	// zero, such records take the TTL of the previous record
+	TTL int
+	// Dir is the directory against which $INCLUDE file names in the parsed
+	// file are resolved; when empty, the working directory is used. File
+	// names in included files are resolved against the including file's
+	// directory, and absolute names are used as they are.
+	Dir string
+	// Open opens files named by $INCLUDE, given their resolved path; when
+	// nil, os.Open is used
+	Open func(path string) (io.ReadCloser, error)
+	// MaxIncludeDepth limits nested $INCLUDE directives
+	MaxIncludeDepth int
+}
+
+// NewZoneFileParser creates a new ZoneFileParser for zone
+func NewZoneFileParser(zone string) *ZoneFileParser {
+	return &ZoneFileParser{Origin: zone, MaxIncludeDepth: 8}
+}
+
+// ParseZoneFile parses a master zone file for zone
+//
+// See: ZoneFileParser.Parse()
+func ParseZoneFile(zone string, r io.Reader) ([]RecordBody, error) {
+	return NewZoneFileParser(zone).Parse(r)
+}
+
+// zoneFileState is the state of a ZoneFileParser across included files
+type zoneFileState struct {
+	origin     string
+	defaultTTL int
+	lastTTL    int
+	owner      string
+	records    []RecordBody
+	index      map[string]int
+}
+
+// Parse parses a master zone file, returning one RecordBody per record set
+//
+// Owner names are returned without the trailing dot, as used by the API.
+// Domain names in rdata are made absolute, and TTLs given with units, such
+// as 1h30m, are converted to seconds. Records of a set with different TTLs
+// get the lowest one.
+func (parser *ZoneFileParser) Parse(r io.Reader) ([]RecordBody, error) {
+	state := &zoneFileState{defaultTTL: parser.TTL, index: map[string]int{}}
+	if parser.Origin != "" {
+		origin, err := absoluteName(parser.Origin, ".")
+		if err != nil {
+			return nil, err
+		}
+		state.origin = origin
+	}
+
+	if err := parser.parse(state, r, "", 0); err != nil {
+		return nil, err
+	}
+
+	return state.records, nil
+}
+
+func (parser *ZoneFileParser) parse(state *zoneFileState, r io.Reader, file string, depth int) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	lines, scanErr := scanZoneFile(data)
+	if scanErr != nil {
+		scanErr.File = file
+		return scanErr
+	}
+
+	for _, line := range lines {
+		if err := parser.parseLine(state, line, file, depth); err != nil {
+			if _, ok := err.(*ZoneFileError); ok {
+				return err
+			}
+			return &ZoneFileError{File: file, Line: line.number, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func (parser *ZoneFileParser) parseLine(state *zoneFileState, line *zoneFileLine, file string, depth int) error {
+	tokens := line.tokens
+	if !line.blankOwner && !tokens[0].quoted && strings.HasPrefix(tokens[0].text, "$") {
+		return parser.parseDirective(state, tokens, file, depth)
+	}
+
+	owner := state.owner
+	if !line.blankOwner {
+		name, err := absoluteName(tokens[0].text, state.origin)
+		if err != nil {
+			return err
+		}
+		owner = name
+		tokens = tokens[1:]
+	} else if owner == "" {
+		return fmt.Errorf("record without owner name")
+	}
+
+	ttl := -1
+	// TTL and class may come in either order
+	for i := 0; i < 2 && len(tokens) > 0; i++ {
+		value, err := parseZoneFileTTL(tokens[0].text)
+		if err == nil && ttl < 0 {
+			ttl = value
+			tokens = tokens[1:]
+			continue
+		}
+
+		class := strings.ToUpper(tokens[0].text)
+		if class == "CH" || class == "HS" || class == "CS" || class == "NONE" || class == "ANY" {
+			return fmt.Errorf("unsupported class %s", class)
+		}
+		if class != "IN" {
+			break
+		}
+		tokens = tokens[1:]
+	}
+
+	if len(tokens) == 0 {
+		return fmt.Errorf("missing record type")
+	}
+	recordType := strings.ToUpper(tokens[0].text)
+	if tokens[0].quoted || !validRecordType(recordType) {
+		return fmt.Errorf("invalid record type %q", tokens[0].text)
+	}
+
+	rdata, err := formatZoneFileRdata(recordType, tokens[1:], state.origin)
+	if err != nil {
+		return fmt.Errorf("%s record %s: %s", recordType, owner, err)
+	}
+
+	switch {
+	case ttl >= 0:
+		state.lastTTL = ttl
+	case state.defaultTTL > 0:
+		ttl = state.defaultTTL
+	case state.lastTTL > 0:
+		ttl = state.lastTTL
+	default:
+		return fmt.Errorf("%s record %s has no TTL and no $TTL is set", recordType, owner)
+	}
+
+	state.owner = owner
+	state.add(strings.TrimSuffix(owner, "."), recordType, ttl, rdata)
+
+	return nil
+}
+
+func (parser *ZoneFileParser) parseDirective(state *zoneFileState, tokens []zoneFileToken, file string, depth int) error {
+	directive := strings.ToUpper(tokens[0].text)
+	args := tokens[1:]
+
+	switch directive {
+	case "$ORIGIN":
+		if len(args) != 1 {
+			return fmt.Errorf("$ORIGIN takes one domain name")
+		}
+		origin, err := absoluteName(args[0].text, state.origin)
+		if err != nil {
+			return err
+		}
+		state.origin = origin
+	case "$TTL":
+		if len(args) != 1 {
+			return fmt.Errorf("$TTL takes one TTL")
+		}
+		ttl, err := parseZoneFileTTL(args[0].text)
+		if err != nil {
+			return err
+		}
+		state.defaultTTL = ttl
+	case "$INCLUDE":
+		if len(args) < 1 || len(args) > 2 {
+			return fmt.Errorf("$INCLUDE takes a file name and an optional origin")
+		}
+		if depth >= parser.MaxIncludeDepth {
+			return fmt.Errorf("$INCLUDE nested more than %d levels", parser.MaxIncludeDepth)
+		}
+
+		origin := state.origin
+		if len(args) == 2 {
+			included, err := absoluteName(args[1].text, state.origin)
+			if err != nil {
+				return err
+			}
+			state.origin = included
+		}
+
+		open := parser.Open
+		if open == nil {
+			open = func(path string) (io.ReadCloser, error) { return os.Open(path) }
+		}
+		path := parser.includePath(args[0].text, file)
+		included, err := open(path)
+		if err != nil {
+			return err
+		}
+		defer included.Close()
+
+		if err := parser.parse(state, included, path, depth+1); err != nil {
+			return err
+		}
+		state.origin = origin
+	default:
+		return fmt.Errorf("unsupported directive %s", tokens[0].text)
+	}
+
+	return nil
+}
+
+// includePath resolves the $INCLUDE file name against the directory of file,
+// the including file, or against Dir in the parsed file
+func (parser *ZoneFileParser) includePath(name string, file string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	if file != "" {
+		return filepath.Join(filepath.Dir(file), name)
+	}
+	return filepath.Join(parser.Dir, name)
+}
+
+// add appends rdata to the record set of name and recordType
+func (state *zoneFileState) add(name string, recordType string, ttl int, rdata string) {
+	key := name + " " + recordType
+	if i, ok := state.index[key]; ok {
+		record := &state.records[i]
+		if ttl < record.TTL {
+			record.TTL = ttl
+		}
+		for _, target := range record.Target {
+			if target == rdata {
+				return
+			}
+		}
+		record.Target = append(record.Target, rdata)
+		return
+	}
+
+	state.index[key] = len(state.records)
+	state.records = append(state.records, RecordBody{
+		Name:       name,
+		RecordType: recordType,
+		TTL:        ttl,
+		Active:     true,
+		Target:     []string{rdata},
+	})
+}
+
+// zoneFileToken is a word or quoted string of a zone file, escapes included
+type zoneFileToken struct {
+	text   string
+	quoted bool
+}
+
+// zoneFileLine is a logical line of a zone file, parentheses joining lines
+type zoneFileLine struct {
+	number     int
+	blankOwner bool
+	tokens     []zoneFileToken
+}
+
+// scanZoneFile splits a zone file into logical lines, dropping comments
+func scanZoneFile(data []byte) ([]*zoneFileLine, *ZoneFileError) {
+	var lines []*zoneFileLine
+	number, depth := 1, 0
+	line := &zoneFileLine{number: number}
+	lineStart := true
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '\n':
+			number++
+			if depth == 0 {
+				if len(line.tokens) > 0 {
+					lines = append(lines, line)
+				}
+				line = &zoneFileLine{number: number}
+				lineStart = true
+			}
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			if lineStart && depth == 0 && len(line.tokens) == 0 {
+				line.blankOwner = true
+			}
+		case c == ';':
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return nil, &ZoneFileError{Line: number, Err: fmt.Errorf("unbalanced parentheses")}
+			}
+		case c == '"':
+			start := i + 1
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				} else if data[i] == '\n' {
+					i = len(data)
+				}
+			}
+			if i >= len(data) {
+				return nil, &ZoneFileError{Line: number, Err: fmt.Errorf("unterminated string")}
+			}
+			line.tokens = append(line.tokens, zoneFileToken{text: string(data[start:i]), quoted: true})
+		default:
+			start := i
+			for ; i < len(data) && !strings.ContainsRune(" \t\r\n;()\"", rune(data[i])); i++ {
+				if data[i] == '\\' && i+1 < len(data) {
+					i++
+				}
+			}
+			line.tokens = append(line.tokens, zoneFileToken{text: string(data[start:i])})
+			i--
+		}
+		lineStart = false
+	}
+
+	if depth > 0 {
+		return nil, &ZoneFileError{Line: number, Err: fmt.Errorf("unbalanced parentheses")}
+	}
+	if len(line.tokens) > 0 {
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// absoluteName returns name as an absolute, lower case, domain name
+func absoluteName(name string, origin string) (string, error) {
+	if name == "@" {
+		if origin == "" {
+			return "", fmt.Errorf("@ used without origin")
+		}
+		return origin, nil
+	}
+
+	if name == "." || isAbsoluteName(name) {
+		return strings.ToLower(name), nil
+	}
+
+	if origin == "" {
+		return "", fmt.Errorf("relative name %s used without origin", name)
+	}
+	if origin == "." {
+		return strings.ToLower(name) + ".", nil
+	}
+
+	return strings.ToLower(name + "." + origin), nil
+}
+
+// isAbsoluteName reports whether name ends with an unescaped dot
+func isAbsoluteName(name string) bool {
+	if !strings.HasSuffix(name, ".") {
+		return false
+	}
+
+	escapes := 0
+	for i := len(name) - 2; i >= 0 && name[i] == '\\'; i-- {
+		escapes++
+	}
+
+	return escapes%2 == 0
+}
+
+// validRecordType reports whether recordType is a mnemonic or TYPEnnn
+func validRecordType(recordType string) bool {
+	if recordType == "" || recordType[0] < 'A' || recordType[0] > 'Z' {
+		return false
+	}
+
+	for _, c := range recordType {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseZoneFileTTL parses a TTL in seconds, or with BIND units such as 1h30m
+func parseZoneFileTTL(value string) (int, error) {
+	if seconds, err := strconv.ParseUint(value, 10, 31); err == nil {
+		return int(seconds), nil
+	}
+
+	units := map[byte]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+	total, number, digits := 0, 0, 0
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= '0' && c <= '9' {
+			number = number*10 + int(c-'0')
+			digits++
+			continue
+		}
+
+		unit, ok := units[c|0x20]
+		if !ok || digits == 0 {
+			return 0, fmt.Errorf("invalid TTL %q", value)
+		}
+		total += number * unit
+		number, digits = 0, 0
+	}
+
+	if digits > 0 || total == 0 && value != "0" {
+		return 0, fmt.Errorf("invalid TTL %q", value)
+	}
+
+	return total, nil
+}
+
+// zoneFileRdataFormat describes the rdata fields of a record type
+//
+// names lists the fields holding domain names. Fields from join onwards,
+// base64 or hex data that may be split by spaces, are concatenated.
+type zoneFileRdataFormat struct {
+	min, max int
+	names    []int
+	join     int
+}
+
+var zoneFileRdataFormats = map[string]zoneFileRdataFormat{
+	"A":          {min: 1, max: 1},
+	"AAAA":       {min: 1, max: 1},
+	"AFSDB":      {min: 2, max: 2, names: []int{1}},
+	"CAA":        {min: 3, max: 3},
+	"CNAME":      {min: 1, max: 1, names: []int{0}},
+	"DNAME":      {min: 1, max: 1, names: []int{0}},
+	"DNSKEY":     {min: 4, join: 3},
+	"DS":         {min: 4, join: 3},
+	"HINFO":      {min: 2, max: 2},
+	"LOC":        {min: 4},
+	"MX":         {min: 2, max: 2, names: []int{1}},
+	"NAPTR":      {min: 6, max: 6, names: []int{5}},
+	"NS":         {min: 1, max: 1, names: []int{0}},
+	"NSEC3PARAM": {min: 4, max: 4},
+	"PTR":        {min: 1, max: 1, names: []int{0}},
+	"RP":         {min: 2, max: 2, names: []int{0, 1}},
+	"RRSIG":      {min: 9, names: []int{7}, join: 8},
+	"SOA":        {min: 7, max: 7, names: []int{0, 1}},
+	"SRV":        {min: 4, max: 4, names: []int{3}},
+	"SSHFP":      {min: 3, join: 2},
+	"TLSA":       {min: 4, join: 3},
+}
+
+// formatZoneFileRdata returns the canonical presentation of rdata
+func formatZoneFileRdata(recordType string, tokens []zoneFileToken, origin string) (string, error) {
+	if len(tokens) == 0 {
+		return "", fmt.Errorf("missing rdata")
+	}
+
+	if recordType == "TXT" || recordType == "SPF" {
+		strs := make([]string, len(tokens))
+		for i, token := range tokens {
+			strs[i] = `"` + token.text + `"`
+		}
+		return strings.Join(strs, " "), nil
+	}
+
+	format, known := zoneFileRdataFormats[recordType]
+	if known && (len(tokens) < format.min || format.max > 0 && len(tokens) > format.max) {
+		return "", fmt.Errorf("unexpected number of rdata fields: %d", len(tokens))
+	}
+
+	fields := make([]string, len(tokens))
+	for i, token := range tokens {
+		fields[i] = token.text
+		if token.quoted {
+			fields[i] = `"` + token.text + `"`
+		}
+	}
+
+	for _, i := range format.names {
+		name, err := absoluteName(fields[i], origin)
+		if err != nil {
+			return "", err
+		}
+		fields[i] = name
+	}
+
+	switch recordType {
+	case "A":
+		if ip := net.ParseIP(fields[0]); ip == nil || ip.To4() == nil {
+			return "", fmt.Errorf("invalid IPv4 address %s", fields[0])
+		}
+	case "AAAA":
+		if ip := net.ParseIP(fields[0]); ip == nil || !strings.Contains(fields[0], ":") {
+			return "", fmt.Errorf("invalid IPv6 address %s", fields[0])
+		}
+	case "SOA":
+		if _, err := strconv.ParseUint(fields[2], 10, 32); err != nil {
+			return "", fmt.Errorf("invalid serial %s", fields[2])
+		}
+		for i := 3; i < 7; i++ {
+			seconds, err := parseZoneFileTTL(fields[i])
+			if err != nil {
+				return "", err
+			}
+			fields[i] = strconv.Itoa(seconds)
+		}
+	}
+
+	if format.join > 0 && len(fields) > format.join {
+		fields = append(fields[:format.join], strings.Join(fields[format.join:], ""))
+	}
+
+	return strings.Join(fields, " "), nil
+}
+
+// WriteZoneFile writes recordsets as a master zone file for zone
+//
+// The output is canonical: the SOA and apex NS records come first, then
+// names in DNS order, types alphabetically. Names are written relative to
+// the zone. Unquoted TXT and SPF rdata is written as character strings of at
+// most 255 bytes, escaped as in zone files.
+func WriteZoneFile(w io.Writer, zone string, recordsets []Recordset) error {
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+
+	sorted := make([]Recordset, len(recordsets))
+	copy(sorted, recordsets)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return zoneFileLess(zone, sorted[i], sorted[j])
+	})
+
+	if _, err := fmt.Fprintf(w, "$ORIGIN %s.\n", zone); err != nil {
+		return err
+	}
+
+	for _, recordset := range sorted {
+		owner := relativeName(recordset.Name, zone)
+		for _, rdata := range recordset.Rdata {
+			if (recordset.Type == "TXT" || recordset.Type == "SPF") && !strings.HasPrefix(rdata, `"`) {
+				rdata = (&TXTRdata{Strings: splitText(rdata)}).String()
+			}
+			if _, err := fmt.Fprintf(w, "%s\t%d\tIN\t%s\t%s\n", owner, recordset.TTL, recordset.Type, rdata); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// FormatZoneFile returns recordsets as a master zone file for zone
+//
+// See: WriteZoneFile()
+func FormatZoneFile(zone string, recordsets []Recordset) string {
+	var buf bytes.Buffer
+	WriteZoneFile(&buf, zone, recordsets)
+
+	return buf.String()
+}
+
+// zoneFileLess orders recordsets canonically
+func zoneFileLess(zone string, a Recordset, b Recordset) bool {
+	rank := func(recordset Recordset) int {
+		apex := strings.ToLower(strings.TrimSuffix(recordset.Name, ".")) == zone
+		switch {
+		case apex && recordset.Type == "SOA":
+			return 0
+		case apex && recordset.Type == "NS":
+			return 1
+		}
+		return 2
+	}
+
+	if rank(a) != rank(b) {
+		return rank(a) < rank(b)
+	}
+
+	aLabels := reversedLabels(a.Name)
+	bLabels := reversedLabels(b.Name)
+	for i := 0; i < len(aLabels) && i < len(bLabels); i++ {
+		if aLabels[i] != bLabels[i] {
+			return aLabels[i] < bLabels[i]
+		}
+	}
+	if len(aLabels) != len(bLabels) {
+		return len(aLabels) < len(bLabels)
+	}
+
+	return a.Type < b.Type
+}
+
+func reversedLabels(name string) []string {
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(name, ".")), ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+
+	return labels
+}
+
+// relativeName returns name relative to zone, @ for the apex
+func relativeName(name string, zone string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	switch {
+	case name == zone:
+		return "@"
+	case strings.HasSuffix(name, "."+zone):
+		return strings.TrimSuffix(name, "."+zone)
+	}
+
+	return name + "."
+}
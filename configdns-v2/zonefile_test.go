@@ -0,0 +1,183 @@
+package dnsv2
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const zoneFileTestBody = `$ORIGIN example.com.
+$TTL 1h
+@	IN	SOA	a1.akam.net. hostmaster (
+		2019022301 ; serial
+		1h 10m 1w 300 )
+	86400	IN	NS	a1.akam.net.
+	86400	IN	NS	a2.akam.net.
+@		MX	10 mail
+www	300	IN	A	10.0.0.1
+www	600	IN	A	10.0.0.2
+www		AAAA	2001:db8::1
+txt		TXT	"v=spf1 include:_spf.example.com -all" "second; string"
+esc\.aped	CNAME	www
+$ORIGIN sub.example.com.
+host	IN 60	CNAME	target.example.net.
+$INCLUDE services.zone _tcp.example.com.
+after		A	10.0.0.3
+`
+
+func testZoneFileOpen(path string) (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader("_sip 120 SRV 10 60 5060 sip.example.com.\n")), nil
+}
+
+func TestZoneFileParser_Parse(t *testing.T) {
+	parser := NewZoneFileParser("example.com")
+	parser.Open = testZoneFileOpen
+
+	records, err := parser.Parse(strings.NewReader(zoneFileTestBody))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	expected := []RecordBody{
+		{Name: "example.com", RecordType: "SOA", TTL: 3600, Active: true, Target: []string{"a1.akam.net. hostmaster.example.com. 2019022301 3600 600 604800 300"}},
+		{Name: "example.com", RecordType: "NS", TTL: 86400, Active: true, Target: []string{"a1.akam.net.", "a2.akam.net."}},
+		{Name: "example.com", RecordType: "MX", TTL: 3600, Active: true, Target: []string{"10 mail.example.com."}},
+		{Name: "www.example.com", RecordType: "A", TTL: 300, Active: true, Target: []string{"10.0.0.1", "10.0.0.2"}},
+		{Name: "www.example.com", RecordType: "AAAA", TTL: 3600, Active: true, Target: []string{"2001:db8::1"}},
+		{Name: "txt.example.com", RecordType: "TXT", TTL: 3600, Active: true, Target: []string{`"v=spf1 include:_spf.example.com -all" "second; string"`}},
+		{Name: `esc\.aped.example.com`, RecordType: "CNAME", TTL: 3600, Active: true, Target: []string{"www.example.com."}},
+		{Name: "host.sub.example.com", RecordType: "CNAME", TTL: 60, Active: true, Target: []string{"target.example.net."}},
+		{Name: "_sip._tcp.example.com", RecordType: "SRV", TTL: 120, Active: true, Target: []string{"10 60 5060 sip.example.com."}},
+		{Name: "after.sub.example.com", RecordType: "A", TTL: 3600, Active: true, Target: []string{"10.0.0.3"}},
+	}
+	assert.Equal(t, expected, records)
+}
+
+func TestZoneFileParser_ParseInclude(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zonefile")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"hosts.zone":        "$INCLUDE sub/services.zone\n",
+		"sub/services.zone": "$INCLUDE more.zone\n",
+		"sub/more.zone":     "www 300 A 10.0.0.1\n",
+	}
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	for name, body := range files {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(body), 0644))
+	}
+
+	parser := NewZoneFileParser("example.com")
+	parser.Dir = dir
+
+	records, err := parser.Parse(strings.NewReader("$INCLUDE hosts.zone\n"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []RecordBody{{Name: "www.example.com", RecordType: "A", TTL: 300, Active: true, Target: []string{"10.0.0.1"}}}, records)
+	}
+}
+
+func TestZoneFileParser_ParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"unbalanced":    "@ 300 IN SOA a. b. ( 1 2 3 4 5\n",
+		"unterminated":  "@ 300 TXT \"abc\n",
+		"no ttl":        "www A 10.0.0.1\n",
+		"class":         "www 300 CH A 10.0.0.1\n",
+		"bad address":   "www 300 A 10.0.0\n",
+		"rdata count":   "www 300 MX mail\n",
+		"directive":     "$GENERATE 1-2 host$ A 10.0.0.$\n",
+		"no owner":      "\t300 A 10.0.0.1\n",
+		"missing rdata": "$TTL 300\nwww.example.com. A\n",
+	}
+
+	for name, body := range tests {
+		_, err := NewZoneFileParser("example.com").Parse(strings.NewReader(body))
+		if assert.Error(t, err, name) {
+			assert.IsType(t, &ZoneFileError{}, err, name)
+		}
+	}
+
+	_, err := NewZoneFileParser("example.com").Parse(strings.NewReader("$TTL 300\nwww A 10.0.0.1\nwww MX 10\n"))
+	assert.Equal(t, "line 3: MX record www.example.com.: unexpected number of rdata fields: 1", err.Error())
+}
+
+func TestWriteZoneFile(t *testing.T) {
+	recordsets := []Recordset{
+		{Name: "www.example.com", Type: "A", TTL: 300, Rdata: []string{"10.0.0.1", "10.0.0.2"}},
+		{Name: "example.com", Type: "NS", TTL: 86400, Rdata: []string{"a1.akam.net."}},
+		{Name: "a.www.example.com", Type: "TXT", TTL: 300, Rdata: []string{"hello world"}},
+		{Name: "example.com", Type: "SOA", TTL: 3600, Rdata: []string{"a1.akam.net. hostmaster.example.com. 1 3600 600 604800 300"}},
+		{Name: "example.com", Type: "MX", TTL: 3600, Rdata: []string{"10 mail.example.com."}},
+		{Name: "other.net", Type: "A", TTL: 300, Rdata: []string{"10.0.0.9"}},
+	}
+
+	expected := `$ORIGIN example.com.
+@	3600	IN	SOA	a1.akam.net. hostmaster.example.com. 1 3600 600 604800 300
+@	86400	IN	NS	a1.akam.net.
+@	3600	IN	MX	10 mail.example.com.
+www	300	IN	A	10.0.0.1
+www	300	IN	A	10.0.0.2
+a.www	300	IN	TXT	"hello world"
+other.net.	300	IN	A	10.0.0.9
+`
+	assert.Equal(t, expected, FormatZoneFile("example.com.", recordsets))
+
+	// Round trip
+	records, err := ParseZoneFile("example.com", strings.NewReader(expected))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var parsed []Recordset
+	for _, record := range records {
+		parsed = append(parsed, record.ToRecordset())
+	}
+	assert.Equal(t, expected, FormatZoneFile("example.com", parsed))
+}
+
+func TestWriteZoneFile_Text(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	recordsets := []Recordset{
+		{Name: "example.com", Type: "TXT", TTL: 300, Rdata: []string{"caf\u00e9\tbar \"quoted\"", long}},
+	}
+
+	zoneFile := FormatZoneFile("example.com", recordsets)
+	assert.Contains(t, zoneFile, `"caf\195\169\009bar \"quoted\""`)
+	assert.Contains(t, zoneFile, `"`+long[:255]+`" "`+long[255:]+`"`)
+
+	records, err := ParseZoneFile("example.com", strings.NewReader(zoneFile))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var texts []string
+	for _, record := range records {
+		for _, target := range record.Target {
+			rdata, err := ParseRdata("TXT", target)
+			if assert.NoError(t, err) {
+				texts = append(texts, strings.Join(rdata.(*TXTRdata).Strings, ""))
+			}
+		}
+	}
+	assert.Equal(t, recordsets[0].Rdata, texts)
+}
+
+func TestPostMasterZoneFile(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://akaa-baseurl-xxxxxxxxxxx-xxxxxxxxxxxxx.luna.akamaiapis.net").
+		Post("/config-dns/v2/zones/example.com/zone-file").
+		MatchHeader("Content-Type", "text/dns").
+		Reply(204)
+
+	Init(config)
+
+	assert.NoError(t, PostMasterZoneFile("example.com", "www 300 IN A 10.0.0.1\n"))
+	assert.True(t, gock.IsDone())
+}
@@ -0,0 +1,296 @@
+package papi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ActivationPeriod is a span of time during which a property version was
+// live on a network
+//
+// End is zero while the version is still live. EndedBy is the activation or
+// deactivation that replaced it.
+type ActivationPeriod struct {
+	Network         NetworkValue
+	PropertyVersion int
+	Activation      *Activation
+	Start           time.Time
+	End             time.Time
+	EndedBy         *Activation
+}
+
+// Live reports whether the period includes t
+func (period *ActivationPeriod) Live(t time.Time) bool {
+	return !t.Before(period.Start) && (period.End.IsZero() || t.Before(period.End))
+}
+
+// ActivationTimeline lists the versions that were live on a network, oldest first
+//
+// Periods never overlap. A deactivation ends a period without starting a
+// new one, leaving a gap during which no version was live.
+type ActivationTimeline struct {
+	Network NetworkValue
+	Periods []*ActivationPeriod
+}
+
+// Current returns the period of the version currently live, or nil
+func (timeline *ActivationTimeline) Current() *ActivationPeriod {
+	if len(timeline.Periods) == 0 {
+		return nil
+	}
+
+	last := timeline.Periods[len(timeline.Periods)-1]
+	if !last.End.IsZero() {
+		return nil
+	}
+
+	return last
+}
+
+// At returns the period of the version live at t, or nil
+func (timeline *ActivationTimeline) At(t time.Time) *ActivationPeriod {
+	for i := len(timeline.Periods) - 1; i >= 0; i-- {
+		if timeline.Periods[i].Live(t) {
+			return timeline.Periods[i]
+		}
+	}
+
+	return nil
+}
+
+// Previous returns the last period before the current one with a
+// different version, the rollback target
+func (timeline *ActivationTimeline) Previous() *ActivationPeriod {
+	current := timeline.Current()
+	if current == nil {
+		return nil
+	}
+
+	for i := len(timeline.Periods) - 2; i >= 0; i-- {
+		if timeline.Periods[i].PropertyVersion != current.PropertyVersion {
+			return timeline.Periods[i]
+		}
+	}
+
+	return nil
+}
+
+// ActivationHistory is the activation timeline of a property on each network
+//
+// Only activations that took effect are considered: pending, aborted and
+// failed ones are ignored. Periods start when the activation was submitted,
+// as the API does not report when it completed.
+//
+//	history, err := property.GetActivationHistory()
+//	version, _ := history.LiveVersion(papi.NetworkProduction, incidentTime)
+type ActivationHistory struct {
+	PropertyID  string
+	Activations []*Activation
+	Timelines   map[NetworkValue]*ActivationTimeline
+}
+
+// NewActivationHistory builds the history of activations
+func NewActivationHistory(activations *Activations) (*ActivationHistory, error) {
+	history := &ActivationHistory{
+		Timelines: map[NetworkValue]*ActivationTimeline{
+			NetworkStaging:    {Network: NetworkStaging},
+			NetworkProduction: {Network: NetworkProduction},
+		},
+	}
+
+	type dated struct {
+		activation *Activation
+		submitted  time.Time
+	}
+
+	var effective []*dated
+	for _, activation := range activations.Activations.Items {
+		if history.PropertyID == "" {
+			history.PropertyID = activation.PropertyID
+		}
+		history.Activations = append(history.Activations, activation)
+
+		if !activationTookEffect(activation) {
+			continue
+		}
+
+		submitted, err := time.Parse(time.RFC3339, activation.SubmitDate)
+		if err != nil {
+			return nil, fmt.Errorf("activation %s: invalid submit date: %s", activation.ActivationID, err)
+		}
+		effective = append(effective, &dated{activation, submitted})
+	}
+
+	sort.SliceStable(effective, func(i, j int) bool { return effective[i].submitted.Before(effective[j].submitted) })
+
+	for _, entry := range effective {
+		timeline, ok := history.Timelines[entry.activation.Network]
+		if !ok {
+			timeline = &ActivationTimeline{Network: entry.activation.Network}
+			history.Timelines[entry.activation.Network] = timeline
+		}
+
+		if current := timeline.Current(); current != nil {
+			current.End = entry.submitted
+			current.EndedBy = entry.activation
+		}
+
+		if entry.activation.ActivationType == ActivationTypeDeactivate {
+			continue
+		}
+
+		timeline.Periods = append(timeline.Periods, &ActivationPeriod{
+			Network:         entry.activation.Network,
+			PropertyVersion: entry.activation.PropertyVersion,
+			Activation:      entry.activation,
+			Start:           entry.submitted,
+		})
+	}
+
+	return history, nil
+}
+
+// activationTookEffect reports whether an activation completed, even if it
+// was superseded or deactivated since
+func activationTookEffect(activation *Activation) bool {
+	switch activation.Status {
+	case StatusActive, StatusInactive, StatusDeactivated, StatusPendingDeactivation:
+		return true
+	}
+
+	return false
+}
+
+// GetActivationHistory retrieves the activations of a property and builds
+// their history
+//
+// See: NewActivationHistory()
+func (property *Property) GetActivationHistory() (*ActivationHistory, error) {
+	activations, err := property.GetActivations()
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := NewActivationHistory(activations)
+	if err != nil {
+		return nil, err
+	}
+
+	if history.PropertyID == "" {
+		history.PropertyID = property.PropertyID
+	}
+
+	return history, nil
+}
+
+// Timeline returns the timeline of a network, defaulting to NetworkProduction
+func (history *ActivationHistory) Timeline(network NetworkValue) *ActivationTimeline {
+	if network == "" {
+		network = NetworkProduction
+	}
+
+	if timeline, ok := history.Timelines[network]; ok {
+		return timeline
+	}
+
+	return &ActivationTimeline{Network: network}
+}
+
+// LiveVersion returns the version live on a network at t, or 0 if none was
+func (history *ActivationHistory) LiveVersion(network NetworkValue, t time.Time) (int, *ActivationPeriod) {
+	period := history.Timeline(network).At(t)
+	if period == nil {
+		return 0, nil
+	}
+
+	return period.PropertyVersion, period
+}
+
+// RollbackOptions configures ActivationHistory.Rollback
+//
+// NotifyEmails defaults to the notify emails of the activation being rolled
+// back to, and ComplianceRecord to an EMERGENCY noncompliance reason.
+type RollbackOptions struct {
+	Network             NetworkValue
+	Note                string
+	NotifyEmails        []string
+	ComplianceRecord    *ActivationComplianceRecord
+	AcknowledgeWarnings bool
+}
+
+// Rollback creates, without submitting, the activation of the version live on
+// a network before the current one
+//
+// See: Property.Rollback()
+func (history *ActivationHistory) Rollback(options *RollbackOptions) (*Activation, error) {
+	if options == nil {
+		options = &RollbackOptions{}
+	}
+
+	timeline := history.Timeline(options.Network)
+	current := timeline.Current()
+	if current == nil {
+		return nil, fmt.Errorf("no version is live on %s", timeline.Network)
+	}
+
+	previous := timeline.Previous()
+	if previous == nil {
+		return nil, fmt.Errorf("no version was live on %s before v%d", timeline.Network, current.PropertyVersion)
+	}
+
+	note := fmt.Sprintf("Rollback from v%d to v%d", current.PropertyVersion, previous.PropertyVersion)
+	if options.Note != "" {
+		note = note + ": " + options.Note
+	}
+
+	notifyEmails := options.NotifyEmails
+	if len(notifyEmails) == 0 {
+		notifyEmails = previous.Activation.NotifyEmails
+	}
+	if len(notifyEmails) == 0 {
+		return nil, errors.New("rollback requires notify emails")
+	}
+
+	complianceRecord := options.ComplianceRecord
+	if complianceRecord == nil {
+		complianceRecord = NewEmergencyComplianceRecord("")
+	}
+
+	activation := NewActivation(NewActivations())
+	activation.ActivationType = ActivationTypeActivate
+	activation.PropertyID = history.PropertyID
+	activation.PropertyVersion = previous.PropertyVersion
+	activation.Network = timeline.Network
+	activation.Note = note
+	activation.NotifyEmails = notifyEmails
+	activation.ComplianceRecord = complianceRecord
+
+	return activation, nil
+}
+
+// Rollback activates the version live on a network before the current one,
+// production unless options.Network is set
+//
+// See: ActivationHistory.Rollback()
+// API Docs: https://developer.akamai.com/api/luna/papi/resources.html#activateaproperty
+// Endpoint: POST /papi/v1/properties/{propertyId}/activations/{?contractId,groupId}
+func (property *Property) Rollback(options *RollbackOptions) (*Activation, error) {
+	history, err := property.GetActivationHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	activation, err := history.Rollback(options)
+	if err != nil {
+		return nil, err
+	}
+
+	acknowledgeWarnings := options != nil && options.AcknowledgeWarnings
+	if err := activation.Save(property, acknowledgeWarnings); err != nil {
+		return nil, err
+	}
+
+	return activation, nil
+}
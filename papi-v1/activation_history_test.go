@@ -0,0 +1,139 @@
+package papi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/jsonhooks-v1"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const activationHistoryTestBody = `{"activations": {"items": [
+	{"activationId": "atv_5", "propertyId": "prp_1", "propertyVersion": 4, "network": "PRODUCTION", "activationType": "ACTIVATE", "status": "ACTIVE", "submitDate": "2020-01-05T10:00:00Z", "notifyEmails": ["dev@example.com"]},
+	{"activationId": "atv_4", "propertyId": "prp_1", "propertyVersion": 3, "network": "PRODUCTION", "activationType": "ACTIVATE", "status": "FAILED", "submitDate": "2020-01-04T10:00:00Z", "notifyEmails": ["dev@example.com"]},
+	{"activationId": "atv_3", "propertyId": "prp_1", "propertyVersion": 2, "network": "PRODUCTION", "activationType": "ACTIVATE", "status": "INACTIVE", "submitDate": "2020-01-03T10:00:00Z", "notifyEmails": ["ops@example.com"]},
+	{"activationId": "atv_2", "propertyId": "prp_1", "propertyVersion": 1, "network": "PRODUCTION", "activationType": "DEACTIVATE", "status": "DEACTIVATED", "submitDate": "2020-01-02T10:00:00Z", "notifyEmails": ["ops@example.com"]},
+	{"activationId": "atv_1", "propertyId": "prp_1", "propertyVersion": 1, "network": "PRODUCTION", "activationType": "ACTIVATE", "status": "INACTIVE", "submitDate": "2020-01-01T10:00:00Z", "notifyEmails": ["ops@example.com"]},
+	{"activationId": "atv_0", "propertyId": "prp_1", "propertyVersion": 4, "network": "STAGING", "activationType": "ACTIVATE", "status": "ACTIVE", "submitDate": "2019-12-31T10:00:00Z", "notifyEmails": ["dev@example.com"]}
+]}}`
+
+func newActivationHistoryTestHistory(t *testing.T) *ActivationHistory {
+	activations := NewActivations()
+	err := jsonhooks.Unmarshal([]byte(activationHistoryTestBody), activations)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	history, err := NewActivationHistory(activations)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	return history
+}
+
+func testTime(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
+
+func TestNewActivationHistory(t *testing.T) {
+	history := newActivationHistoryTestHistory(t)
+
+	assert.Equal(t, "prp_1", history.PropertyID)
+	assert.Len(t, history.Activations, 6)
+
+	production := history.Timeline(NetworkProduction)
+	if assert.Len(t, production.Periods, 3) {
+		assert.Equal(t, 1, production.Periods[0].PropertyVersion)
+		assert.Equal(t, testTime("2020-01-02T10:00:00Z"), production.Periods[0].End)
+		assert.Equal(t, "atv_2", production.Periods[0].EndedBy.ActivationID)
+		assert.Equal(t, 2, production.Periods[1].PropertyVersion)
+		assert.Equal(t, 4, production.Periods[2].PropertyVersion)
+	}
+	assert.Equal(t, 4, production.Current().PropertyVersion)
+	assert.Equal(t, 2, production.Previous().PropertyVersion)
+
+	assert.Equal(t, 4, history.Timeline(NetworkStaging).Current().PropertyVersion)
+	assert.Nil(t, history.Timeline(NetworkStaging).Previous())
+}
+
+func TestActivationHistory_LiveVersion(t *testing.T) {
+	history := newActivationHistoryTestHistory(t)
+
+	for at, expected := range map[string]int{
+		"2019-12-31T10:00:00Z": 0,
+		"2020-01-01T10:00:00Z": 1,
+		"2020-01-02T12:00:00Z": 0,
+		"2020-01-04T12:00:00Z": 2,
+		"2020-06-01T00:00:00Z": 4,
+	} {
+		version, _ := history.LiveVersion(NetworkProduction, testTime(at))
+		assert.Equal(t, expected, version, at)
+	}
+
+	version, period := history.LiveVersion(NetworkStaging, testTime("2020-01-01T00:00:00Z"))
+	assert.Equal(t, 4, version)
+	assert.Equal(t, "atv_0", period.Activation.ActivationID)
+}
+
+func TestActivationHistory_Rollback(t *testing.T) {
+	history := newActivationHistoryTestHistory(t)
+
+	activation, err := history.Rollback(&RollbackOptions{Note: "INC-42"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, activation.PropertyVersion)
+	assert.Equal(t, NetworkProduction, activation.Network)
+	assert.Equal(t, "Rollback from v4 to v2: INC-42", activation.Note)
+	assert.Equal(t, []string{"ops@example.com"}, activation.NotifyEmails)
+	assert.Equal(t, NoncomplianceReasonEmergency, activation.ComplianceRecord.NoncomplianceReason)
+
+	_, err = history.Rollback(&RollbackOptions{Network: NetworkStaging})
+	assert.Error(t, err)
+}
+
+func TestProperty_Rollback(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_1/activations$").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(activationHistoryTestBody)
+
+	gock.New(orchestratorTestURL).
+		Post("/papi/v1/properties/prp_1/activations$").
+		JSON(map[string]interface{}{
+			"activationType":   "ACTIVATE",
+			"complianceRecord": map[string]string{"noncomplianceReason": "EMERGENCY"},
+			"propertyId":       "prp_1",
+			"propertyVersion":  2,
+			"network":          "PRODUCTION",
+			"note":             "Rollback from v4 to v2",
+			"notifyEmails":     []string{"oncall@example.com"},
+		}).
+		Reply(201).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"activationLink": "/papi/v1/properties/prp_1/activations/atv_6"}`)
+
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_1/activations/atv_6").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"activations": {"items": [{"activationId": "atv_6", "propertyId": "prp_1", "propertyVersion": 2, "network": "PRODUCTION", "status": "PENDING"}]}}`)
+
+	Init(config)
+
+	property := NewProperty(NewProperties())
+	property.PropertyID = "prp_1"
+
+	activation, err := property.Rollback(&RollbackOptions{NotifyEmails: []string{"oncall@example.com"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, gock.IsDone())
+	assert.Equal(t, "atv_6", activation.ActivationID)
+}
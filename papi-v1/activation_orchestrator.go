@@ -0,0 +1,311 @@
+package papi
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ActivationEventType is used to create an "enum" of possible ActivationEvent.Type values
+type ActivationEventType string
+
+const (
+	// ActivationEventWarnings ActivationEvent.Type value WARNINGS, warnings were returned on submission
+	ActivationEventWarnings ActivationEventType = "WARNINGS"
+	// ActivationEventSubmitted ActivationEvent.Type value SUBMITTED, the activation was accepted
+	ActivationEventSubmitted ActivationEventType = "SUBMITTED"
+	// ActivationEventStatusChange ActivationEvent.Type value STATUS_CHANGE, the activation status changed
+	ActivationEventStatusChange ActivationEventType = "STATUS_CHANGE"
+	// ActivationEventActive ActivationEvent.Type value ACTIVE, the version is live on the network
+	ActivationEventActive ActivationEventType = "ACTIVE"
+	// ActivationEventFailed ActivationEvent.Type value FAILED, the activation failed or timed out
+	ActivationEventFailed ActivationEventType = "FAILED"
+	// ActivationEventCancelled ActivationEvent.Type value CANCELLED, the orchestrator was cancelled
+	ActivationEventCancelled ActivationEventType = "CANCELLED"
+)
+
+// ActivationStage is used to create an "enum" of possible ActivationError.Stage values
+type ActivationStage string
+
+const (
+	// ActivationStageSubmit the activation request was rejected
+	ActivationStageSubmit ActivationStage = "SUBMIT"
+	// ActivationStagePoll the activation status could not be retrieved
+	ActivationStagePoll ActivationStage = "POLL"
+	// ActivationStageStatus the activation reached a failed status
+	ActivationStageStatus ActivationStage = "STATUS"
+	// ActivationStageTimeout the activation did not complete in time
+	ActivationStageTimeout ActivationStage = "TIMEOUT"
+	// ActivationStageCancel the orchestrator was cancelled
+	ActivationStageCancel ActivationStage = "CANCEL"
+)
+
+// ActivationEvent describes progress of an ActivationOrchestrator
+type ActivationEvent struct {
+	Type       ActivationEventType
+	Network    NetworkValue
+	Activation *Activation
+	Status     StatusValue
+	Warnings   []*ActivationWarning
+	NextPoll   time.Duration
+	Err        error
+	Time       time.Time
+}
+
+// ActivationResult is the outcome of ActivationOrchestrator.Run
+//
+// Activations holds one entry per network that was submitted, in order.
+type ActivationResult struct {
+	PropertyID      string
+	PropertyVersion int
+	Activations     []*Activation
+	Started         time.Time
+	Finished        time.Time
+}
+
+// ActivationError is returned by ActivationOrchestrator.Run when a network
+// could not be activated
+type ActivationError struct {
+	Network    NetworkValue
+	Stage      ActivationStage
+	Activation *Activation
+	Status     StatusValue
+	Err        error
+}
+
+func (e *ActivationError) Error() string {
+	if e.Status != "" {
+		switch e.Stage {
+		case ActivationStageStatus:
+			return fmt.Sprintf("Activation on %s failed with status %s", e.Network, e.Status)
+		case ActivationStageTimeout:
+			return fmt.Sprintf("Activation on %s timed out with status %s", e.Network, e.Status)
+		case ActivationStageCancel:
+			return fmt.Sprintf("Activation on %s cancelled with status %s: %s", e.Network, e.Status, e.Err)
+		}
+	}
+
+	return fmt.Sprintf("Activation on %s failed (%s): %s", e.Network, e.Stage, e.Err)
+}
+
+// Unwrap returns the underlying error, if any
+func (e *ActivationError) Unwrap() error {
+	return e.Err
+}
+
+// ActivationOrchestrator activates a property version on each of Networks in
+// turn, waiting for every network to become active before moving on
+//
+//	orchestrator := papi.NewActivationOrchestrator(property, 3)
+//	orchestrator.NotifyEmails = []string{"ops@example.com"}
+//	orchestrator.OnEvent = func(event *papi.ActivationEvent) {
+//		log.Printf("%s %s %s", event.Network, event.Type, event.Status)
+//	}
+//	result, err := orchestrator.Run(ctx)
+//
+// Cancelling ctx cancels any activation that is still pending.
+type ActivationOrchestrator struct {
+	Property         *Property
+	PropertyVersion  int
+	Networks         []NetworkValue
+	Note             string
+	NotifyEmails     []string
+	FastPush         bool
+	ComplianceRecord *ActivationComplianceRecord
+
+	// AcknowledgeWarnings decides whether warnings returned on submission
+	// are acknowledged. When nil, warnings fail the activation.
+	AcknowledgeWarnings func(network NetworkValue, warnings []*ActivationWarning) bool
+
+	// NetworkTimeout limits how long each network is polled, zero waits
+	// indefinitely. Timed out activations are left running.
+	NetworkTimeout time.Duration
+
+	// MinPollInterval and MaxPollInterval bound the Retry-After interval
+	// suggested by the API
+	MinPollInterval time.Duration
+	MaxPollInterval time.Duration
+
+	// OnEvent is called synchronously for every event
+	OnEvent func(event *ActivationEvent)
+	// Events receives every event; the caller must keep it drained
+	Events chan<- *ActivationEvent
+}
+
+// NewActivationOrchestrator creates a new ActivationOrchestrator for staging, then production
+func NewActivationOrchestrator(property *Property, version int) *ActivationOrchestrator {
+	return &ActivationOrchestrator{
+		Property:        property,
+		PropertyVersion: version,
+		Networks:        []NetworkValue{NetworkStaging, NetworkProduction},
+		MinPollInterval: 10 * time.Second,
+		MaxPollInterval: 5 * time.Minute,
+	}
+}
+
+// Run activates the property version on each network
+//
+// The result contains every activation submitted, even when an error is
+// returned. Errors caused by the activation itself are *ActivationError.
+//
+// The compliance record is checked against ActivationPolicies for every
+// network before anything is submitted.
+func (orchestrator *ActivationOrchestrator) Run(ctx context.Context) (*ActivationResult, error) {
+	result := &ActivationResult{
+		PropertyID:      orchestrator.Property.PropertyID,
+		PropertyVersion: orchestrator.PropertyVersion,
+		Started:         time.Now(),
+	}
+
+	for _, network := range orchestrator.Networks {
+		record, note := orchestrator.ComplianceRecord, orchestrator.Note
+		if err := checkCompliance(network, &record, &note); err != nil {
+			result.Finished = time.Now()
+			return result, err
+		}
+	}
+
+	for _, network := range orchestrator.Networks {
+		activation, err := orchestrator.activate(ctx, network)
+		if activation != nil {
+			result.Activations = append(result.Activations, activation)
+		}
+
+		if err != nil {
+			result.Finished = time.Now()
+			return result, err
+		}
+	}
+
+	result.Finished = time.Now()
+
+	return result, nil
+}
+
+func (orchestrator *ActivationOrchestrator) activate(ctx context.Context, network NetworkValue) (*Activation, error) {
+	activation := NewActivation(NewActivations())
+	activation.ActivationType = ActivationTypeActivate
+	activation.PropertyVersion = orchestrator.PropertyVersion
+	activation.Network = network
+	activation.Note = orchestrator.Note
+	activation.NotifyEmails = orchestrator.NotifyEmails
+	activation.FastPush = orchestrator.FastPush
+	activation.ComplianceRecord = orchestrator.ComplianceRecord
+
+	if err := ctx.Err(); err != nil {
+		return nil, orchestrator.fail(network, ActivationStageCancel, nil, err)
+	}
+
+	err := activation.SaveAcknowledging(orchestrator.Property, func(warnings []*ActivationWarning) bool {
+		orchestrator.emit(&ActivationEvent{Type: ActivationEventWarnings, Network: network, Warnings: warnings})
+		return orchestrator.AcknowledgeWarnings != nil && orchestrator.AcknowledgeWarnings(network, warnings)
+	})
+	if err != nil {
+		return nil, orchestrator.fail(network, ActivationStageSubmit, nil, err)
+	}
+
+	orchestrator.emit(&ActivationEvent{Type: ActivationEventSubmitted, Network: network, Activation: activation, Status: activation.Status})
+
+	var deadline <-chan time.Time
+	if orchestrator.NetworkTimeout > 0 {
+		timer := time.NewTimer(orchestrator.NetworkTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	retry := time.Duration(0)
+	for {
+		switch activation.Status {
+		case StatusActive:
+			orchestrator.emit(&ActivationEvent{Type: ActivationEventActive, Network: network, Activation: activation, Status: activation.Status})
+			return activation, nil
+		case StatusFailed, StatusAborted, StatusDeactivated, StatusInactive:
+			return activation, orchestrator.fail(network, ActivationStageStatus, activation, nil)
+		}
+
+		timer := time.NewTimer(orchestrator.pollInterval(network, retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return activation, orchestrator.cancel(network, activation, ctx.Err())
+		case <-deadline:
+			timer.Stop()
+			return activation, orchestrator.fail(network, ActivationStageTimeout, activation, nil)
+		case <-timer.C:
+		}
+
+		status := activation.Status
+		retry, err = activation.GetActivation(orchestrator.Property)
+		if err != nil {
+			return activation, orchestrator.fail(network, ActivationStagePoll, activation, err)
+		}
+
+		if activation.Status != status {
+			orchestrator.emit(&ActivationEvent{
+				Type:       ActivationEventStatusChange,
+				Network:    network,
+				Activation: activation,
+				Status:     activation.Status,
+				NextPoll:   orchestrator.pollInterval(network, retry),
+			})
+		}
+	}
+}
+
+// pollInterval clamps the API suggested interval, capping staging at a
+// minute as Activation.PollStatus does
+func (orchestrator *ActivationOrchestrator) pollInterval(network NetworkValue, retry time.Duration) time.Duration {
+	if network == NetworkStaging && retry > time.Minute {
+		retry = time.Minute
+	}
+
+	if retry < orchestrator.MinPollInterval {
+		retry = orchestrator.MinPollInterval
+	}
+
+	if orchestrator.MaxPollInterval > 0 && retry > orchestrator.MaxPollInterval {
+		retry = orchestrator.MaxPollInterval
+	}
+
+	return retry
+}
+
+func (orchestrator *ActivationOrchestrator) cancel(network NetworkValue, activation *Activation, reason error) error {
+	var err error = reason
+	if activation.Status == StatusPending || activation.Status == StatusNew {
+		if cancelErr := activation.Cancel(orchestrator.Property); cancelErr != nil {
+			err = fmt.Errorf("%s (cancelling activation %s: %s)", reason, activation.ActivationID, cancelErr)
+		}
+	}
+
+	orchestrator.emit(&ActivationEvent{Type: ActivationEventCancelled, Network: network, Activation: activation, Status: activation.Status, Err: err})
+
+	return &ActivationError{Network: network, Stage: ActivationStageCancel, Activation: activation, Status: activation.Status, Err: err}
+}
+
+func (orchestrator *ActivationOrchestrator) fail(network NetworkValue, stage ActivationStage, activation *Activation, err error) error {
+	activationErr := &ActivationError{Network: network, Stage: stage, Activation: activation, Err: err}
+	if activation != nil {
+		activationErr.Status = activation.Status
+	}
+
+	eventType := ActivationEventFailed
+	if stage == ActivationStageCancel {
+		eventType = ActivationEventCancelled
+	}
+	orchestrator.emit(&ActivationEvent{Type: eventType, Network: network, Activation: activation, Status: activationErr.Status, Err: activationErr})
+
+	return activationErr
+}
+
+func (orchestrator *ActivationOrchestrator) emit(event *ActivationEvent) {
+	event.Time = time.Now()
+
+	if orchestrator.OnEvent != nil {
+		orchestrator.OnEvent(event)
+	}
+
+	if orchestrator.Events != nil {
+		orchestrator.Events <- event
+	}
+}
@@ -0,0 +1,188 @@
+package papi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const orchestratorTestURL = "https://akaa-baseurl-xxxxxxxxxxx-xxxxxxxxxxxxx.luna.akamaiapis.net"
+
+func activationBody(status StatusValue) string {
+	return `{
+		"accountId": "act_TEST",
+		"contractId": "ctr_TEST",
+		"groupId": "grp_123",
+		"activations": {
+			"items": [{
+				"activationId": "atv_1",
+				"propertyName": "example.org",
+				"propertyId": "prp_123",
+				"propertyVersion": 2,
+				"network": "STAGING",
+				"activationType": "ACTIVATE",
+				"status": "` + string(status) + `",
+				"notifyEmails": ["ops@example.org"]
+			}]
+		}
+	}`
+}
+
+func newOrchestratorTestProperty() *Property {
+	property := NewProperty(NewProperties())
+	property.PropertyID = "prp_123"
+	property.ContractID = "ctr_TEST"
+	property.GroupID = "grp_123"
+
+	return property
+}
+
+func TestActivationOrchestrator_Run(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Post("/papi/v1/properties/prp_123/activations").
+		Reply(400).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"warnings": [{"type": "/papi/v1/warnings/test", "messageId": "msg_1", "detail": "Test warning"}]}`)
+	gock.New(orchestratorTestURL).
+		Post("/papi/v1/properties/prp_123/activations").
+		Reply(201).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"activationLink": "/papi/v1/properties/prp_123/activations/atv_1?contractId=ctr_TEST&groupId=grp_123"}`)
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_123/activations/atv_1").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(activationBody(StatusPending))
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_123/activations/atv_1").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Retry-After", "0").
+		BodyString(activationBody(StatusZone1))
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_123/activations/atv_1").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(activationBody(StatusActive))
+
+	Init(config)
+
+	orchestrator := NewActivationOrchestrator(newOrchestratorTestProperty(), 2)
+	orchestrator.Networks = []NetworkValue{NetworkStaging}
+	orchestrator.MinPollInterval = 0
+	orchestrator.MaxPollInterval = time.Millisecond
+	orchestrator.AcknowledgeWarnings = func(network NetworkValue, warnings []*ActivationWarning) bool {
+		return len(warnings) == 1 && warnings[0].MessageID == "msg_1"
+	}
+
+	var events []ActivationEventType
+	orchestrator.OnEvent = func(event *ActivationEvent) {
+		events = append(events, event.Type)
+	}
+
+	result, err := orchestrator.Run(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+	if assert.Len(t, result.Activations, 1) {
+		assert.Equal(t, StatusActive, result.Activations[0].Status)
+	}
+	assert.Equal(t, []ActivationEventType{
+		ActivationEventWarnings,
+		ActivationEventSubmitted,
+		ActivationEventStatusChange,
+		ActivationEventStatusChange,
+		ActivationEventActive,
+	}, events)
+}
+
+func TestActivationOrchestrator_Run_Failed(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Post("/papi/v1/properties/prp_123/activations").
+		Reply(201).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"activationLink": "/papi/v1/properties/prp_123/activations/atv_1?contractId=ctr_TEST&groupId=grp_123"}`)
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_123/activations/atv_1").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(activationBody(StatusFailed))
+
+	Init(config)
+
+	orchestrator := NewActivationOrchestrator(newOrchestratorTestProperty(), 2)
+	orchestrator.ComplianceRecord = NewEmergencyComplianceRecord("")
+
+	result, err := orchestrator.Run(context.Background())
+	assert.Len(t, result.Activations, 1)
+
+	var activationErr *ActivationError
+	if assert.True(t, errors.As(err, &activationErr)) {
+		assert.Equal(t, NetworkStaging, activationErr.Network)
+		assert.Equal(t, ActivationStageStatus, activationErr.Stage)
+		assert.Equal(t, StatusFailed, activationErr.Status)
+	}
+}
+
+func TestActivationOrchestrator_Run_Cancelled(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Post("/papi/v1/properties/prp_123/activations").
+		Reply(201).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"activationLink": "/papi/v1/properties/prp_123/activations/atv_1?contractId=ctr_TEST&groupId=grp_123"}`)
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_123/activations/atv_1").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(activationBody(StatusPending))
+	gock.New(orchestratorTestURL).
+		Delete("/papi/v1/properties/prp_123/activations/atv_1").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(activationBody(StatusAborted))
+
+	Init(config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	orchestrator := NewActivationOrchestrator(newOrchestratorTestProperty(), 2)
+	orchestrator.ComplianceRecord = NewEmergencyComplianceRecord("")
+	orchestrator.OnEvent = func(event *ActivationEvent) {
+		if event.Type == ActivationEventSubmitted {
+			cancel()
+		}
+	}
+
+	_, err := orchestrator.Run(ctx)
+	assert.True(t, gock.IsDone())
+
+	var activationErr *ActivationError
+	if assert.True(t, errors.As(err, &activationErr)) {
+		assert.Equal(t, ActivationStageCancel, activationErr.Stage)
+		assert.Equal(t, StatusAborted, activationErr.Status)
+		assert.Equal(t, context.Canceled, activationErr.Err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	res := &http.Response{Header: http.Header{}}
+	assert.Equal(t, 30*time.Second, retryAfter(res, 30*time.Second))
+
+	res.Header.Set("Retry-After", "120")
+	assert.Equal(t, 2*time.Minute, retryAfter(res, 30*time.Second))
+
+	res.Header.Set("Retry-After", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	assert.Equal(t, time.Duration(0), retryAfter(res, 30*time.Second))
+
+	res.Header.Set("Retry-After", "soon")
+	assert.Equal(t, 30*time.Second, retryAfter(res, 30*time.Second))
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
@@ -130,8 +132,12 @@ type Activation struct {
 	StatusChange        chan bool                   `json:"-"`
 }
 
-type ActivationComplianceRecord struct {
-	NoncomplianceReason string `json:"noncomplianceReason,omitempty"`
+// ActivationWarning is a warning returned by the API when activating a property
+type ActivationWarning struct {
+	Type      string `json:"type"`
+	MessageID string `json:"messageId"`
+	Title     string `json:"title,omitempty"`
+	Detail    string `json:"detail"`
 }
 
 // NewActivation creates a new Activation
@@ -149,6 +155,9 @@ func (activation *Activation) Init() {
 
 // GetActivation populates the Activation resource
 //
+// The returned duration is the poll interval suggested by the API through
+// the Retry-After header, defaulting to 30 seconds.
+//
 // API Docs: https://developer.akamai.com/api/luna/papi/resources.html#getanactivation
 // Endpoint: GET /papi/v1/properties/{propertyId}/activations/{activationId}{?contractId,groupId}
 func (activation *Activation) GetActivation(property *Property) (time.Duration, error) {
@@ -199,10 +208,29 @@ func (activation *Activation) GetActivation(property *Property) (time.Duration,
 	activation.Note = activations.Activations.Items[0].Note
 	activation.NotifyEmails = activations.Activations.Items[0].NotifyEmails
 
-	//retry, _ := strconv.Atoi(res.Header.Get("Retry-After"))
-	//retry *= int(time.Second)
+	return retryAfter(res, 30*time.Second), nil
+}
+
+// retryAfter returns the poll interval suggested by the Retry-After header,
+// which may be given in seconds or as an HTTP date
+func retryAfter(res *http.Response, fallback time.Duration) time.Duration {
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return fallback
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+		return 0
+	}
 
-	return time.Duration(30 * time.Second), nil
+	return fallback
 }
 
 // Save activates a given property
@@ -214,10 +242,24 @@ func (activation *Activation) GetActivation(property *Property) (time.Duration,
 // API Docs: https://developer.akamai.com/api/luna/papi/resources.html#activateaproperty
 // Endpoint: POST /papi/v1/properties/{propertyId}/activations/{?contractId,groupId}
 func (activation *Activation) Save(property *Property, acknowledgeWarnings bool) error {
-	if activation.ComplianceRecord == nil {
-		activation.ComplianceRecord = &ActivationComplianceRecord{
-			NoncomplianceReason: "NO_PRODUCTION_TRAFFIC",
-		}
+	return activation.SaveAcknowledging(property, func(warnings []*ActivationWarning) bool {
+		return acknowledgeWarnings
+	})
+}
+
+// SaveAcknowledging activates a given property, deciding per attempt whether
+// to acknowledge warnings
+//
+// If warnings are returned on the first attempt, acknowledge is called with
+// them. When it returns true, a second attempt is made, acknowledging the
+// warnings; otherwise the API error is returned.
+//
+// See: Activation.Save()
+// API Docs: https://developer.akamai.com/api/luna/papi/resources.html#activateaproperty
+// Endpoint: POST /papi/v1/properties/{propertyId}/activations/{?contractId,groupId}
+func (activation *Activation) SaveAcknowledging(property *Property, acknowledge func(warnings []*ActivationWarning) bool) error {
+	if err := checkCompliance(activation.Network, &activation.ComplianceRecord, &activation.Note); err != nil {
+		return err
 	}
 
 	req, err := client.NewJSONRequest(
@@ -237,17 +279,17 @@ func (activation *Activation) Save(property *Property, acknowledgeWarnings bool)
 	}
 
 	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
 
-	if client.IsError(res) && (!acknowledgeWarnings || (acknowledgeWarnings && res.StatusCode != 400)) {
+	if client.IsError(res) && (acknowledge == nil || res.StatusCode != 400) {
 		return client.NewAPIError(res)
 	}
 
-	if res.StatusCode == 400 && acknowledgeWarnings {
+	if res.StatusCode == 400 {
 		warnings := &struct {
-			Warnings []struct {
-				Detail    string `json:"detail"`
-				MessageID string `json:"messageId"`
-			} `json:"warnings,omitempty"`
+			Warnings []*ActivationWarning `json:"warnings,omitempty"`
 		}{}
 
 		body, err := ioutil.ReadAll(res.Body)
@@ -260,13 +302,7 @@ func (activation *Activation) Save(property *Property, acknowledgeWarnings bool)
 		}
 
 		// Just in case we got a 400 for a different reason
-		if len(warnings.Warnings) == 0 {
-			jsonBody := &client.JSONBody{}
-
-			if err = json.Unmarshal(body, jsonBody); err != nil {
-				return err
-			}
-
+		if len(warnings.Warnings) == 0 || !acknowledge(warnings.Warnings) {
 			return client.NewAPIErrorFromBody(res, body)
 		}
 
@@ -275,7 +311,7 @@ func (activation *Activation) Save(property *Property, acknowledgeWarnings bool)
 		}
 
 		// Don't acknowledgeWarnings again, halting a potential endless recursion
-		return activation.Save(property, false)
+		return activation.SaveAcknowledging(property, nil)
 	}
 
 	var location client.JSONBody
@@ -295,6 +331,9 @@ func (activation *Activation) Save(property *Property, acknowledgeWarnings bool)
 	}
 
 	res, err = client.Do(Config, req)
+	if err != nil {
+		return err
+	}
 
 	activations := NewActivations()
 	if err := client.BodyJSON(res, activations); err != nil {
@@ -383,10 +422,11 @@ func (activation *Activation) Cancel(property *Property) error {
 		Config,
 		"DELETE",
 		fmt.Sprintf(
-			"/papi/v1/properties/%s/activations?contractId=%s&groupId=%s",
+			"/papi/v1/properties/%s/activations/%s?contractId=%s&groupId=%s",
 			property.PropertyID,
-			property.Contract.ContractID,
-			property.Group.GroupID,
+			activation.ActivationID,
+			property.ContractID,
+			property.GroupID,
 		),
 		nil,
 	)
@@ -396,6 +436,9 @@ func (activation *Activation) Cancel(property *Property) error {
 	}
 
 	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
 
 	if client.IsError(res) {
 		return client.NewAPIError(res)
@@ -421,7 +464,6 @@ func (activation *Activation) Cancel(property *Property) error {
 	activation.UpdateDate = newActivations.Activations.Items[0].UpdateDate
 	activation.Note = newActivations.Activations.Items[0].Note
 	activation.NotifyEmails = newActivations.Activations.Items[0].NotifyEmails
-	activation.StatusChange = newActivations.Activations.Items[0].StatusChange
 
 	return nil
 }
@@ -0,0 +1,368 @@
+package papi
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
+)
+
+// BulkOperation is run by a BulkExecutor for each property
+//
+// Operations run concurrently and must be safe for concurrent use.
+type BulkOperation func(ctx context.Context, property *Property) error
+
+// BulkResult is the outcome of a BulkOperation for one property
+//
+// Skipped results were completed by a previous run, see BulkExecutor.Checkpoint.
+type BulkResult struct {
+	Property *Property
+	Err      error
+	Skipped  bool
+	Attempts int
+	Duration time.Duration
+}
+
+// BulkProgress counts the properties processed so far by a BulkExecutor
+type BulkProgress struct {
+	Total     int
+	Completed int
+	Failed    int
+	Skipped   int
+}
+
+// Done returns how many properties were processed
+func (progress BulkProgress) Done() int {
+	return progress.Completed + progress.Failed + progress.Skipped
+}
+
+// BulkReport holds the results of BulkExecutor.Run, in the order of the
+// properties given
+type BulkReport struct {
+	Results  []*BulkResult
+	Progress BulkProgress
+}
+
+// Failed returns the results of the properties whose operation failed
+func (report *BulkReport) Failed() []*BulkResult {
+	var failed []*BulkResult
+	for _, result := range report.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// BulkExecutor runs an operation over many properties with bounded concurrency
+//
+// Requests go through client.Do and so wait for client.Limiter, which
+// should be set to stay within the API rate limit. Operations failing with
+// 429 Too Many Requests or a 5xx error are retried, honoring Retry-After.
+//
+// When Checkpoint is set, the ID of each property completed is appended to
+// that file, and properties listed in it are skipped, so an interrupted run
+// resumes where it stopped. Failed properties are retried on resume.
+//
+//	client.Limiter = client.NewTokenBucket(10, 20)
+//	executor := papi.NewBulkExecutor(8)
+//	executor.Checkpoint = "/var/lib/nightly/checkpoint"
+//	report, err := executor.Run(ctx, properties, papi.PatchRulesOperation(patch))
+type BulkExecutor struct {
+	Concurrency  int
+	Retries      int
+	RetryBackoff time.Duration
+	Checkpoint   string
+
+	// OnProgress is called after each property, never concurrently
+	OnProgress func(progress BulkProgress, result *BulkResult)
+}
+
+// NewBulkExecutor creates a new BulkExecutor running up to concurrency
+// operations at once
+func NewBulkExecutor(concurrency int) *BulkExecutor {
+	return &BulkExecutor{
+		Concurrency:  concurrency,
+		Retries:      3,
+		RetryBackoff: 5 * time.Second,
+	}
+}
+
+// Run runs operation for each property
+//
+// The report contains a result for every property, even when an error is
+// returned. An error is returned when ctx is cancelled, in which case
+// properties not processed have ctx.Err() as their error, unless the
+// checkpoint marks them completed, or when the checkpoint file can not be
+// read or written.
+func (executor *BulkExecutor) Run(ctx context.Context, properties []*Property, operation BulkOperation) (*BulkReport, error) {
+	completed, err := executor.readCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint *os.File
+	if executor.Checkpoint != "" {
+		checkpoint, err = os.OpenFile(executor.Checkpoint, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		defer checkpoint.Close()
+	}
+
+	report := &BulkReport{Results: make([]*BulkResult, len(properties))}
+	report.Progress.Total = len(properties)
+
+	var mu sync.Mutex
+	var checkpointErr error
+	finish := func(result *BulkResult) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case result.Skipped:
+			report.Progress.Skipped++
+		case result.Err != nil:
+			report.Progress.Failed++
+		default:
+			report.Progress.Completed++
+			if checkpoint != nil && checkpointErr == nil {
+				_, checkpointErr = fmt.Fprintln(checkpoint, result.Property.PropertyID)
+			}
+		}
+
+		if executor.OnProgress != nil {
+			executor.OnProgress(report.Progress, result)
+		}
+	}
+
+	concurrency := executor.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				result := executor.run(ctx, properties[index], operation)
+				report.Results[index] = result
+				finish(result)
+			}
+		}()
+	}
+
+	for index, property := range properties {
+		if completed[property.PropertyID] {
+			result := &BulkResult{Property: property, Skipped: true}
+			report.Results[index] = result
+			finish(result)
+			continue
+		}
+
+		select {
+		case jobs <- index:
+			continue
+		case <-ctx.Done():
+		}
+
+		for ; index < len(properties); index++ {
+			result := &BulkResult{Property: properties[index], Err: ctx.Err()}
+			if completed[properties[index].PropertyID] {
+				result = &BulkResult{Property: properties[index], Skipped: true}
+			}
+			report.Results[index] = result
+			finish(result)
+		}
+		break
+	}
+	close(jobs)
+	wg.Wait()
+
+	if checkpointErr != nil {
+		return report, fmt.Errorf("writing checkpoint: %s", checkpointErr)
+	}
+
+	return report, ctx.Err()
+}
+
+// run runs operation for one property, retrying throttled and server errors
+func (executor *BulkExecutor) run(ctx context.Context, property *Property, operation BulkOperation) *BulkResult {
+	result := &BulkResult{Property: property}
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			result.Err = err
+			return result
+		}
+
+		result.Attempts++
+		result.Err = operation(ctx, property)
+		if result.Err == nil || result.Attempts > executor.Retries {
+			return result
+		}
+
+		var apiErr client.APIError
+		if !errors.As(result.Err, &apiErr) || (apiErr.Status != 429 && apiErr.Status < 500) {
+			return result
+		}
+
+		wait := executor.RetryBackoff * time.Duration(1<<uint(result.Attempts-1))
+		if apiErr.Response != nil {
+			wait = retryAfter(apiErr.Response, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// readCheckpoint returns the property IDs listed in the checkpoint file
+func (executor *BulkExecutor) readCheckpoint() (map[string]bool, error) {
+	completed := map[string]bool{}
+	if executor.Checkpoint == "" {
+		return completed, nil
+	}
+
+	file, err := os.Open(executor.Checkpoint)
+	if os.IsNotExist(err) {
+		return completed, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if id := strings.TrimSpace(scanner.Text()); id != "" {
+			completed[id] = true
+		}
+	}
+
+	return completed, scanner.Err()
+}
+
+// FetchRulesOperation retrieves the rules of the latest version of each
+// property and passes them to handle
+func FetchRulesOperation(handle func(property *Property, rules *Rules) error) BulkOperation {
+	return func(ctx context.Context, property *Property) error {
+		version, err := property.GetLatestVersion("")
+		if err != nil {
+			return err
+		}
+
+		rules := NewRules()
+		if err := rules.GetRulesForVersion(property, version.PropertyVersion); err != nil {
+			return err
+		}
+
+		return handle(property, rules)
+	}
+}
+
+// PatchRulesOperation applies patch to the rules of the latest version of
+// each property and saves them
+//
+// Properties left unchanged by patch are not modified. The latest version is
+// edited in place unless it was activated, in which case a new version is
+// created from it; property.LatestVersion is updated accordingly.
+func PatchRulesOperation(patch func(property *Property, rules *Rules) error) BulkOperation {
+	return func(ctx context.Context, property *Property) error {
+		version, err := property.GetLatestVersion("")
+		if err != nil {
+			return err
+		}
+
+		rules := NewRules()
+		if err := rules.GetRulesForVersion(property, version.PropertyVersion); err != nil {
+			return err
+		}
+
+		patched := copyRules(rules)
+		if err := patch(property, patched); err != nil {
+			return err
+		}
+
+		if len(DiffRules(rules, patched)) == 0 {
+			return nil
+		}
+
+		var session *PropertyVersionSession
+		if (version.StagingStatus == "" || version.StagingStatus == StatusInactive) &&
+			(version.ProductionStatus == "" || version.ProductionStatus == StatusInactive) {
+			session, err = OpenPropertyVersionSession(property, version)
+		} else {
+			session, err = NewPropertyVersionSession(property, version)
+		}
+		if err != nil {
+			return err
+		}
+
+		session.Rules.Rule = patched.Rule
+		if err := session.Save(); err != nil {
+			return err
+		}
+
+		property.LatestVersion = session.Version.PropertyVersion
+
+		return nil
+	}
+}
+
+// ActivateOperation activates the latest version of each property, waiting
+// for the activation to complete
+//
+// template configures each activation; its Property and PropertyVersion
+// are ignored. Events are emitted for every property.
+//
+// See: ActivationOrchestrator.Run()
+func ActivateOperation(template *ActivationOrchestrator) BulkOperation {
+	return func(ctx context.Context, property *Property) error {
+		version := property.LatestVersion
+		if version == 0 {
+			latest, err := property.GetLatestVersion("")
+			if err != nil {
+				return err
+			}
+			version = latest.PropertyVersion
+		}
+
+		orchestrator := *template
+		orchestrator.Property = property
+		orchestrator.PropertyVersion = version
+
+		_, err := orchestrator.Run(ctx)
+
+		return err
+	}
+}
+
+// ChainOperations runs operations in turn, stopping at the first error
+func ChainOperations(operations ...BulkOperation) BulkOperation {
+	return func(ctx context.Context, property *Property) error {
+		for _, operation := range operations {
+			if err := operation(ctx, property); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,168 @@
+package papi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBulkTestProperties(count int) []*Property {
+	properties := make([]*Property, count)
+	for i := range properties {
+		properties[i] = NewProperty(NewProperties())
+		properties[i].PropertyID = fmt.Sprintf("prp_%d", i+1)
+	}
+
+	return properties
+}
+
+func TestBulkExecutor_Run(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+
+	executor := NewBulkExecutor(3)
+	var progress []BulkProgress
+	executor.OnProgress = func(p BulkProgress, result *BulkResult) {
+		progress = append(progress, p)
+	}
+
+	report, err := executor.Run(context.Background(), newBulkTestProperties(10), func(ctx context.Context, property *Property) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+
+		if property.PropertyID == "prp_4" {
+			return errors.New("invalid rules")
+		}
+		return nil
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.True(t, maxRunning <= 3)
+	assert.Len(t, report.Results, 10)
+	assert.Equal(t, "prp_1", report.Results[0].Property.PropertyID)
+	assert.Equal(t, "prp_10", report.Results[9].Property.PropertyID)
+	assert.Equal(t, BulkProgress{Total: 10, Completed: 9, Failed: 1}, report.Progress)
+	assert.Len(t, progress, 10)
+	assert.Equal(t, 10, progress[9].Done())
+
+	if failed := report.Failed(); assert.Len(t, failed, 1) {
+		assert.Equal(t, "prp_4", failed[0].Property.PropertyID)
+		assert.Equal(t, 1, failed[0].Attempts)
+	}
+}
+
+func TestBulkExecutor_RunCheckpoint(t *testing.T) {
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint")
+	if !assert.NoError(t, ioutil.WriteFile(checkpoint, []byte("prp_1\nprp_2\n"), 0644)) {
+		return
+	}
+
+	executor := NewBulkExecutor(2)
+	executor.Checkpoint = checkpoint
+
+	report, err := executor.Run(context.Background(), newBulkTestProperties(4), func(ctx context.Context, property *Property) error {
+		if property.PropertyID == "prp_4" {
+			return errors.New("invalid rules")
+		}
+		return nil
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.True(t, report.Results[0].Skipped)
+	assert.True(t, report.Results[1].Skipped)
+	assert.Equal(t, BulkProgress{Total: 4, Completed: 1, Failed: 1, Skipped: 2}, report.Progress)
+
+	contents, err := ioutil.ReadFile(checkpoint)
+	assert.NoError(t, err)
+	assert.Equal(t, "prp_1\nprp_2\nprp_3\n", string(contents))
+}
+
+func TestBulkExecutor_RunRetry(t *testing.T) {
+	executor := NewBulkExecutor(1)
+	executor.RetryBackoff = 0
+
+	calls := 0
+	report, err := executor.Run(context.Background(), newBulkTestProperties(1), func(ctx context.Context, property *Property) error {
+		calls++
+		if calls < 3 {
+			return client.APIError{Status: 429}
+		}
+		return nil
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, report.Results[0].Err)
+	assert.Equal(t, 3, report.Results[0].Attempts)
+
+	calls = 0
+	report, _ = executor.Run(context.Background(), newBulkTestProperties(1), func(ctx context.Context, property *Property) error {
+		calls++
+		return client.APIError{Status: 400}
+	})
+	assert.Error(t, report.Results[0].Err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestBulkExecutor_RunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := NewBulkExecutor(2).Run(ctx, newBulkTestProperties(3), func(ctx context.Context, property *Property) error {
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	for _, result := range report.Results {
+		assert.Equal(t, context.Canceled, result.Err)
+	}
+}
+
+func TestBulkExecutor_RunCancelledCheckpoint(t *testing.T) {
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint")
+	if !assert.NoError(t, ioutil.WriteFile(checkpoint, []byte("prp_3\n"), 0644)) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	executor := NewBulkExecutor(1)
+	executor.Checkpoint = checkpoint
+	var progress []BulkProgress
+	executor.OnProgress = func(p BulkProgress, result *BulkResult) {
+		progress = append(progress, p)
+	}
+
+	report, err := executor.Run(ctx, newBulkTestProperties(3), func(ctx context.Context, property *Property) error {
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, context.Canceled, report.Results[0].Err)
+	assert.Equal(t, context.Canceled, report.Results[1].Err)
+	assert.True(t, report.Results[2].Skipped)
+	assert.NoError(t, report.Results[2].Err)
+	assert.Equal(t, BulkProgress{Total: 3, Failed: 2, Skipped: 1}, report.Progress)
+	assert.Len(t, progress, 3)
+}
@@ -0,0 +1,638 @@
+package papi
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/jsonhooks-v1"
+)
+
+// BundleFormatVersion is the version of the bundle layout written by PropertyBundle
+//
+// A bundle is a directory, or a gzipped tarball of one, laid out as:
+//
+//	manifest.json
+//	versions/{propertyVersion}/rules.json
+//	versions/{propertyVersion}/hostnames.json
+const BundleFormatVersion = 1
+
+// BundleManifest describes the content of a PropertyBundle
+type BundleManifest struct {
+	FormatVersion int                   `json:"formatVersion"`
+	ExportedAt    time.Time             `json:"exportedAt"`
+	Property      BundleProperty        `json:"property"`
+	Versions      []*BundleVersion      `json:"versions"`
+	CpCodes       []*BundleCpCode       `json:"cpcodes"`
+	EdgeHostnames []*BundleEdgeHostname `json:"edgeHostnames"`
+}
+
+// BundleProperty is the property metadata stored in a bundle
+type BundleProperty struct {
+	AccountID         string `json:"accountId"`
+	ContractID        string `json:"contractId"`
+	GroupID           string `json:"groupId"`
+	PropertyID        string `json:"propertyId"`
+	PropertyName      string `json:"propertyName"`
+	ProductID         string `json:"productId"`
+	RuleFormat        string `json:"ruleFormat,omitempty"`
+	LatestVersion     int    `json:"latestVersion"`
+	StagingVersion    int    `json:"stagingVersion,omitempty"`
+	ProductionVersion int    `json:"productionVersion,omitempty"`
+}
+
+// BundleVersion is a property version stored in a bundle
+type BundleVersion struct {
+	PropertyVersion  int         `json:"propertyVersion"`
+	Note             string      `json:"note,omitempty"`
+	RuleFormat       string      `json:"ruleFormat,omitempty"`
+	ProductID        string      `json:"productId,omitempty"`
+	UpdatedByUser    string      `json:"updatedByUser,omitempty"`
+	UpdatedDate      time.Time   `json:"updatedDate,omitempty"`
+	StagingStatus    StatusValue `json:"stagingStatus,omitempty"`
+	ProductionStatus StatusValue `json:"productionStatus,omitempty"`
+}
+
+// BundleCpCode is a CP code referenced by a bundled rule tree
+type BundleCpCode struct {
+	CpcodeID   string   `json:"cpcodeId"`
+	CpcodeName string   `json:"cpcodeName,omitempty"`
+	ProductIDs []string `json:"productIds,omitempty"`
+}
+
+// BundleEdgeHostname is an edge hostname referenced by bundled hostnames
+type BundleEdgeHostname struct {
+	EdgeHostnameID     string `json:"edgeHostnameId"`
+	EdgeHostnameDomain string `json:"edgeHostnameDomain,omitempty"`
+	DomainPrefix       string `json:"domainPrefix,omitempty"`
+	DomainSuffix       string `json:"domainSuffix,omitempty"`
+	ProductID          string `json:"productId,omitempty"`
+	Secure             bool   `json:"secure,omitempty"`
+	IPVersionBehavior  string `json:"ipVersionBehavior,omitempty"`
+}
+
+// PropertyBundle is a portable copy of a property and its versions
+type PropertyBundle struct {
+	Manifest  *BundleManifest
+	Rules     map[int]*Rules
+	Hostnames map[int]*Hostnames
+}
+
+// ExportOptions controls ExportProperty
+type ExportOptions struct {
+	// Versions to export, all versions when empty
+	Versions []int
+}
+
+// ExportProperty retrieves a property, its versions and the CP codes and
+// edge hostnames they reference
+func ExportProperty(property *Property, options *ExportOptions) (*PropertyBundle, error) {
+	if options == nil {
+		options = &ExportOptions{}
+	}
+
+	if err := property.GetProperty(); err != nil {
+		return nil, err
+	}
+
+	versions, err := property.GetVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &PropertyBundle{
+		Manifest: &BundleManifest{
+			FormatVersion: BundleFormatVersion,
+			ExportedAt:    time.Now().UTC(),
+			Property: BundleProperty{
+				AccountID:         property.AccountID,
+				ContractID:        property.ContractID,
+				GroupID:           property.GroupID,
+				PropertyID:        property.PropertyID,
+				PropertyName:      property.PropertyName,
+				ProductID:         property.ProductID,
+				RuleFormat:        property.RuleFormat,
+				LatestVersion:     property.LatestVersion,
+				StagingVersion:    property.StagingVersion,
+				ProductionVersion: property.ProductionVersion,
+			},
+		},
+		Rules:     map[int]*Rules{},
+		Hostnames: map[int]*Hostnames{},
+	}
+
+	wanted := map[int]bool{}
+	for _, version := range options.Versions {
+		wanted[version] = true
+	}
+
+	for _, version := range versions.Versions.Items {
+		if len(wanted) > 0 && !wanted[version.PropertyVersion] {
+			continue
+		}
+
+		rules := NewRules()
+		if err := rules.GetRulesForVersion(property, version.PropertyVersion); err != nil {
+			return nil, err
+		}
+
+		hostnames := NewHostnames()
+		hostnames.PropertyID = property.PropertyID
+		hostnames.ContractID = property.ContractID
+		hostnames.GroupID = property.GroupID
+		if err := hostnames.GetHostnames(version); err != nil {
+			return nil, err
+		}
+
+		if version.ProductID == "" {
+			version.ProductID = property.ProductID
+		}
+
+		bundle.Manifest.Versions = append(bundle.Manifest.Versions, &BundleVersion{
+			PropertyVersion:  version.PropertyVersion,
+			Note:             version.Note,
+			RuleFormat:       rules.RuleFormat,
+			ProductID:        version.ProductID,
+			UpdatedByUser:    version.UpdatedByUser,
+			UpdatedDate:      version.UpdatedDate,
+			StagingStatus:    version.StagingStatus,
+			ProductionStatus: version.ProductionStatus,
+		})
+		bundle.Rules[version.PropertyVersion] = rules
+		bundle.Hostnames[version.PropertyVersion] = hostnames
+	}
+
+	sort.Slice(bundle.Manifest.Versions, func(i, j int) bool {
+		return bundle.Manifest.Versions[i].PropertyVersion < bundle.Manifest.Versions[j].PropertyVersion
+	})
+
+	if err := bundle.exportReferences(property); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+func (bundle *PropertyBundle) exportReferences(property *Property) error {
+	cpCodeIDs, edgeHostnameIDs, err := bundle.References()
+	if err != nil {
+		return err
+	}
+
+	contract := NewContract(NewContracts())
+	contract.ContractID = property.ContractID
+	group := NewGroup(NewGroups())
+	group.GroupID = property.GroupID
+
+	if len(cpCodeIDs) > 0 {
+		cpcodes, err := GetCpCodes(contract, group)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range cpCodeIDs {
+			exported := &BundleCpCode{CpcodeID: fmt.Sprintf("cpc_%d", id)}
+			for _, cpcode := range cpcodes.CpCodes.Items {
+				if cpcode.ID() == id {
+					exported.CpcodeName = cpcode.CpcodeName
+					exported.ProductIDs = cpcode.ProductIDs
+				}
+			}
+			bundle.Manifest.CpCodes = append(bundle.Manifest.CpCodes, exported)
+		}
+	}
+
+	if len(edgeHostnameIDs) > 0 {
+		edgeHostnames, err := GetEdgeHostnames(contract, group, "")
+		if err != nil {
+			return err
+		}
+
+		for _, id := range edgeHostnameIDs {
+			exported := &BundleEdgeHostname{EdgeHostnameID: id}
+			for _, edgeHostname := range edgeHostnames.EdgeHostnames.Items {
+				if edgeHostname.EdgeHostnameID == id {
+					exported.EdgeHostnameDomain = edgeHostname.EdgeHostnameDomain
+					exported.DomainPrefix = edgeHostname.DomainPrefix
+					exported.DomainSuffix = edgeHostname.DomainSuffix
+					exported.ProductID = edgeHostname.ProductID
+					exported.Secure = edgeHostname.Secure
+					exported.IPVersionBehavior = edgeHostname.IPVersionBehavior
+				}
+			}
+			bundle.Manifest.EdgeHostnames = append(bundle.Manifest.EdgeHostnames, exported)
+		}
+	}
+
+	return nil
+}
+
+// References returns the CP code and edge hostname IDs used by every bundled version
+func (bundle *PropertyBundle) References() ([]int, []string, error) {
+	cpCodes := map[int]bool{}
+	edgeHostnames := map[string]bool{}
+	for _, version := range bundle.Manifest.Versions {
+		if rules := bundle.Rules[version.PropertyVersion]; rules != nil {
+			references, err := rules.FindCpCodeReferences()
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, reference := range references {
+				cpCodes[reference.CpCodeID] = true
+			}
+		}
+
+		if hostnames := bundle.Hostnames[version.PropertyVersion]; hostnames != nil {
+			for _, hostname := range hostnames.Hostnames.Items {
+				if hostname.EdgeHostnameID != "" {
+					edgeHostnames[hostname.EdgeHostnameID] = true
+				}
+			}
+		}
+	}
+
+	cpCodeIDs := make([]int, 0, len(cpCodes))
+	for id := range cpCodes {
+		cpCodeIDs = append(cpCodeIDs, id)
+	}
+	sort.Ints(cpCodeIDs)
+
+	edgeHostnameIDs := make([]string, 0, len(edgeHostnames))
+	for id := range edgeHostnames {
+		edgeHostnameIDs = append(edgeHostnameIDs, id)
+	}
+	sort.Strings(edgeHostnameIDs)
+
+	return cpCodeIDs, edgeHostnameIDs, nil
+}
+
+// WriteDir writes the bundle to a directory, creating it if needed
+func (bundle *PropertyBundle) WriteDir(dir string) error {
+	files, err := bundle.files()
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		name := filepath.Join(dir, filepath.FromSlash(file.name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(name, file.body, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteTar writes the bundle as a gzipped tarball
+func (bundle *PropertyBundle) WriteTar(w io.Writer) error {
+	files, err := bundle.files()
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		header := &tar.Header{
+			Name:    file.name,
+			Mode:    0644,
+			Size:    int64(len(file.body)),
+			ModTime: bundle.Manifest.ExportedAt,
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if _, err := tw.Write(file.body); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+type bundleFile struct {
+	name string
+	body []byte
+}
+
+func (bundle *PropertyBundle) files() ([]bundleFile, error) {
+	manifest, err := json.MarshalIndent(bundle.Manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	files := []bundleFile{{name: "manifest.json", body: manifest}}
+	for _, version := range bundle.Manifest.Versions {
+		for name, value := range map[string]interface{}{
+			"rules.json":     bundle.Rules[version.PropertyVersion],
+			"hostnames.json": bundle.Hostnames[version.PropertyVersion],
+		} {
+			body, err := json.MarshalIndent(value, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, bundleFile{name: path.Join("versions", strconv.Itoa(version.PropertyVersion), name), body: body})
+		}
+	}
+
+	sort.Slice(files[1:], func(i, j int) bool {
+		return files[i+1].name < files[j+1].name
+	})
+
+	return files, nil
+}
+
+// ReadPropertyBundle reads a bundle written by PropertyBundle.WriteDir
+func ReadPropertyBundle(dir string) (*PropertyBundle, error) {
+	return readPropertyBundle(func(name string) ([]byte, error) {
+		return ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	})
+}
+
+// ReadPropertyBundleTar reads a bundle written by PropertyBundle.WriteTar
+func ReadPropertyBundleTar(r io.Reader) (*PropertyBundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[strings.TrimPrefix(path.Clean(header.Name), "./")] = body
+	}
+
+	return readPropertyBundle(func(name string) ([]byte, error) {
+		body, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("bundle is missing %s", name)
+		}
+		return body, nil
+	})
+}
+
+func readPropertyBundle(read func(name string) ([]byte, error)) (*PropertyBundle, error) {
+	body, err := read("manifest.json")
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &PropertyBundle{
+		Manifest:  &BundleManifest{},
+		Rules:     map[int]*Rules{},
+		Hostnames: map[int]*Hostnames{},
+	}
+	if err := json.Unmarshal(body, bundle.Manifest); err != nil {
+		return nil, err
+	}
+
+	if bundle.Manifest.FormatVersion < 1 || bundle.Manifest.FormatVersion > BundleFormatVersion {
+		return nil, fmt.Errorf("unsupported bundle format version %d", bundle.Manifest.FormatVersion)
+	}
+
+	for _, version := range bundle.Manifest.Versions {
+		dir := path.Join("versions", strconv.Itoa(version.PropertyVersion))
+
+		body, err := read(path.Join(dir, "rules.json"))
+		if err != nil {
+			return nil, err
+		}
+		rules := NewRules()
+		if err := jsonhooks.Unmarshal(body, rules); err != nil {
+			return nil, err
+		}
+
+		body, err = read(path.Join(dir, "hostnames.json"))
+		if err != nil {
+			return nil, err
+		}
+		hostnames := NewHostnames()
+		if err := jsonhooks.Unmarshal(body, hostnames); err != nil {
+			return nil, err
+		}
+
+		bundle.Rules[version.PropertyVersion] = rules
+		bundle.Hostnames[version.PropertyVersion] = hostnames
+	}
+
+	return bundle, nil
+}
+
+// IDMapping translates CP code and edge hostname IDs between accounts
+//
+// CP codes are keyed by their integer ID, edge hostnames by their ehn_ ID.
+type IDMapping struct {
+	CpCodes       map[int]int
+	EdgeHostnames map[string]string
+}
+
+// Unmapped returns the bundle references missing from the mapping
+func (mapping *IDMapping) Unmapped(bundle *PropertyBundle) ([]int, []string, error) {
+	cpCodeIDs, edgeHostnameIDs, err := bundle.References()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cpCodes []int
+	for _, id := range cpCodeIDs {
+		if _, ok := mapping.CpCodes[id]; !ok {
+			cpCodes = append(cpCodes, id)
+		}
+	}
+
+	var edgeHostnames []string
+	for _, id := range edgeHostnameIDs {
+		if _, ok := mapping.EdgeHostnames[id]; !ok {
+			edgeHostnames = append(edgeHostnames, id)
+		}
+	}
+
+	return cpCodes, edgeHostnames, nil
+}
+
+// RemapRules replaces mapped CP code IDs in cpCode behaviors
+//
+// Unmapped CP codes are left as is.
+func (mapping *IDMapping) RemapRules(rules *Rules) error {
+	references, err := rules.FindCpCodeReferences()
+	if err != nil {
+		return err
+	}
+
+	for _, reference := range references {
+		if id, ok := mapping.CpCodes[reference.CpCodeID]; ok {
+			if err := setOption(reference.Behavior.Options, []string{"value", "id"}, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RemapHostnames replaces mapped edge hostname IDs
+//
+// Unmapped edge hostnames are left as is.
+func (mapping *IDMapping) RemapHostnames(hostnames *Hostnames) {
+	for _, hostname := range hostnames.Hostnames.Items {
+		if id, ok := mapping.EdgeHostnames[hostname.EdgeHostnameID]; ok {
+			hostname.EdgeHostnameID = id
+			hostname.CnameTo = ""
+		}
+	}
+}
+
+// ImportOptions controls ImportProperty
+type ImportOptions struct {
+	Contract     *Contract
+	Group        *Group
+	PropertyName string
+	ProductID    string
+	Mapping      *IDMapping
+
+	// AllowUnmapped keeps the original ID of references missing from
+	// Mapping, e.g. when importing into the same account
+	AllowUnmapped bool
+}
+
+// ImportProperty creates a new property from a bundle
+//
+// Every bundled version is recreated in order, with CP codes and edge
+// hostnames translated through options.Mapping. The new property name and
+// product default to those of the bundled property. options.Contract and
+// options.Group are required.
+func ImportProperty(bundle *PropertyBundle, options *ImportOptions) (*Property, error) {
+	if options == nil || options.Contract == nil || options.Group == nil {
+		return nil, fmt.Errorf("import options must set the contract and group")
+	}
+	if len(bundle.Manifest.Versions) == 0 {
+		return nil, fmt.Errorf("bundle has no versions")
+	}
+
+	mapping := options.Mapping
+	if mapping == nil {
+		mapping = &IDMapping{}
+	}
+
+	if !options.AllowUnmapped {
+		cpCodes, edgeHostnames, err := mapping.Unmapped(bundle)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(cpCodes) > 0 || len(edgeHostnames) > 0 {
+			return nil, fmt.Errorf("unmapped references: CP codes %v, edge hostnames %v", cpCodes, edgeHostnames)
+		}
+	}
+
+	property := NewProperty(NewProperties())
+	property.Contract = options.Contract
+	property.Group = options.Group
+	property.PropertyName = options.PropertyName
+	if property.PropertyName == "" {
+		property.PropertyName = bundle.Manifest.Property.PropertyName
+	}
+	property.ProductID = options.ProductID
+	if property.ProductID == "" {
+		property.ProductID = bundle.Manifest.Property.ProductID
+	}
+	property.RuleFormat = bundle.Manifest.Versions[0].RuleFormat
+
+	if err := property.Save(); err != nil {
+		return nil, err
+	}
+	property.ContractID = options.Contract.ContractID
+	property.GroupID = options.Group.GroupID
+
+	versions := NewVersions()
+	versions.PropertyID = property.PropertyID
+	versions.ContractID = property.ContractID
+	versions.GroupID = property.GroupID
+
+	previous := &Version{PropertyVersion: property.LatestVersion}
+	for key, bundled := range bundle.Manifest.Versions {
+		version := previous
+		if key > 0 {
+			version = versions.NewVersion(previous, false)
+			version.Note = bundled.Note
+			if err := version.Save(); err != nil {
+				return property, err
+			}
+		}
+
+		if err := importVersion(bundle, bundled, property, version.PropertyVersion, mapping); err != nil {
+			return property, err
+		}
+
+		previous = version
+	}
+
+	property.LatestVersion = previous.PropertyVersion
+
+	return property, nil
+}
+
+func importVersion(bundle *PropertyBundle, bundled *BundleVersion, property *Property, version int, mapping *IDMapping) error {
+	rules := copyRules(bundle.Rules[bundled.PropertyVersion])
+	rules.AccountID = ""
+	rules.ContractID = property.ContractID
+	rules.GroupID = property.GroupID
+	rules.PropertyID = property.PropertyID
+	rules.PropertyVersion = version
+	rules.Etag = ""
+	if err := mapping.RemapRules(rules); err != nil {
+		return err
+	}
+
+	var err error
+	if bundled.RuleFormat != "" && bundled.RuleFormat != "latest" {
+		err = rules.Freeze(bundled.RuleFormat)
+	} else {
+		err = rules.Save()
+	}
+	if err != nil {
+		return err
+	}
+
+	hostnames := copyHostnames(bundle.Hostnames[bundled.PropertyVersion])
+	hostnames.ContractID = property.ContractID
+	hostnames.GroupID = property.GroupID
+	hostnames.PropertyID = property.PropertyID
+	hostnames.PropertyVersion = version
+	mapping.RemapHostnames(hostnames)
+
+	return hostnames.Save()
+}
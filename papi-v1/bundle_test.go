@@ -0,0 +1,119 @@
+package papi
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBundle(t *testing.T) *PropertyBundle {
+	rules := newQueryTestRules(t)
+	rules.PropertyID = "prp_123"
+	rules.PropertyVersion = 2
+	rules.RuleFormat = "v2018-02-27"
+
+	hostnames := NewHostnames()
+	hostnames.PropertyID = "prp_123"
+	hostnames.PropertyVersion = 2
+	hostname := hostnames.NewHostname()
+	hostname.CnameFrom = "www.example.com"
+	hostname.EdgeHostnameID = "ehn_1"
+
+	return &PropertyBundle{
+		Manifest: &BundleManifest{
+			FormatVersion: BundleFormatVersion,
+			Property: BundleProperty{
+				PropertyID:   "prp_123",
+				PropertyName: "example.com",
+				ProductID:    "prd_Site_Accel",
+			},
+			Versions: []*BundleVersion{
+				{PropertyVersion: 2, RuleFormat: "v2018-02-27"},
+			},
+		},
+		Rules:     map[int]*Rules{2: rules},
+		Hostnames: map[int]*Hostnames{2: hostnames},
+	}
+}
+
+func TestPropertyBundle_WriteDir(t *testing.T) {
+	bundle := newTestBundle(t)
+	dir := t.TempDir()
+
+	assert.NoError(t, bundle.WriteDir(dir))
+
+	read, err := ReadPropertyBundle(dir)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "example.com", read.Manifest.Property.PropertyName)
+		assert.Empty(t, DiffRules(bundle.Rules[2], read.Rules[2]))
+		assert.Empty(t, DiffHostnames(bundle.Hostnames[2], read.Hostnames[2]))
+		assert.Equal(t, "v2018-02-27", read.Rules[2].RuleFormat)
+	}
+}
+
+func TestPropertyBundle_WriteTar(t *testing.T) {
+	bundle := newTestBundle(t)
+	buf := &bytes.Buffer{}
+
+	assert.NoError(t, bundle.WriteTar(buf))
+
+	read, err := ReadPropertyBundleTar(buf)
+	if assert.NoError(t, err) {
+		assert.Len(t, read.Manifest.Versions, 1)
+		assert.Empty(t, DiffRules(bundle.Rules[2], read.Rules[2]))
+		assert.Empty(t, DiffHostnames(bundle.Hostnames[2], read.Hostnames[2]))
+	}
+}
+
+func TestReadPropertyBundle_UnsupportedFormat(t *testing.T) {
+	bundle := newTestBundle(t)
+	bundle.Manifest.FormatVersion = BundleFormatVersion + 1
+	dir := t.TempDir()
+
+	assert.NoError(t, bundle.WriteDir(dir))
+
+	_, err := ReadPropertyBundle(dir)
+	assert.Error(t, err)
+}
+
+func TestIDMapping(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	cpCodes, edgeHostnames, err := bundle.References()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{12345}, cpCodes)
+	assert.Equal(t, []string{"ehn_1"}, edgeHostnames)
+
+	mapping := &IDMapping{CpCodes: map[int]int{12345: 67890}}
+	cpCodes, edgeHostnames, err = mapping.Unmapped(bundle)
+	assert.NoError(t, err)
+	assert.Empty(t, cpCodes)
+	assert.Equal(t, []string{"ehn_1"}, edgeHostnames)
+
+	mapping.EdgeHostnames = map[string]string{"ehn_1": "ehn_2"}
+	assert.NoError(t, mapping.RemapRules(bundle.Rules[2]))
+	mapping.RemapHostnames(bundle.Hostnames[2])
+
+	references, err := bundle.Rules[2].FindCpCodeReferences()
+	if assert.NoError(t, err) && assert.Len(t, references, 1) {
+		assert.Equal(t, 67890, references[0].CpCodeID)
+	}
+	assert.Equal(t, "ehn_2", bundle.Hostnames[2].Hostnames.Items[0].EdgeHostnameID)
+}
+
+func TestImportProperty_MissingOptions(t *testing.T) {
+	bundle := newTestBundle(t)
+	contract := NewContract(NewContracts())
+	group := NewGroup(NewGroups())
+
+	for _, options := range []*ImportOptions{
+		nil,
+		{Group: group},
+		{Contract: contract},
+	} {
+		property, err := ImportProperty(bundle, options)
+		assert.Nil(t, property)
+		assert.EqualError(t, err, "import options must set the contract and group")
+	}
+}
@@ -0,0 +1,229 @@
+package papi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NoncomplianceReasonValue is used to create an "enum" of possible
+// ActivationComplianceRecord.NoncomplianceReason values
+type NoncomplianceReasonValue string
+
+const (
+	// NoncomplianceReasonNone ActivationComplianceRecord.NoncomplianceReason value NONE,
+	// the change was peer reviewed, unit tested and the customer notified
+	NoncomplianceReasonNone NoncomplianceReasonValue = "NONE"
+	// NoncomplianceReasonOther ActivationComplianceRecord.NoncomplianceReason value OTHER
+	NoncomplianceReasonOther NoncomplianceReasonValue = "OTHER"
+	// NoncomplianceReasonNoProductionTraffic ActivationComplianceRecord.NoncomplianceReason value NO_PRODUCTION_TRAFFIC
+	NoncomplianceReasonNoProductionTraffic NoncomplianceReasonValue = "NO_PRODUCTION_TRAFFIC"
+	// NoncomplianceReasonEmergency ActivationComplianceRecord.NoncomplianceReason value EMERGENCY
+	NoncomplianceReasonEmergency NoncomplianceReasonValue = "EMERGENCY"
+)
+
+// ActivationComplianceRecord holds the change-management metadata of an
+// activation
+//
+// Which fields are required depends on NoncomplianceReason, see Validate().
+// TicketID is optional for all reasons; when set, it is also added to the
+// activation note.
+type ActivationComplianceRecord struct {
+	NoncomplianceReason      NoncomplianceReasonValue `json:"noncomplianceReason,omitempty"`
+	OtherNoncomplianceReason string                   `json:"otherNoncomplianceReason,omitempty"`
+	PeerReviewedBy           string                   `json:"peerReviewedBy,omitempty"`
+	CustomerEmail            string                   `json:"customerEmail,omitempty"`
+	UnitTested               bool                     `json:"unitTested,omitempty"`
+	TicketID                 string                   `json:"ticketId,omitempty"`
+}
+
+// NewPeerReviewedComplianceRecord creates a compliance record for a change
+// that was peer reviewed and unit tested, and of which the customer was
+// notified
+func NewPeerReviewedComplianceRecord(peerReviewedBy string, customerEmail string, ticketID string) *ActivationComplianceRecord {
+	return &ActivationComplianceRecord{
+		NoncomplianceReason: NoncomplianceReasonNone,
+		PeerReviewedBy:      peerReviewedBy,
+		CustomerEmail:       customerEmail,
+		UnitTested:          true,
+		TicketID:            ticketID,
+	}
+}
+
+// NewEmergencyComplianceRecord creates a compliance record for an emergency
+// change, such as a rollback
+func NewEmergencyComplianceRecord(ticketID string) *ActivationComplianceRecord {
+	return &ActivationComplianceRecord{
+		NoncomplianceReason: NoncomplianceReasonEmergency,
+		TicketID:            ticketID,
+	}
+}
+
+// NewNoProductionTrafficComplianceRecord creates a compliance record for a
+// property not serving production traffic yet
+func NewNoProductionTrafficComplianceRecord(ticketID string) *ActivationComplianceRecord {
+	return &ActivationComplianceRecord{
+		NoncomplianceReason: NoncomplianceReasonNoProductionTraffic,
+		TicketID:            ticketID,
+	}
+}
+
+// NewOtherComplianceRecord creates a compliance record for a change skipping
+// the review process for another reason
+func NewOtherComplianceRecord(reason string, ticketID string) *ActivationComplianceRecord {
+	return &ActivationComplianceRecord{
+		NoncomplianceReason:      NoncomplianceReasonOther,
+		OtherNoncomplianceReason: reason,
+		TicketID:                 ticketID,
+	}
+}
+
+// Validate checks that the fields required by the noncompliance reason are set
+//
+// NONE requires PeerReviewedBy, a valid CustomerEmail and UnitTested, OTHER
+// requires OtherNoncomplianceReason. Fields belonging to another reason are
+// rejected.
+func (record *ActivationComplianceRecord) Validate() error {
+	var problems []string
+
+	switch record.NoncomplianceReason {
+	case NoncomplianceReasonNone:
+		if record.PeerReviewedBy == "" {
+			problems = append(problems, "peerReviewedBy is required")
+		}
+		if record.CustomerEmail == "" {
+			problems = append(problems, "customerEmail is required")
+		} else if !validEmail(record.CustomerEmail) {
+			problems = append(problems, fmt.Sprintf("customerEmail %q is not a valid email address", record.CustomerEmail))
+		}
+		if !record.UnitTested {
+			problems = append(problems, "the change must be unit tested")
+		}
+		if record.OtherNoncomplianceReason != "" {
+			problems = append(problems, "otherNoncomplianceReason is only allowed with OTHER")
+		}
+	case NoncomplianceReasonOther:
+		if strings.TrimSpace(record.OtherNoncomplianceReason) == "" {
+			problems = append(problems, "otherNoncomplianceReason is required")
+		}
+	case NoncomplianceReasonNoProductionTraffic, NoncomplianceReasonEmergency:
+		if record.OtherNoncomplianceReason != "" {
+			problems = append(problems, "otherNoncomplianceReason is only allowed with OTHER")
+		}
+	case "":
+		problems = append(problems, "noncomplianceReason is required")
+	default:
+		problems = append(problems, fmt.Sprintf("unknown noncomplianceReason %s", record.NoncomplianceReason))
+	}
+
+	if record.NoncomplianceReason != NoncomplianceReasonNone &&
+		(record.PeerReviewedBy != "" || record.CustomerEmail != "" || record.UnitTested) {
+		problems = append(problems, "peerReviewedBy, customerEmail and unitTested are only allowed with NONE")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid compliance record: %s", strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+func validEmail(email string) bool {
+	at := strings.LastIndex(email, "@")
+	return at > 0 && at < len(email)-1 && !strings.ContainsAny(email, " \t,;")
+}
+
+// ActivationPolicy decides whether an activation on network may be submitted
+// with a compliance record, which may be nil
+type ActivationPolicy func(network NetworkValue, record *ActivationComplianceRecord) error
+
+// ActivationPolicies are checked, in order, before submitting property and
+// include activations
+//
+// By default, production activations require a compliance record. Add
+// RequireTicketID() or custom policies to enforce stricter rules.
+var ActivationPolicies = []ActivationPolicy{RequireComplianceRecord}
+
+// ErrComplianceRecordRequired is returned by RequireComplianceRecord
+var ErrComplianceRecordRequired = errors.New("production activations require a compliance record")
+
+// RequireComplianceRecord is an ActivationPolicy refusing production
+// activations without a compliance record
+func RequireComplianceRecord(network NetworkValue, record *ActivationComplianceRecord) error {
+	if network == NetworkProduction && record == nil {
+		return ErrComplianceRecordRequired
+	}
+
+	return nil
+}
+
+// RequireTicketID is an ActivationPolicy refusing production activations
+// whose compliance record has no ticket ID
+func RequireTicketID(network NetworkValue, record *ActivationComplianceRecord) error {
+	if network == NetworkProduction && (record == nil || record.TicketID == "") {
+		return errors.New("production activations require a ticket ID")
+	}
+
+	return nil
+}
+
+// RequireNoncomplianceReasons returns an ActivationPolicy refusing
+// production activations with any other noncompliance reason
+func RequireNoncomplianceReasons(allowed ...NoncomplianceReasonValue) ActivationPolicy {
+	return func(network NetworkValue, record *ActivationComplianceRecord) error {
+		if network != NetworkProduction || record == nil {
+			return nil
+		}
+
+		for _, reason := range allowed {
+			if record.NoncomplianceReason == reason {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("noncompliance reason %s is not allowed for production activations", record.NoncomplianceReason)
+	}
+}
+
+// checkCompliance validates the compliance record of an activation and
+// checks it against ActivationPolicies
+//
+// Staging activations without a compliance record get a
+// NO_PRODUCTION_TRAFFIC one. The ticket ID of the record is prepended to
+// the note unless already mentioned.
+func checkCompliance(network NetworkValue, record **ActivationComplianceRecord, note *string) error {
+	if *record == nil && network == NetworkStaging {
+		*record = NewNoProductionTrafficComplianceRecord("")
+	}
+
+	if *record != nil {
+		if err := (*record).Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, policy := range ActivationPolicies {
+		if err := policy(network, *record); err != nil {
+			return fmt.Errorf("activation refused: %s", err)
+		}
+	}
+
+	if *record != nil {
+		*note = noteWithTicket(*note, (*record).TicketID)
+	}
+
+	return nil
+}
+
+// noteWithTicket prepends "[ticketID] " to note, unless it mentions it
+func noteWithTicket(note string, ticketID string) string {
+	if ticketID == "" || strings.Contains(note, ticketID) {
+		return note
+	}
+
+	if note == "" {
+		return ticketID
+	}
+
+	return fmt.Sprintf("[%s] %s", ticketID, note)
+}
@@ -0,0 +1,78 @@
+package papi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestActivationComplianceRecord_Validate(t *testing.T) {
+	tests := map[string]struct {
+		record *ActivationComplianceRecord
+		valid  bool
+	}{
+		"peer reviewed":            {NewPeerReviewedComplianceRecord("jdoe", "customer@example.com", "CHG-1"), true},
+		"peer reviewed, no email":  {NewPeerReviewedComplianceRecord("jdoe", "", ""), false},
+		"peer reviewed, bad email": {NewPeerReviewedComplianceRecord("jdoe", "customer", ""), false},
+		"not unit tested":          {&ActivationComplianceRecord{NoncomplianceReason: NoncomplianceReasonNone, PeerReviewedBy: "jdoe", CustomerEmail: "customer@example.com"}, false},
+		"emergency":                {NewEmergencyComplianceRecord(""), true},
+		"emergency, reviewer":      {&ActivationComplianceRecord{NoncomplianceReason: NoncomplianceReasonEmergency, PeerReviewedBy: "jdoe"}, false},
+		"no production traffic":    {NewNoProductionTrafficComplianceRecord("CHG-1"), true},
+		"other":                    {NewOtherComplianceRecord("vendor outage", ""), true},
+		"other, no reason":         {NewOtherComplianceRecord(" ", ""), false},
+		"unknown":                  {&ActivationComplianceRecord{NoncomplianceReason: "LATE"}, false},
+		"empty":                    {&ActivationComplianceRecord{}, false},
+	}
+
+	for name, test := range tests {
+		err := test.record.Validate()
+		if test.valid {
+			assert.NoError(t, err, name)
+		} else {
+			assert.Error(t, err, name)
+		}
+	}
+}
+
+func TestCheckCompliance(t *testing.T) {
+	defer func(policies []ActivationPolicy) { ActivationPolicies = policies }(ActivationPolicies)
+
+	var record *ActivationComplianceRecord
+	note := "Release 42"
+	assert.NoError(t, checkCompliance(NetworkStaging, &record, &note))
+	assert.Equal(t, NoncomplianceReasonNoProductionTraffic, record.NoncomplianceReason)
+
+	record = nil
+	assert.Equal(t, "activation refused: production activations require a compliance record", checkCompliance(NetworkProduction, &record, &note).Error())
+
+	record = NewPeerReviewedComplianceRecord("jdoe", "customer@example.com", "CHG-1")
+	assert.NoError(t, checkCompliance(NetworkProduction, &record, &note))
+	assert.Equal(t, "[CHG-1] Release 42", note)
+	assert.NoError(t, checkCompliance(NetworkProduction, &record, &note))
+	assert.Equal(t, "[CHG-1] Release 42", note)
+
+	ActivationPolicies = append(ActivationPolicies, RequireTicketID, RequireNoncomplianceReasons(NoncomplianceReasonNone))
+
+	record = NewEmergencyComplianceRecord("")
+	assert.Error(t, checkCompliance(NetworkProduction, &record, &note))
+
+	record = NewEmergencyComplianceRecord("INC-7")
+	assert.Error(t, checkCompliance(NetworkProduction, &record, &note))
+	assert.NoError(t, checkCompliance(NetworkStaging, &record, &note))
+}
+
+func TestActivationOrchestrator_Run_Refused(t *testing.T) {
+	defer gock.Off()
+
+	Init(config)
+
+	orchestrator := NewActivationOrchestrator(newOrchestratorTestProperty(), 2)
+	orchestrator.ComplianceRecord = NewPeerReviewedComplianceRecord("jdoe", "", "")
+
+	result, err := orchestrator.Run(context.Background())
+	assert.Error(t, err)
+	assert.Empty(t, result.Activations)
+	assert.False(t, gock.HasUnmatchedRequest())
+}
@@ -0,0 +1,211 @@
+package papi
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CpCodeUsage lists the rule tree references to a single CP code
+//
+// CpCode is nil when the CP code is referenced by a rule tree but is not
+// part of the CP codes the report was built with.
+type CpCodeUsage struct {
+	CpCodeID   int
+	CpCode     *CpCode
+	References []*SearchHit
+}
+
+// Orphaned reports whether no indexed rule tree references the CP code
+func (usage *CpCodeUsage) Orphaned() bool {
+	return len(usage.References) == 0
+}
+
+// PropertyIDs returns the IDs of the properties referencing the CP code
+func (usage *CpCodeUsage) PropertyIDs() []string {
+	seen := map[string]bool{}
+	var ids []string
+	for _, reference := range usage.References {
+		if !seen[reference.PropertyID] {
+			seen[reference.PropertyID] = true
+			ids = append(ids, reference.PropertyID)
+		}
+	}
+
+	return ids
+}
+
+// CpCodeUsageReport cross-references CP codes with the rule trees using
+// them, to reconcile billing buckets with property configurations
+//
+//	cpcodes := papi.NewCpCodes(contract, group)
+//	err := cpcodes.GetCpCodes()
+//	report, err := papi.NewCpCodeUsageReport(cpcodes.CpCodes.Items, index)
+//	for _, usage := range report.Orphaned() { ... }
+type CpCodeUsageReport struct {
+	// Usages has one entry per CP code, ordered by CP code ID
+	Usages []*CpCodeUsage
+	// Unknown lists CP codes referenced by rule trees that were not part
+	// of the CP codes the report was built with
+	Unknown []*CpCodeUsage
+}
+
+// NewCpCodeUsageReport builds a report for cpcodes from the rule trees
+// stored in index
+func NewCpCodeUsageReport(cpcodes []*CpCode, index *SearchIndex) (*CpCodeUsageReport, error) {
+	usages := map[int]*CpCodeUsage{}
+	for _, cpcode := range cpcodes {
+		usages[cpcode.ID()] = &CpCodeUsage{CpCodeID: cpcode.ID(), CpCode: cpcode}
+	}
+
+	references := map[*Behavior]int{}
+	hits, err := index.find(func(rules *Rules) ([]*BehaviorMatch, error) {
+		found, err := rules.FindCpCodeReferences()
+		if err != nil {
+			return nil, err
+		}
+
+		var matches []*BehaviorMatch
+		for _, reference := range found {
+			references[reference.Behavior] = reference.CpCodeID
+			matches = append(matches, &BehaviorMatch{Behavior: reference.Behavior, Rule: reference.Rule, Path: reference.Path})
+		}
+
+		return matches, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CpCodeUsageReport{}
+	unknown := map[int]*CpCodeUsage{}
+	for _, hit := range hits {
+		id := references[hit.Behavior]
+		usage, ok := usages[id]
+		if !ok {
+			if usage, ok = unknown[id]; !ok {
+				usage = &CpCodeUsage{CpCodeID: id}
+				unknown[id] = usage
+				report.Unknown = append(report.Unknown, usage)
+			}
+		}
+		usage.References = append(usage.References, hit)
+	}
+
+	for _, usage := range usages {
+		report.Usages = append(report.Usages, usage)
+	}
+	sort.Slice(report.Usages, func(i, j int) bool { return report.Usages[i].CpCodeID < report.Usages[j].CpCodeID })
+	sort.Slice(report.Unknown, func(i, j int) bool { return report.Unknown[i].CpCodeID < report.Unknown[j].CpCodeID })
+
+	return report, nil
+}
+
+// Orphaned returns the CP codes no indexed rule tree references
+func (report *CpCodeUsageReport) Orphaned() []*CpCodeUsage {
+	var orphaned []*CpCodeUsage
+	for _, usage := range report.Usages {
+		if usage.Orphaned() {
+			orphaned = append(orphaned, usage)
+		}
+	}
+
+	return orphaned
+}
+
+// Usage returns the usage of a CP code, or nil if it is not in the report
+func (report *CpCodeUsageReport) Usage(cpCodeID int) *CpCodeUsage {
+	for _, usages := range [][]*CpCodeUsage{report.Usages, report.Unknown} {
+		for _, usage := range usages {
+			if usage.CpCodeID == cpCodeID {
+				return usage
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteCSV writes the report with one row per reference, and one row for
+// each orphaned CP code
+//
+// The status column is one of "referenced", "orphaned" or "unknown".
+func (report *CpCodeUsageReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"cpCodeId", "cpCodeName", "status", "propertyId", "propertyName", "propertyVersion", "labels", "path"})
+	if err != nil {
+		return err
+	}
+
+	write := func(usage *CpCodeUsage, status string) error {
+		name := ""
+		if usage.CpCode != nil {
+			name = usage.CpCode.CpcodeName
+		}
+		id := strconv.Itoa(usage.CpCodeID)
+
+		if usage.Orphaned() {
+			return writer.Write([]string{id, name, status, "", "", "", "", ""})
+		}
+
+		for _, hit := range usage.References {
+			err := writer.Write([]string{
+				id,
+				name,
+				status,
+				hit.PropertyID,
+				hit.PropertyName,
+				strconv.Itoa(hit.PropertyVersion),
+				strings.Join(hit.Labels, " "),
+				hit.Path,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, usage := range report.Usages {
+		status := "referenced"
+		if usage.Orphaned() {
+			status = "orphaned"
+		}
+		if err := write(usage, status); err != nil {
+			return err
+		}
+	}
+
+	for _, usage := range report.Unknown {
+		if err := write(usage, "unknown"); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// String returns a summary of the report, one line per CP code
+func (report *CpCodeUsageReport) String() string {
+	var lines []string
+	for _, usage := range report.Usages {
+		name := usage.CpCode.CpcodeName
+		if usage.Orphaned() {
+			lines = append(lines, fmt.Sprintf("%d %s: orphaned", usage.CpCodeID, name))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%d %s: %d references in %d properties", usage.CpCodeID, name, len(usage.References), len(usage.PropertyIDs())))
+	}
+
+	for _, usage := range report.Unknown {
+		lines = append(lines, fmt.Sprintf("%d: unknown CP code, %d references in %d properties", usage.CpCodeID, len(usage.References), len(usage.PropertyIDs())))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,173 @@
+package papi
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/jsonhooks-v1"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func newCpCodeTestRules(t *testing.T, cpCodes ...int) *Rules {
+	var children []string
+	for i, id := range cpCodes {
+		children = append(children, fmt.Sprintf(`{"name": "Rule %d", "behaviors": [{"name": "cpCode", "options": {"value": {"id": %d}}}]}`, i+1, id))
+	}
+
+	rules := NewRules()
+	err := jsonhooks.Unmarshal([]byte(fmt.Sprintf(`{"rules": {"name": "default", "children": [%s]}}`, strings.Join(children, ","))), rules)
+	assert.NoError(t, err)
+
+	return rules
+}
+
+func newCpCodeTestIndex(t *testing.T) *SearchIndex {
+	return &SearchIndex{Properties: map[string]*IndexedProperty{
+		"prp_1": {
+			PropertyID:   "prp_1",
+			PropertyName: "www.example.com",
+			Versions: []*IndexedVersion{
+				{PropertyVersion: 3, Labels: []string{IndexLabelLatest, IndexLabelProduction}, Rules: newCpCodeTestRules(t, 100, 300)},
+			},
+		},
+		"prp_2": {
+			PropertyID:   "prp_2",
+			PropertyName: "static.example.com",
+			Versions: []*IndexedVersion{
+				{PropertyVersion: 1, Labels: []string{IndexLabelLatest}, Rules: newCpCodeTestRules(t, 100)},
+			},
+		},
+	}}
+}
+
+func newCpCodeTestCpCodes() *CpCodes {
+	contract := NewContract(NewContracts())
+	contract.ContractID = "ctr_1"
+	cpcodes := NewCpCodes(contract, NewGroup(NewGroups()))
+	for _, id := range []int{100, 200} {
+		cpcode := cpcodes.NewCpCode()
+		cpcode.CpcodeID = fmt.Sprintf("cpc_%d", id)
+		cpcode.CpcodeName = fmt.Sprintf("bucket %d", id)
+		cpcodes.AddCpCode(cpcode)
+	}
+
+	return cpcodes
+}
+
+func TestNewCpCodeUsageReport(t *testing.T) {
+	report, err := NewCpCodeUsageReport(newCpCodeTestCpCodes().CpCodes.Items, newCpCodeTestIndex(t))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.Len(t, report.Usages, 2) {
+		assert.Equal(t, 100, report.Usages[0].CpCodeID)
+		assert.Equal(t, []string{"prp_1", "prp_2"}, report.Usages[0].PropertyIDs())
+		assert.Equal(t, "/Rule 1/cpCode", report.Usages[0].References[0].Path)
+		assert.True(t, report.Usages[1].Orphaned())
+	}
+
+	orphaned := report.Orphaned()
+	if assert.Len(t, orphaned, 1) {
+		assert.Equal(t, 200, orphaned[0].CpCodeID)
+	}
+
+	if assert.Len(t, report.Unknown, 1) {
+		assert.Equal(t, 300, report.Unknown[0].CpCodeID)
+		assert.Nil(t, report.Unknown[0].CpCode)
+	}
+	assert.Equal(t, report.Unknown[0], report.Usage(300))
+	assert.Nil(t, report.Usage(400))
+
+	assert.Equal(t, "100 bucket 100: 2 references in 2 properties\n200 bucket 200: orphaned\n300: unknown CP code, 1 references in 1 properties", report.String())
+}
+
+func TestCpCodeUsageReport_WriteCSV(t *testing.T) {
+	report, err := NewCpCodeUsageReport(newCpCodeTestCpCodes().CpCodes.Items, newCpCodeTestIndex(t))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, report.WriteCSV(buf))
+	assert.Equal(t, `cpCodeId,cpCodeName,status,propertyId,propertyName,propertyVersion,labels,path
+100,bucket 100,referenced,prp_1,www.example.com,3,latest production,/Rule 1/cpCode
+100,bucket 100,referenced,prp_2,static.example.com,1,latest,/Rule 1/cpCode
+200,bucket 200,orphaned,,,,,
+300,,unknown,prp_1,www.example.com,3,latest production,/Rule 2/cpCode
+`, buf.String())
+}
+
+func TestCpCode_Rename(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Get("/cprg/v1/cpcodes/100$").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"cpcodeId": 100, "cpcodeName": "old", "purgeable": true, "contracts": [{"contractId": "ctr_1", "status": "ongoing"}]}`)
+
+	gock.New(orchestratorTestURL).
+		Put("/cprg/v1/cpcodes/100$").
+		JSON(map[string]interface{}{"cpcodeId": 100, "cpcodeName": "new", "purgeable": true, "contracts": []map[string]string{{"contractId": "ctr_1", "status": "ongoing"}}}).
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"cpcodeId": 100, "cpcodeName": "new"}`)
+
+	Init(config)
+
+	cpcode := newCpCodeTestCpCodes().CpCodes.Items[0]
+	assert.NoError(t, cpcode.Rename("new"))
+	assert.True(t, gock.IsDone())
+	assert.Equal(t, "new", cpcode.CpcodeName)
+}
+
+func TestCpCode_AddToReportingGroup(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Get("/cprg/v1/reporting-groups/7$").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"reportingGroupId": 7, "reportingGroupName": "finance", "contracts": [{"contractId": "ctr_1", "cpcodes": [{"cpcodeId": 200, "cpcodeName": "bucket 200"}]}]}`)
+
+	gock.New(orchestratorTestURL).
+		Put("/cprg/v1/reporting-groups/7$").
+		JSON(map[string]interface{}{
+			"reportingGroupId":   7,
+			"reportingGroupName": "finance",
+			"contracts": []map[string]interface{}{{
+				"contractId": "ctr_1",
+				"cpcodes": []map[string]interface{}{
+					{"cpcodeId": 200, "cpcodeName": "bucket 200"},
+					{"cpcodeId": 100, "cpcodeName": "bucket 100"},
+				},
+			}},
+		}).
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"reportingGroupId": 7, "reportingGroupName": "finance"}`)
+
+	Init(config)
+
+	cpcode := newCpCodeTestCpCodes().CpCodes.Items[0]
+	assert.NoError(t, cpcode.AddToReportingGroup(7))
+	assert.True(t, gock.IsDone())
+}
+
+func TestReportingGroup_RemoveCpCode(t *testing.T) {
+	cpcodes := newCpCodeTestCpCodes()
+	reportingGroup := NewReportingGroup()
+	reportingGroup.AddCpCode("ctr_1", cpcodes.CpCodes.Items[0])
+	reportingGroup.AddCpCode("ctr_1", cpcodes.CpCodes.Items[0])
+	reportingGroup.AddCpCode("ctr_2", cpcodes.CpCodes.Items[1])
+
+	assert.Len(t, reportingGroup.Contracts, 2)
+	assert.Len(t, reportingGroup.Contracts[0].CpCodes, 1)
+	assert.True(t, reportingGroup.RemoveCpCode(cpcodes.CpCodes.Items[0]))
+	assert.False(t, reportingGroup.RemoveCpCode(cpcodes.CpCodes.Items[0]))
+	assert.Len(t, reportingGroup.Contracts[0].CpCodes, 0)
+}
@@ -299,3 +299,279 @@ func (cpcode *CpCode) Save() error {
 
 	return nil
 }
+
+// CpCodeReference is a CP code used by a behavior within a rule tree
+type CpCodeReference struct {
+	CpCodeID int
+	Path     string
+	Rule     *Rule
+	Behavior *Behavior
+}
+
+// FindCpCodeReferences returns every CP code referenced by a cpCode behavior
+//
+// See: Rules.SelectBehaviors()
+func (rules *Rules) FindCpCodeReferences() ([]*CpCodeReference, error) {
+	matches, err := rules.SelectBehaviors("/**/cpCode[value.id]")
+	if err != nil {
+		return nil, err
+	}
+
+	var references []*CpCodeReference
+	for _, match := range matches {
+		value, _ := lookupOption(match.Behavior.Options, []string{"value", "id"})
+		id, ok := optionInt(value)
+		if !ok {
+			continue
+		}
+
+		references = append(references, &CpCodeReference{
+			CpCodeID: id,
+			Path:     match.Path,
+			Rule:     match.Rule,
+			Behavior: match.Behavior,
+		})
+	}
+
+	return references, nil
+}
+
+// optionInt converts a numeric option value, as decoded from JSON or set in
+// code, to an int
+func optionInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case string:
+		id, err := strconv.Atoi(strings.TrimPrefix(v, "cpc_"))
+		return id, err == nil
+	}
+
+	return 0, false
+}
+
+// Rename changes the name of a CP code
+//
+// PAPI can not update CP codes, so the CP code is updated through the CP
+// Codes and Reporting Groups API, leaving its other settings unchanged.
+//
+// API Docs: https://developer.akamai.com/api/core_features/cp_codes_reporting_groups/v1.html#putcpcode
+// Endpoint: PUT /cprg/v1/cpcodes/{cpcodeId}
+func (cpcode *CpCode) Rename(name string) error {
+	req, err := client.NewRequest(
+		Config,
+		"GET",
+		fmt.Sprintf("/cprg/v1/cpcodes/%d", cpcode.ID()),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	var body client.JSONBody
+	if err = client.BodyJSON(res, &body); err != nil {
+		return err
+	}
+
+	body["cpcodeName"] = name
+	req, err = client.NewJSONRequest(
+		Config,
+		"PUT",
+		fmt.Sprintf("/cprg/v1/cpcodes/%d", cpcode.ID()),
+		body,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err = client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	cpcode.CpcodeName = name
+
+	return nil
+}
+
+// ReportingGroup represents a CP code reporting group
+//
+// API Docs: https://developer.akamai.com/api/core_features/cp_codes_reporting_groups/v1.html#reportinggroup
+type ReportingGroup struct {
+	client.Resource
+	ReportingGroupID   int                       `json:"reportingGroupId,omitempty"`
+	ReportingGroupName string                    `json:"reportingGroupName"`
+	Contracts          []*ReportingGroupContract `json:"contracts"`
+	AccessGroup        *ReportingGroupAccess     `json:"accessGroup,omitempty"`
+}
+
+// ReportingGroupContract lists the CP codes of a reporting group within a contract
+type ReportingGroupContract struct {
+	ContractID string                  `json:"contractId"`
+	CpCodes    []*ReportingGroupCpCode `json:"cpcodes"`
+}
+
+// ReportingGroupCpCode is a CP code member of a reporting group
+type ReportingGroupCpCode struct {
+	CpcodeID   int    `json:"cpcodeId"`
+	CpcodeName string `json:"cpcodeName,omitempty"`
+}
+
+// ReportingGroupAccess is the access group of a reporting group
+type ReportingGroupAccess struct {
+	GroupID    int    `json:"groupId"`
+	ContractID string `json:"contractId"`
+}
+
+// NewReportingGroup creates a new *ReportingGroup
+func NewReportingGroup() *ReportingGroup {
+	reportingGroup := &ReportingGroup{}
+	reportingGroup.Init()
+	return reportingGroup
+}
+
+// GetReportingGroup populates the *ReportingGroup with it's data
+//
+// API Docs: https://developer.akamai.com/api/core_features/cp_codes_reporting_groups/v1.html#getreportinggroup
+// Endpoint: GET /cprg/v1/reporting-groups/{reportingGroupId}
+func (reportingGroup *ReportingGroup) GetReportingGroup() error {
+	req, err := client.NewRequest(
+		Config,
+		"GET",
+		fmt.Sprintf("/cprg/v1/reporting-groups/%d", reportingGroup.ReportingGroupID),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	if err = client.BodyJSON(res, reportingGroup); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddCpCode adds a CP code to the reporting group for a contract
+func (reportingGroup *ReportingGroup) AddCpCode(contractID string, cpcode *CpCode) {
+	var contract *ReportingGroupContract
+	for _, c := range reportingGroup.Contracts {
+		if c.ContractID == contractID {
+			contract = c
+		}
+	}
+
+	if contract == nil {
+		contract = &ReportingGroupContract{ContractID: contractID}
+		reportingGroup.Contracts = append(reportingGroup.Contracts, contract)
+	}
+
+	for _, member := range contract.CpCodes {
+		if member.CpcodeID == cpcode.ID() {
+			return
+		}
+	}
+
+	contract.CpCodes = append(contract.CpCodes, &ReportingGroupCpCode{CpcodeID: cpcode.ID(), CpcodeName: cpcode.CpcodeName})
+}
+
+// RemoveCpCode removes a CP code from every contract of the reporting
+// group, reporting whether it was a member
+func (reportingGroup *ReportingGroup) RemoveCpCode(cpcode *CpCode) bool {
+	removed := false
+	for _, contract := range reportingGroup.Contracts {
+		cpcodes := contract.CpCodes[:0]
+		for _, member := range contract.CpCodes {
+			if member.CpcodeID == cpcode.ID() {
+				removed = true
+				continue
+			}
+			cpcodes = append(cpcodes, member)
+		}
+		contract.CpCodes = cpcodes
+	}
+
+	return removed
+}
+
+// Save creates the reporting group, or updates it if it has an ID
+//
+// API Docs: https://developer.akamai.com/api/core_features/cp_codes_reporting_groups/v1.html#putreportinggroup
+// Endpoint: POST /cprg/v1/reporting-groups
+// Endpoint: PUT /cprg/v1/reporting-groups/{reportingGroupId}
+func (reportingGroup *ReportingGroup) Save() error {
+	method, path := "POST", "/cprg/v1/reporting-groups"
+	if reportingGroup.ReportingGroupID != 0 {
+		method, path = "PUT", fmt.Sprintf("/cprg/v1/reporting-groups/%d", reportingGroup.ReportingGroupID)
+	}
+
+	req, err := client.NewJSONRequest(Config, method, path, reportingGroup)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	if err = client.BodyJSON(res, reportingGroup); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddToReportingGroup adds the CP code to an existing reporting group
+func (cpcode *CpCode) AddToReportingGroup(reportingGroupID int) error {
+	reportingGroup := NewReportingGroup()
+	reportingGroup.ReportingGroupID = reportingGroupID
+	if err := reportingGroup.GetReportingGroup(); err != nil {
+		return err
+	}
+
+	contractID := ""
+	if cpcode.parent != nil {
+		contractID = cpcode.parent.ContractID
+		if contractID == "" && cpcode.parent.Contract != nil {
+			contractID = cpcode.parent.Contract.ContractID
+		}
+	}
+	if contractID == "" {
+		return fmt.Errorf("CP code \"%s\" has no contract", cpcode.CpcodeID)
+	}
+
+	reportingGroup.AddCpCode(contractID, cpcode)
+
+	return reportingGroup.Save()
+}
@@ -0,0 +1,274 @@
+package papi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/cps-v2"
+)
+
+// EdgeHostnameChangeStatusValue is used to create an "enum" of possible EdgeHostnameChange.Status values
+type EdgeHostnameChangeStatusValue string
+
+const (
+	// EdgeHostnameChangePending EdgeHostnameChange.Status value PENDING
+	EdgeHostnameChangePending EdgeHostnameChangeStatusValue = "PENDING"
+	// EdgeHostnameChangeSucceeded EdgeHostnameChange.Status value SUCCEEDED
+	EdgeHostnameChangeSucceeded EdgeHostnameChangeStatusValue = "SUCCEEDED"
+	// EdgeHostnameChangeFailed EdgeHostnameChange.Status value FAILED
+	EdgeHostnameChangeFailed EdgeHostnameChangeStatusValue = "FAILED"
+)
+
+// edgeHostnameDomainSuffixes are the DNS zones edge hostnames are created in
+var edgeHostnameDomainSuffixes = []string{"edgesuite.net", "edgekey.net", "akamaized.net"}
+
+// EdgeHostnamePatch is a single JSON Patch operation of an Edge Hostname update
+//
+// Path is either "/ttl" or "/ipVersionBehavior".
+type EdgeHostnamePatch struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// EdgeHostnameChange is an asynchronous change to an Edge Hostname, made
+// through the Edge Hostnames API
+//
+// API Docs: https://developer.akamai.com/api/core_features/edge_hostnames/v1.html#changerequest
+type EdgeHostnameChange struct {
+	client.Resource
+	ChangeID         int                           `json:"changeId"`
+	Action           string                        `json:"action"`
+	Status           EdgeHostnameChangeStatusValue `json:"status"`
+	StatusMessage    string                        `json:"statusMessage,omitempty"`
+	StatusUpdateDate string                        `json:"statusUpdateDate,omitempty"`
+	SubmitDate       string                        `json:"submitDate,omitempty"`
+	Submitter        string                        `json:"submitter,omitempty"`
+}
+
+// GetChange refreshes the status of the change
+//
+// API Docs: https://developer.akamai.com/api/core_features/edge_hostnames/v1.html#getchangerequest
+// Endpoint: GET /hapi/v1/changes/{changeId}
+func (change *EdgeHostnameChange) GetChange() error {
+	req, err := client.NewRequest(
+		Config,
+		"GET",
+		fmt.Sprintf("/hapi/v1/changes/%d", change.ChangeID),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	if err = client.BodyJSON(res, change); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Done reports whether the change is no longer pending
+func (change *EdgeHostnameChange) Done() bool {
+	return change.Status == EdgeHostnameChangeSucceeded || change.Status == EdgeHostnameChangeFailed
+}
+
+// Update applies patches to the Edge Hostname
+//
+// PAPI can not modify Edge Hostnames, so the change is submitted to the Edge
+// Hostnames API. It is applied asynchronously, see EdgeHostnameChange.GetChange().
+//
+// API Docs: https://developer.akamai.com/api/core_features/edge_hostnames/v1.html#patchedgehostname
+// Endpoint: PATCH /hapi/v1/dns-zones/{dnsZone}/edge-hostnames/{recordName}{?comments}
+func (edgeHostname *EdgeHostname) Update(patches []*EdgeHostnamePatch, comments string) (*EdgeHostnameChange, error) {
+	path, err := edgeHostname.hapiPath(comments)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewJSONRequest(Config, "PATCH", path, patches)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json-patch+json")
+
+	change, err := doEdgeHostnameChange(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, patch := range patches {
+		switch patch.Path {
+		case "/ipVersionBehavior":
+			edgeHostname.IPVersionBehavior = patch.Value
+		}
+	}
+
+	return change, nil
+}
+
+// SetIPVersionBehavior changes the IP versions the Edge Hostname resolves to
+func (edgeHostname *EdgeHostname) SetIPVersionBehavior(behavior IPVersionBehaviorValue, comments string) (*EdgeHostnameChange, error) {
+	return edgeHostname.Update([]*EdgeHostnamePatch{{Op: "replace", Path: "/ipVersionBehavior", Value: string(behavior)}}, comments)
+}
+
+// SetTTL changes the DNS TTL of the Edge Hostname, in seconds
+func (edgeHostname *EdgeHostname) SetTTL(ttl int, comments string) (*EdgeHostnameChange, error) {
+	return edgeHostname.Update([]*EdgeHostnamePatch{{Op: "replace", Path: "/ttl", Value: strconv.Itoa(ttl)}}, comments)
+}
+
+// Delete removes the Edge Hostname
+//
+// The Edge Hostname must no longer be used by any active property.
+//
+// API Docs: https://developer.akamai.com/api/core_features/edge_hostnames/v1.html#deleteedgehostname
+// Endpoint: DELETE /hapi/v1/dns-zones/{dnsZone}/edge-hostnames/{recordName}{?comments}
+func (edgeHostname *EdgeHostname) Delete(comments string) (*EdgeHostnameChange, error) {
+	path, err := edgeHostname.hapiPath(comments)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := client.NewRequest(Config, "DELETE", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return doEdgeHostnameChange(req)
+}
+
+// hapiPath returns the Edge Hostnames API path of the Edge Hostname
+func (edgeHostname *EdgeHostname) hapiPath(comments string) (string, error) {
+	zone, record := edgeHostname.DomainSuffix, edgeHostname.DomainPrefix
+	if zone == "" || record == "" {
+		domain := edgeHostnameDomain(edgeHostname)
+		for _, suffix := range edgeHostnameDomainSuffixes {
+			if strings.HasSuffix(domain, "."+suffix) {
+				zone, record = suffix, strings.TrimSuffix(domain, "."+suffix)
+			}
+		}
+	}
+
+	if zone == "" || record == "" {
+		return "", fmt.Errorf("unable to determine DNS zone of edge hostname \"%s\"", edgeHostnameDomain(edgeHostname))
+	}
+
+	path := fmt.Sprintf("/hapi/v1/dns-zones/%s/edge-hostnames/%s", zone, record)
+	if comments != "" {
+		path += "?comments=" + url.QueryEscape(comments)
+	}
+
+	return path, nil
+}
+
+func doEdgeHostnameChange(req *http.Request) (*EdgeHostnameChange, error) {
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.IsError(res) {
+		return nil, client.NewAPIError(res)
+	}
+
+	change := &EdgeHostnameChange{}
+	change.Init()
+	if err = client.BodyJSON(res, change); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// EdgeHostnameCertificate links the Edge Hostnames secured by a CPS
+// enrollment to that enrollment
+//
+// Enrollment is nil for secure Edge Hostnames without a known enrollment,
+// which are grouped under EnrollmentID 0.
+type EdgeHostnameCertificate struct {
+	EnrollmentID  int
+	Enrollment    *cps.Enrollment
+	EdgeHostnames []*EdgeHostname
+}
+
+// CommonName returns the common name of the enrollment certificate
+func (certificate *EdgeHostnameCertificate) CommonName() string {
+	if certificate.Enrollment == nil || certificate.Enrollment.CertificateSigningRequest == nil {
+		return ""
+	}
+
+	return certificate.Enrollment.CertificateSigningRequest.CommonName
+}
+
+// Names returns the common name and subject alternative names of the
+// enrollment certificate
+func (certificate *EdgeHostnameCertificate) Names() []string {
+	if certificate.Enrollment == nil || certificate.Enrollment.CertificateSigningRequest == nil {
+		return nil
+	}
+
+	csr := certificate.Enrollment.CertificateSigningRequest
+	names := []string{csr.CommonName}
+	if csr.AlternativeNames != nil {
+		for _, name := range *csr.AlternativeNames {
+			if name != csr.CommonName {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// GetCertificates links the secure Edge Hostnames to their CPS enrollments,
+// ordered by enrollment ID
+//
+// Each enrollment is fetched once using the cps-v2 package, which must be
+// initialized with cps.Init().
+//
+// Endpoint: GET /cps/v2/enrollments/{enrollmentId}
+func (edgeHostnames *EdgeHostnames) GetCertificates() ([]*EdgeHostnameCertificate, error) {
+	certificates := map[int]*EdgeHostnameCertificate{}
+	for _, edgeHostname := range edgeHostnames.EdgeHostnames.Items {
+		if edgeHostname.CertEnrollmentId == 0 && !edgeHostname.Secure {
+			continue
+		}
+
+		certificate, ok := certificates[edgeHostname.CertEnrollmentId]
+		if !ok {
+			certificate = &EdgeHostnameCertificate{EnrollmentID: edgeHostname.CertEnrollmentId}
+			certificates[edgeHostname.CertEnrollmentId] = certificate
+		}
+		certificate.EdgeHostnames = append(certificate.EdgeHostnames, edgeHostname)
+	}
+
+	var linked []*EdgeHostnameCertificate
+	for id, certificate := range certificates {
+		if id != 0 {
+			enrollment, err := cps.GetEnrollment(fmt.Sprintf("/cps/v2/enrollments/%d", id))
+			if err != nil {
+				return nil, fmt.Errorf("enrollment %d: %s", id, err)
+			}
+			certificate.Enrollment = enrollment
+		}
+		linked = append(linked, certificate)
+	}
+
+	sort.Slice(linked, func(i, j int) bool { return linked[i].EnrollmentID < linked[j].EnrollmentID })
+
+	return linked, nil
+}
@@ -0,0 +1,134 @@
+package papi
+
+import (
+	"testing"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/cps-v2"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/jsonhooks-v1"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestEdgeHostname_MapDetails(t *testing.T) {
+	edgeHostnames := NewEdgeHostnames()
+	err := jsonhooks.Unmarshal([]byte(`{"edgeHostnames": {"items": [
+		{"edgeHostnameId": "ehn_1", "domainPrefix": "www.example.com", "domainSuffix": "edgesuite.net", "mapDetails:serialNumber": 1234, "mapDetails:mapDomain": "a1234.g.akamai.net"},
+		{"edgeHostnameId": "ehn_2", "domainPrefix": "api.example.com", "domainSuffix": "edgekey.net", "mapDetails": {"serialNumber": 5678, "slotNumber": 9, "mapDomain": "e5678.a.akamaiedge.net"}},
+		{"edgeHostnameId": "ehn_3", "domainPrefix": "img.example.com", "domainSuffix": "edgesuite.net"}
+	]}}`), edgeHostnames)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	items := edgeHostnames.EdgeHostnames.Items
+	assert.Equal(t, &EdgeHostnameMapDetails{SerialNumber: 1234, MapDomain: "a1234.g.akamai.net"}, items[0].MapDetails)
+	assert.Equal(t, &EdgeHostnameMapDetails{SerialNumber: 5678, SlotNumber: 9, MapDomain: "e5678.a.akamaiedge.net"}, items[1].MapDetails)
+	assert.Equal(t, 9, items[1].MapDetailsSlotNumber)
+	assert.Nil(t, items[2].MapDetails)
+}
+
+func TestEdgeHostname_SetTTL(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Patch("/hapi/v1/dns-zones/edgesuite.net/edge-hostnames/www.example.com$").
+		MatchParam("comments", "shorter ttl").
+		MatchHeader("Content-Type", "application/json-patch\\+json").
+		BodyString(`[{"op": "replace", "path": "/ttl", "value": "300"}]`).
+		Reply(202).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"changeId": 42, "action": "EDIT", "status": "PENDING"}`)
+
+	gock.New(orchestratorTestURL).
+		Get("/hapi/v1/changes/42$").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"changeId": 42, "action": "EDIT", "status": "SUCCEEDED"}`)
+
+	Init(config)
+
+	edgeHostname := NewEdgeHostname(NewEdgeHostnames())
+	edgeHostname.EdgeHostnameDomain = "www.example.com.edgesuite.net"
+
+	change, err := edgeHostname.SetTTL(300, "shorter ttl")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 42, change.ChangeID)
+	assert.False(t, change.Done())
+
+	assert.NoError(t, change.GetChange())
+	assert.True(t, change.Done())
+	assert.True(t, gock.IsDone())
+}
+
+func TestEdgeHostname_Delete(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Delete("/hapi/v1/dns-zones/edgekey.net/edge-hostnames/api.example.com$").
+		Reply(202).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"changeId": 43, "action": "DELETE", "status": "PENDING"}`)
+
+	Init(config)
+
+	edgeHostname := NewEdgeHostname(NewEdgeHostnames())
+	edgeHostname.DomainPrefix = "api.example.com"
+	edgeHostname.DomainSuffix = "edgekey.net"
+
+	change, err := edgeHostname.Delete("")
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE", change.Action)
+	assert.True(t, gock.IsDone())
+
+	edgeHostname = NewEdgeHostname(NewEdgeHostnames())
+	edgeHostname.EdgeHostnameDomain = "www.example.com"
+	_, err = edgeHostname.Delete("")
+	assert.Error(t, err)
+}
+
+func TestEdgeHostnames_GetCertificates(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Get("/cps/v2/enrollments/10$").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"csr": {"cn": "www.example.com", "sans": ["www.example.com", "api.example.com"]}}`)
+
+	Init(config)
+	cps.Init(config)
+
+	edgeHostnames := NewEdgeHostnames()
+	for i, domain := range []string{"www.example.com", "api.example.com", "legacy.example.com", "img.example.com"} {
+		edgeHostname := edgeHostnames.NewEdgeHostname()
+		edgeHostname.DomainPrefix = domain
+		edgeHostname.DomainSuffix = "edgekey.net"
+		edgeHostname.Secure = true
+		if i < 2 {
+			edgeHostname.CertEnrollmentId = 10
+		}
+		if i == 3 {
+			edgeHostname.DomainSuffix = "edgesuite.net"
+			edgeHostname.Secure = false
+		}
+	}
+
+	certificates, err := edgeHostnames.GetCertificates()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, gock.IsDone())
+
+	if assert.Len(t, certificates, 2) {
+		assert.Equal(t, 0, certificates[0].EnrollmentID)
+		assert.Nil(t, certificates[0].Enrollment)
+		assert.Len(t, certificates[0].EdgeHostnames, 1)
+
+		assert.Equal(t, 10, certificates[1].EnrollmentID)
+		assert.Equal(t, "www.example.com", certificates[1].CommonName())
+		assert.Equal(t, []string{"www.example.com", "api.example.com"}, certificates[1].Names())
+		assert.Len(t, certificates[1].EdgeHostnames, 2)
+	}
+}
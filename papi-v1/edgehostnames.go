@@ -143,21 +143,22 @@ func (edgeHostnames *EdgeHostnames) AddEdgeHostname(edgeHostname *EdgeHostname)
 type EdgeHostname struct {
 	client.Resource
 	parent                 *EdgeHostnames
-	EdgeHostnameID         string      `json:"edgeHostnameId,omitempty"`
-	EdgeHostnameDomain     string      `json:"edgeHostnameDomain,omitempty"`
-	ProductID              string      `json:"productId"`
-	DomainPrefix           string      `json:"domainPrefix"`
-	DomainSuffix           string      `json:"domainSuffix"`
-	CertEnrollmentId       int         `json:"certEnrollmentId,omitempty"`
-	SlotNumber             int         `json:"slotNumber,omitempty"`
-	SecureNetwork          string      `json:"secureNetwork,omitempty"`
-	Status                 StatusValue `json:"status,omitempty"`
-	Secure                 bool        `json:"secure,omitempty"`
-	IPVersionBehavior      string      `json:"ipVersionBehavior,omitempty"`
-	MapDetailsSerialNumber int         `json:"mapDetails:serialNumber,omitempty"`
-	MapDetailsSlotNumber   int         `json:"mapDetails:slotNumber,omitempty"`
-	MapDetailsMapDomain    string      `json:"mapDetails:mapDomain,omitempty"`
-	StatusChange           chan bool   `json:"-"`
+	EdgeHostnameID         string                  `json:"edgeHostnameId,omitempty"`
+	EdgeHostnameDomain     string                  `json:"edgeHostnameDomain,omitempty"`
+	ProductID              string                  `json:"productId"`
+	DomainPrefix           string                  `json:"domainPrefix"`
+	DomainSuffix           string                  `json:"domainSuffix"`
+	CertEnrollmentId       int                     `json:"certEnrollmentId,omitempty"`
+	SlotNumber             int                     `json:"slotNumber,omitempty"`
+	SecureNetwork          string                  `json:"secureNetwork,omitempty"`
+	Status                 StatusValue             `json:"status,omitempty"`
+	Secure                 bool                    `json:"secure,omitempty"`
+	IPVersionBehavior      string                  `json:"ipVersionBehavior,omitempty"`
+	MapDetailsSerialNumber int                     `json:"mapDetails:serialNumber,omitempty"`
+	MapDetailsSlotNumber   int                     `json:"mapDetails:slotNumber,omitempty"`
+	MapDetailsMapDomain    string                  `json:"mapDetails:mapDomain,omitempty"`
+	MapDetails             *EdgeHostnameMapDetails `json:"mapDetails,omitempty"`
+	StatusChange           chan bool               `json:"-"`
 }
 
 // NewEdgeHostname creates a new EdgeHostname
@@ -172,6 +173,42 @@ func (edgeHostname *EdgeHostname) Init() {
 	edgeHostname.StatusChange = make(chan bool, 1)
 }
 
+// EdgeHostnameMapDetails describes the map an Edge Hostname resolves to
+//
+// PAPI returns map details as flattened "mapDetails:*" fields when the
+// mapDetails option is requested, while other APIs nest them in a
+// "mapDetails" object; either form populates EdgeHostname.MapDetails.
+type EdgeHostnameMapDetails struct {
+	SerialNumber int    `json:"serialNumber,omitempty"`
+	SlotNumber   int    `json:"slotNumber,omitempty"`
+	MapDomain    string `json:"mapDomain,omitempty"`
+}
+
+// PostUnmarshalJSON is called after JSON unmarshaling into EdgeHostname
+//
+// See: jsonhooks-v1/jsonhooks.Unmarshal()
+func (edgeHostname *EdgeHostname) PostUnmarshalJSON() error {
+	edgeHostname.Init()
+
+	if edgeHostname.MapDetails == nil {
+		if edgeHostname.MapDetailsSerialNumber != 0 || edgeHostname.MapDetailsSlotNumber != 0 || edgeHostname.MapDetailsMapDomain != "" {
+			edgeHostname.MapDetails = &EdgeHostnameMapDetails{
+				SerialNumber: edgeHostname.MapDetailsSerialNumber,
+				SlotNumber:   edgeHostname.MapDetailsSlotNumber,
+				MapDomain:    edgeHostname.MapDetailsMapDomain,
+			}
+		}
+	} else {
+		edgeHostname.MapDetailsSerialNumber = edgeHostname.MapDetails.SerialNumber
+		edgeHostname.MapDetailsSlotNumber = edgeHostname.MapDetails.SlotNumber
+		edgeHostname.MapDetailsMapDomain = edgeHostname.MapDetails.MapDomain
+	}
+
+	edgeHostname.Complete <- true
+
+	return nil
+}
+
 // GetEdgeHostname populates EdgeHostname with data
 //
 // API Docs: https://developer.akamai.com/api/luna/papi/resources.html#getanedgehostname
@@ -227,6 +264,7 @@ func (edgeHostname *EdgeHostname) GetEdgeHostname(options string) error {
 			edgeHostname.MapDetailsSerialNumber = newEdgeHostname.MapDetailsSerialNumber
 			edgeHostname.MapDetailsSlotNumber = newEdgeHostname.MapDetailsSlotNumber
 			edgeHostname.MapDetailsMapDomain = newEdgeHostname.MapDetailsMapDomain
+			edgeHostname.MapDetails = newEdgeHostname.MapDetails
 
 			return nil
 		}
@@ -250,6 +288,7 @@ func (edgeHostname *EdgeHostname) GetEdgeHostname(options string) error {
 	edgeHostname.MapDetailsSerialNumber = newEdgeHostnames.EdgeHostnames.Items[0].MapDetailsSerialNumber
 	edgeHostname.MapDetailsSlotNumber = newEdgeHostnames.EdgeHostnames.Items[0].MapDetailsSlotNumber
 	edgeHostname.MapDetailsMapDomain = newEdgeHostnames.EdgeHostnames.Items[0].MapDetailsMapDomain
+	edgeHostname.MapDetails = newEdgeHostnames.EdgeHostnames.Items[0].MapDetails
 
 	return nil
 }
@@ -10,6 +10,7 @@ const (
 	ErrVariableNotFound
 	ErrRuleNotFound
 	ErrInvalidRules
+	ErrInvalidSelector
 )
 
 var (
@@ -20,5 +21,6 @@ var (
 		ErrVariableNotFound: errors.New("Variable not found"),
 		ErrRuleNotFound:     errors.New("Rule not found"),
 		ErrInvalidRules:     errors.New("Rule validation failed. See papi.Rules.Errors for details"),
+		ErrInvalidSelector:  errors.New("Invalid selector"),
 	}
 )
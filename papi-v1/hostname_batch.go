@@ -0,0 +1,399 @@
+package papi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IPVersionBehaviorValue is used to create an "enum" of possible EdgeHostname.IPVersionBehavior values
+type IPVersionBehaviorValue string
+
+const (
+	// IPVersionBehaviorIPv4 EdgeHostname.IPVersionBehavior value IPV4
+	IPVersionBehaviorIPv4 IPVersionBehaviorValue = "IPV4"
+	// IPVersionBehaviorIPv6Compliance EdgeHostname.IPVersionBehavior value IPV6_COMPLIANCE, dual stack
+	IPVersionBehaviorIPv6Compliance IPVersionBehaviorValue = "IPV6_COMPLIANCE"
+	// IPVersionBehaviorIPv6Performance EdgeHostname.IPVersionBehavior value IPV6_PERFORMANCE
+	IPVersionBehaviorIPv6Performance IPVersionBehaviorValue = "IPV6_PERFORMANCE"
+)
+
+// HostnameActionValue is used to create an "enum" of possible HostnameResult.Action values
+type HostnameActionValue string
+
+const (
+	// HostnameActionAdded the hostname was added to the property
+	HostnameActionAdded HostnameActionValue = "ADDED"
+	// HostnameActionUpdated the hostname now points to a different edge hostname
+	HostnameActionUpdated HostnameActionValue = "UPDATED"
+	// HostnameActionRemoved the hostname was removed from the property
+	HostnameActionRemoved HostnameActionValue = "REMOVED"
+	// HostnameActionUnchanged the property already had the requested hostname
+	HostnameActionUnchanged HostnameActionValue = "UNCHANGED"
+	// HostnameActionFailed the hostname could not be processed, see HostnameResult.Err
+	HostnameActionFailed HostnameActionValue = "FAILED"
+)
+
+// HostnameRequest describes a hostname to add to a property
+//
+// EdgeHostname is the edge hostname domain to point CnameFrom at, it defaults
+// to CnameFrom with an edgesuite.net suffix, or edgekey.net when Secure is set.
+// When the edge hostname does not exist it is created with the remaining fields.
+type HostnameRequest struct {
+	CnameFrom         string
+	EdgeHostname      string
+	Secure            bool
+	SecureNetwork     string
+	CertEnrollmentID  int
+	IPVersionBehavior IPVersionBehaviorValue
+	ProductID         string
+}
+
+// HostnameResult is the outcome for a single hostname of a HostnameBatch
+type HostnameResult struct {
+	CnameFrom           string
+	Action              HostnameActionValue
+	EdgeHostnameID      string
+	EdgeHostnameDomain  string
+	EdgeHostnameCreated bool
+	Err                 error
+}
+
+// String returns a one line description of the result
+func (result *HostnameResult) String() string {
+	switch result.Action {
+	case HostnameActionFailed:
+		return fmt.Sprintf("%s %s: %s", result.Action, result.CnameFrom, result.Err)
+	case HostnameActionRemoved:
+		return fmt.Sprintf("%s %s", result.Action, result.CnameFrom)
+	}
+
+	created := ""
+	if result.EdgeHostnameCreated {
+		created = " (created)"
+	}
+
+	return fmt.Sprintf("%s %s => %s%s", result.Action, result.CnameFrom, result.EdgeHostnameDomain, created)
+}
+
+// HostnameReport lists the changes made by HostnameBatch.Apply
+type HostnameReport struct {
+	PropertyID      string
+	PropertyVersion int
+	Results         []*HostnameResult
+	// EdgeHostnames holds the edge hostnames created by the batch
+	EdgeHostnames []*EdgeHostname
+}
+
+// Failed returns the results of hostnames that could not be processed
+func (report *HostnameReport) Failed() []*HostnameResult {
+	var failed []*HostnameResult
+	for _, result := range report.Results {
+		if result.Action == HostnameActionFailed {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// String returns the results, one per line
+func (report *HostnameReport) String() string {
+	lines := make([]string, len(report.Results))
+	for key, result := range report.Results {
+		lines[key] = result.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// HostnameBatch adds and removes many hostnames of a property version at once
+//
+// Edge hostnames are looked up by domain and created when missing, then the
+// hostname list is saved in a single etag-checked update. A hostname that
+// can not be processed is reported as failed without stopping the batch.
+//
+//	batch := papi.NewHostnameBatch(property, 3)
+//	batch.Add(&papi.HostnameRequest{CnameFrom: "www.example.com", Secure: true, CertEnrollmentID: 1234})
+//	batch.Remove("old.example.com")
+//	report, err := batch.Apply(ctx)
+//	fmt.Println(report)
+type HostnameBatch struct {
+	Property        *Property
+	PropertyVersion int
+
+	// ProductID is used to create edge hostnames when the request has none,
+	// it defaults to the property product
+	ProductID string
+
+	// WaitForEdgeHostnames polls created edge hostnames until they are
+	// active before the hostnames are saved
+	WaitForEdgeHostnames bool
+	// EdgeHostnameTimeout limits how long created edge hostnames are polled,
+	// zero waits indefinitely
+	EdgeHostnameTimeout time.Duration
+	PollInterval        time.Duration
+
+	add    []*HostnameRequest
+	remove []string
+}
+
+// NewHostnameBatch creates a new HostnameBatch for a property version
+func NewHostnameBatch(property *Property, version int) *HostnameBatch {
+	return &HostnameBatch{
+		Property:             property,
+		PropertyVersion:      version,
+		WaitForEdgeHostnames: true,
+		EdgeHostnameTimeout:  30 * time.Minute,
+		PollInterval:         time.Minute,
+	}
+}
+
+// Add queues a hostname to be added, or repointed if it already exists
+func (batch *HostnameBatch) Add(request *HostnameRequest) {
+	batch.add = append(batch.add, request)
+}
+
+// Remove queues a hostname to be removed
+func (batch *HostnameBatch) Remove(cnameFrom string) {
+	batch.remove = append(batch.remove, cnameFrom)
+}
+
+// Apply provisions edge hostnames and saves the property hostnames
+//
+// An error is returned only when the batch could not be applied at all; the
+// outcome of each hostname is in the report. When saving the hostnames
+// fails, every added, updated or removed hostname is reported as failed.
+func (batch *HostnameBatch) Apply(ctx context.Context) (*HostnameReport, error) {
+	report := &HostnameReport{PropertyID: batch.Property.PropertyID, PropertyVersion: batch.PropertyVersion}
+
+	contract := NewContract(NewContracts())
+	contract.ContractID = propertyContractID(batch.Property)
+	group := NewGroup(NewGroups())
+	group.GroupID = propertyGroupID(batch.Property)
+
+	edgeHostnames := NewEdgeHostnames()
+	if len(batch.add) > 0 {
+		if err := edgeHostnames.GetEdgeHostnames(contract, group, ""); err != nil {
+			return nil, err
+		}
+	}
+	edgeHostnames.ContractID = contract.ContractID
+	edgeHostnames.GroupID = group.GroupID
+
+	hostnames := NewHostnames()
+	hostnames.PropertyID = batch.Property.PropertyID
+	hostnames.ContractID = contract.ContractID
+	hostnames.GroupID = group.GroupID
+	if err := hostnames.GetHostnames(&Version{PropertyVersion: batch.PropertyVersion}); err != nil {
+		return nil, err
+	}
+	hostnames.PropertyVersion = batch.PropertyVersion
+	original := copyHostnames(hostnames)
+
+	var pending []*HostnameResult
+	for _, request := range batch.add {
+		result := &HostnameResult{CnameFrom: request.CnameFrom}
+		report.Results = append(report.Results, result)
+
+		edgeHostname, created, err := batch.edgeHostname(edgeHostnames, request)
+		if err != nil {
+			result.Action = HostnameActionFailed
+			result.Err = err
+			continue
+		}
+
+		result.EdgeHostnameID = edgeHostname.EdgeHostnameID
+		result.EdgeHostnameDomain = edgeHostnameDomain(edgeHostname)
+		result.EdgeHostnameCreated = created
+		if created {
+			report.EdgeHostnames = append(report.EdgeHostnames, edgeHostname)
+		}
+		pending = append(pending, result)
+	}
+
+	if batch.WaitForEdgeHostnames {
+		for _, edgeHostname := range report.EdgeHostnames {
+			if err := batch.wait(ctx, edgeHostname); err != nil {
+				for _, result := range pending {
+					if result.EdgeHostnameID == edgeHostname.EdgeHostnameID {
+						result.Action = HostnameActionFailed
+						result.Err = err
+					}
+				}
+			}
+		}
+	}
+
+	for _, result := range pending {
+		if result.Action == HostnameActionFailed {
+			continue
+		}
+		result.Action = setHostname(hostnames, result.CnameFrom, result.EdgeHostnameID)
+	}
+
+	for _, cnameFrom := range batch.remove {
+		result := &HostnameResult{CnameFrom: cnameFrom, Action: HostnameActionRemoved}
+		if !removeHostname(hostnames, cnameFrom) {
+			result.Action = HostnameActionFailed
+			result.Err = fmt.Errorf("hostname %s not found", cnameFrom)
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	if len(DiffHostnames(original, hostnames)) > 0 {
+		save := hostnames.SaveStrict
+		if hostnames.Etag == "" {
+			save = hostnames.Save
+		}
+		if err := save(); err != nil {
+			// nothing was saved, edge hostnames created meanwhile remain
+			for _, result := range report.Results {
+				if result.Action != HostnameActionFailed && result.Action != HostnameActionUnchanged {
+					result.Action = HostnameActionFailed
+					result.Err = err
+				}
+			}
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// edgeHostname finds the edge hostname for a request, creating it when missing
+func (batch *HostnameBatch) edgeHostname(edgeHostnames *EdgeHostnames, request *HostnameRequest) (*EdgeHostname, bool, error) {
+	prefix, suffix := splitEdgeHostname(request)
+
+	for _, edgeHostname := range edgeHostnames.EdgeHostnames.Items {
+		if strings.EqualFold(edgeHostnameDomain(edgeHostname), prefix+"."+suffix) {
+			if request.Secure && !edgeHostname.Secure && edgeHostname.DomainSuffix != "edgekey.net" {
+				return nil, false, fmt.Errorf("edge hostname %s is not secure", edgeHostnameDomain(edgeHostname))
+			}
+			return edgeHostname, false, nil
+		}
+	}
+
+	productID := request.ProductID
+	if productID == "" {
+		productID = batch.ProductID
+	}
+	if productID == "" {
+		productID = batch.Property.ProductID
+	}
+
+	ipVersionBehavior := request.IPVersionBehavior
+	if ipVersionBehavior == "" {
+		ipVersionBehavior = IPVersionBehaviorIPv4
+	}
+
+	edgeHostname := NewEdgeHostname(edgeHostnames)
+	edgeHostname.ProductID = productID
+	edgeHostname.DomainPrefix = prefix
+	edgeHostname.DomainSuffix = suffix
+	edgeHostname.Secure = request.Secure
+	edgeHostname.SecureNetwork = request.SecureNetwork
+	edgeHostname.CertEnrollmentId = request.CertEnrollmentID
+	edgeHostname.IPVersionBehavior = string(ipVersionBehavior)
+	if request.Secure && edgeHostname.SecureNetwork == "" {
+		edgeHostname.SecureNetwork = "ENHANCED_TLS"
+	}
+
+	if err := edgeHostname.Save(""); err != nil {
+		return nil, false, err
+	}
+	// Save added the edge hostname to edgeHostnames, so later requests of the
+	// batch for the same domain reuse it rather than create it again
+	edgeHostname.EdgeHostnameDomain = prefix + "." + suffix
+
+	return edgeHostname, true, nil
+}
+
+// wait polls a created edge hostname until it is active
+func (batch *HostnameBatch) wait(ctx context.Context, edgeHostname *EdgeHostname) error {
+	var deadline <-chan time.Time
+	if batch.EdgeHostnameTimeout > 0 {
+		timer := time.NewTimer(batch.EdgeHostnameTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for edgeHostname.Status != StatusActive {
+		timer := time.NewTimer(batch.PollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-deadline:
+			timer.Stop()
+			return fmt.Errorf("edge hostname %s not active after %s (status %s)", edgeHostnameDomain(edgeHostname), batch.EdgeHostnameTimeout, edgeHostname.Status)
+		case <-timer.C:
+		}
+
+		if err := edgeHostname.GetEdgeHostname(""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func splitEdgeHostname(request *HostnameRequest) (string, string) {
+	domain := strings.TrimSuffix(strings.ToLower(request.EdgeHostname), ".")
+	if domain == "" {
+		suffix := "edgesuite.net"
+		if request.Secure {
+			suffix = "edgekey.net"
+		}
+		return strings.ToLower(request.CnameFrom), suffix
+	}
+
+	for _, suffix := range []string{"edgesuite.net", "edgekey.net", "akamaized.net"} {
+		if strings.HasSuffix(domain, "."+suffix) {
+			return strings.TrimSuffix(domain, "."+suffix), suffix
+		}
+	}
+
+	return domain, "edgesuite.net"
+}
+
+func edgeHostnameDomain(edgeHostname *EdgeHostname) string {
+	if edgeHostname.EdgeHostnameDomain != "" {
+		return edgeHostname.EdgeHostnameDomain
+	}
+
+	return edgeHostname.DomainPrefix + "." + edgeHostname.DomainSuffix
+}
+
+func setHostname(hostnames *Hostnames, cnameFrom string, edgeHostnameID string) HostnameActionValue {
+	for _, hostname := range hostnames.Hostnames.Items {
+		if strings.EqualFold(hostname.CnameFrom, cnameFrom) {
+			if hostname.EdgeHostnameID == edgeHostnameID {
+				return HostnameActionUnchanged
+			}
+			hostname.CnameType = CnameTypeEdgeHostname
+			hostname.EdgeHostnameID = edgeHostnameID
+			hostname.CnameTo = ""
+			return HostnameActionUpdated
+		}
+	}
+
+	hostname := hostnames.NewHostname()
+	hostname.CnameFrom = cnameFrom
+	hostname.EdgeHostnameID = edgeHostnameID
+
+	return HostnameActionAdded
+}
+
+func removeHostname(hostnames *Hostnames, cnameFrom string) bool {
+	items := hostnames.Hostnames.Items
+	for key, hostname := range items {
+		if strings.EqualFold(hostname.CnameFrom, cnameFrom) {
+			hostnames.Hostnames.Items = append(items[:key], items[key+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
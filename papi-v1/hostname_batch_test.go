@@ -0,0 +1,225 @@
+package papi
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestHostnameBatch_Apply(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/edgehostnames").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{
+			"accountId": "act_TEST",
+			"contractId": "ctr_TEST",
+			"groupId": "grp_123",
+			"edgeHostnames": {"items": [
+				{"edgeHostnameId": "ehn_1", "edgeHostnameDomain": "a.example.com.edgesuite.net", "domainPrefix": "a.example.com", "domainSuffix": "edgesuite.net", "status": "ACTIVE"},
+				{"edgeHostnameId": "ehn_3", "edgeHostnameDomain": "c.example.com.edgesuite.net", "domainPrefix": "c.example.com", "domainSuffix": "edgesuite.net", "status": "ACTIVE"}
+			]}
+		}`)
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_123/versions/2/hostnames/").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{
+			"propertyId": "prp_123",
+			"propertyVersion": 2,
+			"etag": "abc",
+			"hostnames": {"items": [
+				{"cnameType": "EDGE_HOSTNAME", "edgeHostnameId": "ehn_1", "cnameFrom": "a.example.com"},
+				{"cnameType": "EDGE_HOSTNAME", "edgeHostnameId": "ehn_9", "cnameFrom": "old.example.com"}
+			]}
+		}`)
+	gock.New(orchestratorTestURL).
+		Post("/papi/v1/edgehostnames/").
+		Reply(201).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"edgeHostnameLink": "/papi/v1/edgehostnames/ehn_2?contractId=ctr_TEST&groupId=grp_123"}`)
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/edgehostnames/ehn_2").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"edgeHostnames": {"items": [
+			{"edgeHostnameId": "ehn_2", "edgeHostnameDomain": "b.example.com.edgekey.net", "domainPrefix": "b.example.com", "domainSuffix": "edgekey.net", "secure": true, "status": "ACTIVE"}
+		]}}`)
+
+	var saved []*Hostname
+	gock.New(orchestratorTestURL).
+		Put("/papi/v1/properties/prp_123/versions/2/hostnames").
+		MatchHeader("If-Match", "abc").
+		AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return false, err
+			}
+			return true, json.Unmarshal(body, &saved)
+		}).
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"propertyId": "prp_123", "propertyVersion": 2, "etag": "def", "hostnames": {"items": []}}`)
+
+	Init(config)
+
+	batch := NewHostnameBatch(newOrchestratorTestProperty(), 2)
+	batch.PollInterval = 0
+	batch.Add(&HostnameRequest{CnameFrom: "a.example.com"})
+	batch.Add(&HostnameRequest{CnameFrom: "b.example.com", Secure: true, CertEnrollmentID: 1234})
+	batch.Add(&HostnameRequest{CnameFrom: "c.example.com", EdgeHostname: "c.example.com.edgesuite.net", Secure: true})
+	batch.Remove("old.example.com")
+	batch.Remove("missing.example.com")
+
+	report, err := batch.Apply(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+
+	if assert.Len(t, report.Results, 5) {
+		assert.Equal(t, HostnameActionUnchanged, report.Results[0].Action)
+		assert.Equal(t, HostnameActionAdded, report.Results[1].Action)
+		assert.Equal(t, "ehn_2", report.Results[1].EdgeHostnameID)
+		assert.True(t, report.Results[1].EdgeHostnameCreated)
+		assert.Equal(t, HostnameActionFailed, report.Results[2].Action)
+		assert.Equal(t, HostnameActionRemoved, report.Results[3].Action)
+		assert.Equal(t, HostnameActionFailed, report.Results[4].Action)
+	}
+	assert.Len(t, report.Failed(), 2)
+	assert.Len(t, report.EdgeHostnames, 1)
+
+	if assert.Len(t, saved, 2) {
+		assert.Equal(t, "a.example.com", saved[0].CnameFrom)
+		assert.Equal(t, "b.example.com", saved[1].CnameFrom)
+		assert.Equal(t, "ehn_2", saved[1].EdgeHostnameID)
+	}
+}
+
+func TestHostnameBatch_Apply_SharedEdgeHostname(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/edgehostnames").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"accountId": "act_TEST", "contractId": "ctr_TEST", "groupId": "grp_123", "edgeHostnames": {"items": []}}`)
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_123/versions/2/hostnames/").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"propertyId": "prp_123", "propertyVersion": 2, "etag": "abc", "hostnames": {"items": []}}`)
+	gock.New(orchestratorTestURL).
+		Post("/papi/v1/edgehostnames/").
+		Times(1).
+		Reply(201).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"edgeHostnameLink": "/papi/v1/edgehostnames/ehn_2?contractId=ctr_TEST&groupId=grp_123"}`)
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/edgehostnames/ehn_2").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"edgeHostnames": {"items": [
+			{"edgeHostnameId": "ehn_2", "edgeHostnameDomain": "shared.example.com.edgekey.net", "domainPrefix": "shared.example.com", "domainSuffix": "edgekey.net", "secure": true, "status": "ACTIVE"}
+		]}}`)
+
+	var saved []*Hostname
+	gock.New(orchestratorTestURL).
+		Put("/papi/v1/properties/prp_123/versions/2/hostnames").
+		AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return false, err
+			}
+			return true, json.Unmarshal(body, &saved)
+		}).
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"propertyId": "prp_123", "propertyVersion": 2, "etag": "def", "hostnames": {"items": []}}`)
+
+	Init(config)
+
+	batch := NewHostnameBatch(newOrchestratorTestProperty(), 2)
+	batch.PollInterval = 0
+	batch.Add(&HostnameRequest{CnameFrom: "a.example.com", EdgeHostname: "shared.example.com.edgekey.net", Secure: true})
+	batch.Add(&HostnameRequest{CnameFrom: "b.example.com", EdgeHostname: "shared.example.com.edgekey.net", Secure: true})
+
+	report, err := batch.Apply(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+	assert.Empty(t, report.Failed())
+	assert.Len(t, report.EdgeHostnames, 1)
+
+	if assert.Len(t, report.Results, 2) {
+		assert.True(t, report.Results[0].EdgeHostnameCreated)
+		assert.False(t, report.Results[1].EdgeHostnameCreated)
+		assert.Equal(t, "ehn_2", report.Results[1].EdgeHostnameID)
+	}
+	if assert.Len(t, saved, 2) {
+		assert.Equal(t, "ehn_2", saved[0].EdgeHostnameID)
+		assert.Equal(t, "ehn_2", saved[1].EdgeHostnameID)
+	}
+}
+
+func TestHostnameBatch_Apply_SaveFailed(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/edgehostnames").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"accountId": "act_TEST", "contractId": "ctr_TEST", "groupId": "grp_123", "edgeHostnames": {"items": [
+			{"edgeHostnameId": "ehn_1", "edgeHostnameDomain": "a.example.com.edgesuite.net", "domainPrefix": "a.example.com", "domainSuffix": "edgesuite.net", "status": "ACTIVE"}
+		]}}`)
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_123/versions/2/hostnames/").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"propertyId": "prp_123", "propertyVersion": 2, "etag": "abc", "hostnames": {"items": [
+			{"cnameType": "EDGE_HOSTNAME", "edgeHostnameId": "ehn_1", "cnameFrom": "a.example.com"},
+			{"cnameType": "EDGE_HOSTNAME", "edgeHostnameId": "ehn_9", "cnameFrom": "old.example.com"}
+		]}}`)
+	gock.New(orchestratorTestURL).
+		Put("/papi/v1/properties/prp_123/versions/2/hostnames").
+		Reply(412).
+		SetHeader("Content-Type", "application/problem+json").
+		BodyString(`{"type": "/papi/v1/errors/precondition-failed", "title": "Precondition Failed", "status": 412}`)
+
+	Init(config)
+
+	batch := NewHostnameBatch(newOrchestratorTestProperty(), 2)
+	batch.Add(&HostnameRequest{CnameFrom: "a.example.com"})
+	batch.Add(&HostnameRequest{CnameFrom: "b.example.com", EdgeHostname: "a.example.com.edgesuite.net"})
+	batch.Remove("old.example.com")
+
+	report, err := batch.Apply(context.Background())
+	assert.True(t, IsPreconditionFailed(err))
+	assert.True(t, gock.IsDone())
+
+	if assert.Len(t, report.Results, 3) {
+		assert.Equal(t, HostnameActionUnchanged, report.Results[0].Action)
+		for _, result := range report.Results[1:] {
+			assert.Equal(t, HostnameActionFailed, result.Action, result.CnameFrom)
+			assert.Equal(t, err, result.Err)
+		}
+	}
+}
+
+func TestSplitEdgeHostname(t *testing.T) {
+	prefix, suffix := splitEdgeHostname(&HostnameRequest{CnameFrom: "WWW.example.com"})
+	assert.Equal(t, "www.example.com", prefix)
+	assert.Equal(t, "edgesuite.net", suffix)
+
+	prefix, suffix = splitEdgeHostname(&HostnameRequest{CnameFrom: "www.example.com", Secure: true})
+	assert.Equal(t, "www.example.com", prefix)
+	assert.Equal(t, "edgekey.net", suffix)
+
+	prefix, suffix = splitEdgeHostname(&HostnameRequest{CnameFrom: "www.example.com", EdgeHostname: "shared.example.com.edgekey.net."})
+	assert.Equal(t, "shared.example.com", prefix)
+	assert.Equal(t, "edgekey.net", suffix)
+}
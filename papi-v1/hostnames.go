@@ -1,6 +1,7 @@
 package papi
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
@@ -109,6 +110,23 @@ func (hostnames *Hostnames) NewHostname() *Hostname {
 
 // Save updates a properties hostnames
 func (hostnames *Hostnames) Save() error {
+	return hostnames.save(false)
+}
+
+// SaveStrict updates a properties hostnames only if they are unchanged since
+// they were retrieved
+//
+// Hostnames.Etag is sent as If-Match, so the API rejects the update with
+// 412 Precondition Failed when the hostnames were modified concurrently.
+func (hostnames *Hostnames) SaveStrict() error {
+	if hostnames.Etag == "" {
+		return errors.New("hostnames have no etag, did you call GetHostnames()?")
+	}
+
+	return hostnames.save(true)
+}
+
+func (hostnames *Hostnames) save(strict bool) error {
 	req, err := client.NewJSONRequest(
 		Config,
 		"PUT",
@@ -125,6 +143,10 @@ func (hostnames *Hostnames) Save() error {
 		return err
 	}
 
+	if strict {
+		req.Header.Set("If-Match", hostnames.Etag)
+	}
+
 	res, err := client.Do(Config, req)
 	if err != nil {
 		return err
@@ -0,0 +1,323 @@
+package papi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
+)
+
+// IncludeActivations is a collection of include activations
+type IncludeActivations struct {
+	client.Resource
+	AccountID   string `json:"accountId"`
+	ContractID  string `json:"contractId"`
+	GroupID     string `json:"groupId"`
+	Activations struct {
+		Items []*IncludeActivation `json:"items"`
+	} `json:"activations"`
+}
+
+// NewIncludeActivations creates a new IncludeActivations
+func NewIncludeActivations() *IncludeActivations {
+	activations := &IncludeActivations{}
+	activations.Init()
+
+	return activations
+}
+
+// GetActivations retrieves activation data for a given include
+//
+// See: Include.GetActivations()
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/get-include-activations
+// Endpoint: GET /papi/v1/includes/{includeId}/activations{?contractId,groupId}
+func (activations *IncludeActivations) GetActivations(include *Include) error {
+	req, err := client.NewRequest(
+		Config,
+		"GET",
+		fmt.Sprintf("/papi/v1/includes/%s/activations?contractId=%s&groupId=%s",
+			include.IncludeID,
+			include.ContractID,
+			include.GroupID,
+		),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	if err = client.BodyJSON(res, activations); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetLatestActivation gets the latest activation for the specified network
+//
+// See: Activations.GetLatestActivation()
+func (activations *IncludeActivations) GetLatestActivation(network NetworkValue, status StatusValue) (*IncludeActivation, error) {
+	if network == "" {
+		network = NetworkProduction
+	}
+
+	if status == "" {
+		status = StatusActive
+	}
+
+	var latest *IncludeActivation
+	for _, activation := range activations.Activations.Items {
+		if activation.Network == network && activation.Status == status && (latest == nil || activation.IncludeVersion > latest.IncludeVersion) {
+			latest = activation
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("No activation found (network: %s, status: %s)", network, status)
+	}
+
+	return latest, nil
+}
+
+// IncludeActivation represents an include activation resource
+type IncludeActivation struct {
+	client.Resource
+	ActivationID        string                      `json:"activationId,omitempty"`
+	ActivationType      ActivationValue             `json:"activationType,omitempty"`
+	AcknowledgeWarnings []string                    `json:"acknowledgeWarnings,omitempty"`
+	ComplianceRecord    *ActivationComplianceRecord `json:"complianceRecord,omitempty"`
+	IncludeID           string                      `json:"includeId,omitempty"`
+	IncludeName         string                      `json:"includeName,omitempty"`
+	IncludeType         IncludeTypeValue            `json:"includeType,omitempty"`
+	IncludeVersion      int                         `json:"includeVersion"`
+	Network             NetworkValue                `json:"network"`
+	Status              StatusValue                 `json:"status,omitempty"`
+	SubmitDate          string                      `json:"submitDate,omitempty"`
+	UpdateDate          string                      `json:"updateDate,omitempty"`
+	Note                string                      `json:"note,omitempty"`
+	NotifyEmails        []string                    `json:"notifyEmails"`
+}
+
+// NewIncludeActivation creates a new IncludeActivation
+func NewIncludeActivation() *IncludeActivation {
+	activation := &IncludeActivation{}
+	activation.Init()
+
+	return activation
+}
+
+func (activation *IncludeActivation) update(newActivation *IncludeActivation) {
+	activation.ActivationID = newActivation.ActivationID
+	activation.ActivationType = newActivation.ActivationType
+	activation.AcknowledgeWarnings = newActivation.AcknowledgeWarnings
+	activation.ComplianceRecord = newActivation.ComplianceRecord
+	activation.IncludeID = newActivation.IncludeID
+	activation.IncludeName = newActivation.IncludeName
+	activation.IncludeType = newActivation.IncludeType
+	activation.IncludeVersion = newActivation.IncludeVersion
+	activation.Network = newActivation.Network
+	activation.Status = newActivation.Status
+	activation.SubmitDate = newActivation.SubmitDate
+	activation.UpdateDate = newActivation.UpdateDate
+	activation.Note = newActivation.Note
+	activation.NotifyEmails = newActivation.NotifyEmails
+}
+
+// GetActivation populates the IncludeActivation resource
+//
+// The returned duration is the poll interval suggested by the API through
+// the Retry-After header, defaulting to 30 seconds.
+//
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/get-include-activation
+// Endpoint: GET /papi/v1/includes/{includeId}/activations/{activationId}{?contractId,groupId}
+func (activation *IncludeActivation) GetActivation(include *Include) (time.Duration, error) {
+	req, err := client.NewRequest(
+		Config,
+		"GET",
+		fmt.Sprintf(
+			"/papi/v1/includes/%s/activations/%s?contractId=%s&groupId=%s",
+			include.IncludeID,
+			activation.ActivationID,
+			include.ContractID,
+			include.GroupID,
+		),
+		nil,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return 0, err
+	}
+
+	if client.IsError(res) {
+		return 0, client.NewAPIError(res)
+	}
+
+	activations := NewIncludeActivations()
+	if err := client.BodyJSON(res, activations); err != nil {
+		return 0, err
+	}
+
+	activation.update(activations.Activations.Items[0])
+
+	return retryAfter(res, 30*time.Second), nil
+}
+
+// Save activates an include version
+//
+// If acknowledgeWarnings is true and warnings are returned on the first attempt,
+// a second attempt is made, acknowledging the warnings.
+//
+// Activating an include does not check the properties referencing it, see
+// Include.CheckParents().
+//
+// See: Include.Activate()
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/post-include-activation
+// Endpoint: POST /papi/v1/includes/{includeId}/activations{?contractId,groupId}
+func (activation *IncludeActivation) Save(include *Include, acknowledgeWarnings bool) error {
+	if err := checkCompliance(activation.Network, &activation.ComplianceRecord, &activation.Note); err != nil {
+		return err
+	}
+
+	if activation.ActivationType == "" {
+		activation.ActivationType = ActivationTypeActivate
+	}
+
+	req, err := client.NewJSONRequest(
+		Config,
+		"POST",
+		fmt.Sprintf(
+			"/papi/v1/includes/%s/activations?contractId=%s&groupId=%s",
+			include.IncludeID,
+			include.ContractID,
+			include.GroupID,
+		),
+		activation,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) && (!acknowledgeWarnings || res.StatusCode != 400) {
+		return client.NewAPIError(res)
+	}
+
+	if res.StatusCode == 400 {
+		warnings := &struct {
+			Warnings []*ActivationWarning `json:"warnings,omitempty"`
+		}{}
+
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+
+		if err = json.Unmarshal(body, warnings); err != nil {
+			return err
+		}
+
+		// Just in case we got a 400 for a different reason
+		if len(warnings.Warnings) == 0 {
+			return client.NewAPIErrorFromBody(res, body)
+		}
+
+		for _, warning := range warnings.Warnings {
+			activation.AcknowledgeWarnings = append(activation.AcknowledgeWarnings, warning.MessageID)
+		}
+
+		// Don't acknowledgeWarnings again, halting a potential endless recursion
+		return activation.Save(include, false)
+	}
+
+	var location client.JSONBody
+	if err = client.BodyJSON(res, &location); err != nil {
+		return err
+	}
+
+	req, err = client.NewRequest(
+		Config,
+		"GET",
+		location["activationLink"].(string),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err = client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	activations := NewIncludeActivations()
+	if err := client.BodyJSON(res, activations); err != nil {
+		return err
+	}
+
+	activation.update(activations.Activations.Items[0])
+
+	return nil
+}
+
+// Cancel an include activation in progress
+//
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/delete-include-activation
+// Endpoint: DELETE /papi/v1/includes/{includeId}/activations/{activationId}{?contractId,groupId}
+func (activation *IncludeActivation) Cancel(include *Include) error {
+	req, err := client.NewRequest(
+		Config,
+		"DELETE",
+		fmt.Sprintf(
+			"/papi/v1/includes/%s/activations/%s?contractId=%s&groupId=%s",
+			include.IncludeID,
+			activation.ActivationID,
+			include.ContractID,
+			include.GroupID,
+		),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	activations := NewIncludeActivations()
+	if err := client.BodyJSON(res, activations); err != nil {
+		return err
+	}
+
+	activation.update(activations.Activations.Items[0])
+
+	return nil
+}
@@ -0,0 +1,402 @@
+package papi
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
+)
+
+// IncludeVersions contains a collection of Include Versions
+type IncludeVersions struct {
+	client.Resource
+	AccountID   string           `json:"accountId"`
+	ContractID  string           `json:"contractId"`
+	GroupID     string           `json:"groupId"`
+	IncludeID   string           `json:"includeId"`
+	IncludeName string           `json:"includeName"`
+	IncludeType IncludeTypeValue `json:"includeType"`
+	Versions    struct {
+		Items []*IncludeVersion `json:"items"`
+	} `json:"versions"`
+}
+
+// NewIncludeVersions creates a new IncludeVersions
+func NewIncludeVersions() *IncludeVersions {
+	versions := &IncludeVersions{}
+	versions.Init()
+
+	return versions
+}
+
+// PostUnmarshalJSON is called after JSON unmarshaling into IncludeVersions
+//
+// See: jsonhooks-v1/jsonhooks.Unmarshal()
+func (versions *IncludeVersions) PostUnmarshalJSON() error {
+	versions.Init()
+
+	for key := range versions.Versions.Items {
+		versions.Versions.Items[key].parent = versions
+	}
+	versions.Complete <- true
+
+	return nil
+}
+
+// GetVersions retrieves all versions of a given include
+//
+// See: Include.GetVersions()
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/get-include-versions
+// Endpoint: GET /papi/v1/includes/{includeId}/versions{?contractId,groupId}
+func (versions *IncludeVersions) GetVersions(include *Include) error {
+	if include == nil {
+		return errors.New("You must provide an include")
+	}
+
+	req, err := client.NewRequest(
+		Config,
+		"GET",
+		fmt.Sprintf(
+			"/papi/v1/includes/%s/versions?contractId=%s&groupId=%s",
+			include.IncludeID,
+			include.ContractID,
+			include.GroupID,
+		),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	if err = client.BodyJSON(res, versions); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NewVersion creates a new version associated with the IncludeVersions collection
+//
+// If useEtagStrict is true, the new version is only created if
+// createFromVersion is unchanged, see IncludeVersion.CreateFromVersionEtag.
+func (versions *IncludeVersions) NewVersion(createFromVersion *IncludeVersion, useEtagStrict bool) *IncludeVersion {
+	version := NewIncludeVersion(versions)
+	version.CreateFromVersion = createFromVersion.IncludeVersion
+	if useEtagStrict {
+		version.CreateFromVersionEtag = createFromVersion.Etag
+	}
+
+	versions.Versions.Items = append(versions.Versions.Items, version)
+
+	return version
+}
+
+// IncludeVersion represents an Include Version
+type IncludeVersion struct {
+	client.Resource
+	parent                *IncludeVersions
+	IncludeVersion        int         `json:"includeVersion,omitempty"`
+	UpdatedByUser         string      `json:"updatedByUser,omitempty"`
+	UpdatedDate           time.Time   `json:"updatedDate,omitempty"`
+	ProductionStatus      StatusValue `json:"productionStatus,omitempty"`
+	StagingStatus         StatusValue `json:"stagingStatus,omitempty"`
+	Etag                  string      `json:"etag,omitempty"`
+	ProductID             string      `json:"productId,omitempty"`
+	RuleFormat            string      `json:"ruleFormat,omitempty"`
+	Note                  string      `json:"note,omitempty"`
+	CreateFromVersion     int         `json:"createFromVersion,omitempty"`
+	CreateFromVersionEtag string      `json:"createFromVersionEtag,omitempty"`
+}
+
+// NewIncludeVersion creates a new IncludeVersion
+func NewIncludeVersion(parent *IncludeVersions) *IncludeVersion {
+	version := &IncludeVersion{parent: parent}
+	version.Init()
+
+	return version
+}
+
+// GetVersion populates an IncludeVersion, the latest if getVersion is 0
+//
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/get-include-version
+// Endpoint: GET /papi/v1/includes/{includeId}/versions/{includeVersion}{?contractId,groupId}
+func (version *IncludeVersion) GetVersion(include *Include, getVersion int) error {
+	if getVersion == 0 {
+		getVersion = include.LatestVersion
+	}
+
+	req, err := client.NewRequest(
+		Config,
+		"GET",
+		fmt.Sprintf(
+			"/papi/v1/includes/%s/versions/%d?contractId=%s&groupId=%s",
+			include.IncludeID,
+			getVersion,
+			include.ContractID,
+			include.GroupID,
+		),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	newVersions := NewIncludeVersions()
+	if err := client.BodyJSON(res, newVersions); err != nil {
+		return err
+	}
+
+	version.update(newVersions.Versions.Items[0])
+
+	return nil
+}
+
+func (version *IncludeVersion) update(newVersion *IncludeVersion) {
+	version.IncludeVersion = newVersion.IncludeVersion
+	version.UpdatedByUser = newVersion.UpdatedByUser
+	version.UpdatedDate = newVersion.UpdatedDate
+	version.ProductionStatus = newVersion.ProductionStatus
+	version.StagingStatus = newVersion.StagingStatus
+	version.Etag = newVersion.Etag
+	version.ProductID = newVersion.ProductID
+	version.RuleFormat = newVersion.RuleFormat
+	version.Note = newVersion.Note
+}
+
+// Save creates a new include version
+//
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/post-include-versions
+// Endpoint: POST /papi/v1/includes/{includeId}/versions{?contractId,groupId}
+func (version *IncludeVersion) Save() error {
+	if version.IncludeVersion != 0 {
+		return fmt.Errorf("version (%d) already exists", version.IncludeVersion)
+	}
+
+	req, err := client.NewJSONRequest(
+		Config,
+		"POST",
+		fmt.Sprintf(
+			"/papi/v1/includes/%s/versions?contractId=%s&groupId=%s",
+			version.parent.IncludeID,
+			version.parent.ContractID,
+			version.parent.GroupID,
+		),
+		version,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	var location client.JSONBody
+	if err = client.BodyJSON(res, &location); err != nil {
+		return err
+	}
+
+	req, err = client.NewRequest(
+		Config,
+		"GET",
+		location["versionLink"].(string),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err = client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	versions := NewIncludeVersions()
+	if err = client.BodyJSON(res, versions); err != nil {
+		return err
+	}
+
+	version.update(versions.Versions.Items[0])
+
+	return nil
+}
+
+// IncludeRules is the rule tree of an include version
+type IncludeRules struct {
+	client.Resource
+	AccountID      string           `json:"accountId"`
+	ContractID     string           `json:"contractId"`
+	GroupID        string           `json:"groupId"`
+	IncludeID      string           `json:"includeId"`
+	IncludeVersion int              `json:"includeVersion"`
+	IncludeType    IncludeTypeValue `json:"includeType"`
+	Etag           string           `json:"etag"`
+	RuleFormat     string           `json:"ruleFormat"`
+	Rule           *Rule            `json:"rules"`
+	Errors         []*RuleErrors    `json:"errors,omitempty"`
+}
+
+// NewIncludeRules creates a new IncludeRules
+func NewIncludeRules() *IncludeRules {
+	rules := &IncludeRules{}
+	rules.Rule = NewRule()
+	rules.Rule.Name = "default"
+	rules.Init()
+
+	return rules
+}
+
+// PreMarshalJSON is called before JSON marshaling
+//
+// See: jsonhooks-v1/json.Marshal()
+func (rules *IncludeRules) PreMarshalJSON() error {
+	rules.Errors = nil
+	return nil
+}
+
+// Rules returns the include rule tree as *Rules sharing the same rules,
+// so the query, diff and lint helpers can be used on includes
+func (rules *IncludeRules) Rules() *Rules {
+	return &Rules{
+		AccountID:  rules.AccountID,
+		ContractID: rules.ContractID,
+		GroupID:    rules.GroupID,
+		Etag:       rules.Etag,
+		RuleFormat: rules.RuleFormat,
+		Rule:       rules.Rule,
+	}
+}
+
+// GetRules populates IncludeRules with the rules of an include version,
+// the latest if version is 0
+//
+// See: Include.GetRules()
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/get-include-version-rules
+// Endpoint: GET /papi/v1/includes/{includeId}/versions/{includeVersion}/rules{?contractId,groupId}
+func (rules *IncludeRules) GetRules(include *Include, version int) error {
+	if version == 0 {
+		version = include.LatestVersion
+	}
+
+	req, err := client.NewRequest(
+		Config,
+		"GET",
+		fmt.Sprintf(
+			"/papi/v1/includes/%s/versions/%d/rules?contractId=%s&groupId=%s",
+			include.IncludeID,
+			version,
+			include.ContractID,
+			include.GroupID,
+		),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	if err = client.BodyJSON(res, rules); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Save updates the rule tree of an include version
+//
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/put-include-version-rules
+// Endpoint: PUT /papi/v1/includes/{includeId}/versions/{includeVersion}/rules{?contractId,groupId}
+func (rules *IncludeRules) Save() error {
+	return rules.save(false)
+}
+
+// SaveStrict updates an include rule tree only if it is unchanged since it
+// was retrieved
+//
+// See: Rules.SaveStrict()
+func (rules *IncludeRules) SaveStrict() error {
+	if rules.Etag == "" {
+		return errors.New("rules have no etag, did you call GetRules()?")
+	}
+
+	return rules.save(true)
+}
+
+func (rules *IncludeRules) save(strict bool) error {
+	rules.Errors = []*RuleErrors{}
+
+	req, err := client.NewJSONRequest(
+		Config,
+		"PUT",
+		fmt.Sprintf(
+			"/papi/v1/includes/%s/versions/%d/rules?contractId=%s&groupId=%s",
+			rules.IncludeID,
+			rules.IncludeVersion,
+			rules.ContractID,
+			rules.GroupID,
+		),
+		rules,
+	)
+	if err != nil {
+		return err
+	}
+
+	if strict {
+		req.Header.Set("If-Match", rules.Etag)
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	if err = client.BodyJSON(res, rules); err != nil {
+		return err
+	}
+
+	if len(rules.Errors) != 0 {
+		return ErrorMap[ErrInvalidRules]
+	}
+
+	return nil
+}
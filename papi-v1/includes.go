@@ -0,0 +1,541 @@
+package papi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
+)
+
+// IncludeTypeValue is used to create an "enum" of possible Include.IncludeType values
+type IncludeTypeValue string
+
+const (
+	// IncludeTypeMicroServices Include.IncludeType value MICROSERVICES
+	IncludeTypeMicroServices IncludeTypeValue = "MICROSERVICES"
+	// IncludeTypeCommonSettings Include.IncludeType value COMMON_SETTINGS
+	IncludeTypeCommonSettings IncludeTypeValue = "COMMON_SETTINGS"
+)
+
+// Includes is a collection of PAPI Include resources
+//
+// An include is a rule fragment with its own versions and activations that
+// properties reference with the "include" behavior.
+type Includes struct {
+	client.Resource
+	Includes struct {
+		Items []*Include `json:"items"`
+	} `json:"includes"`
+}
+
+// NewIncludes creates a new Includes
+func NewIncludes() *Includes {
+	includes := &Includes{}
+	includes.Init()
+
+	return includes
+}
+
+// PostUnmarshalJSON is called after JSON unmarshaling into Includes
+//
+// See: jsonhooks-v1/jsonhooks.Unmarshal()
+func (includes *Includes) PostUnmarshalJSON() error {
+	includes.Init()
+
+	for key := range includes.Includes.Items {
+		includes.Includes.Items[key].parent = includes
+		includes.Includes.Items[key].Init()
+	}
+
+	includes.Complete <- true
+
+	return nil
+}
+
+// GetIncludes populates Includes with the includes of a contract and group
+//
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/get-includes
+// Endpoint: GET /papi/v1/includes{?contractId,groupId}
+func (includes *Includes) GetIncludes(contract *Contract, group *Group) error {
+	if contract == nil {
+		contract = NewContract(NewContracts())
+		contract.ContractID = group.ContractIDs[0]
+	}
+
+	req, err := client.NewRequest(
+		Config,
+		"GET",
+		fmt.Sprintf(
+			"/papi/v1/includes?contractId=%s&groupId=%s",
+			contract.ContractID,
+			group.GroupID,
+		),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	if err = client.BodyJSON(res, includes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindInclude finds an include by ID or name within the collection
+func (includes *Includes) FindInclude(id string) (*Include, error) {
+	for _, include := range includes.Includes.Items {
+		if include.IncludeID == id || include.IncludeName == id {
+			return include, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Unable to find include: \"%s\"", id)
+}
+
+// NewInclude creates a new include associated with the collection
+func (includes *Includes) NewInclude() *Include {
+	include := NewInclude(includes)
+	includes.Includes.Items = append(includes.Includes.Items, include)
+
+	return include
+}
+
+// Include represents a PAPI Include
+type Include struct {
+	client.Resource
+	parent            *Includes
+	AccountID         string            `json:"accountId,omitempty"`
+	ContractID        string            `json:"contractId,omitempty"`
+	GroupID           string            `json:"groupId,omitempty"`
+	IncludeID         string            `json:"includeId,omitempty"`
+	IncludeName       string            `json:"includeName"`
+	IncludeType       IncludeTypeValue  `json:"includeType"`
+	LatestVersion     int               `json:"latestVersion,omitempty"`
+	StagingVersion    int               `json:"stagingVersion,omitempty"`
+	ProductionVersion int               `json:"productionVersion,omitempty"`
+	ProductID         string            `json:"productId,omitempty"`
+	RuleFormat        string            `json:"ruleFormat,omitempty"`
+	CloneFrom         *CloneIncludeFrom `json:"cloneFrom,omitempty"`
+}
+
+// CloneIncludeFrom identifies the include version a new include is cloned from
+type CloneIncludeFrom struct {
+	IncludeID string `json:"includeId"`
+	Version   int    `json:"version"`
+}
+
+// NewInclude creates a new Include
+func NewInclude(parent *Includes) *Include {
+	include := &Include{parent: parent}
+	include.Init()
+
+	return include
+}
+
+// GetInclude populates an Include
+//
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/get-include
+// Endpoint: GET /papi/v1/includes/{includeId}{?contractId,groupId}
+func (include *Include) GetInclude() error {
+	req, err := client.NewRequest(
+		Config,
+		"GET",
+		fmt.Sprintf(
+			"/papi/v1/includes/%s?contractId=%s&groupId=%s",
+			include.IncludeID,
+			include.ContractID,
+			include.GroupID,
+		),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	newIncludes := NewIncludes()
+	if err := client.BodyJSON(res, newIncludes); err != nil {
+		return err
+	}
+
+	include.update(newIncludes.Includes.Items[0])
+
+	return nil
+}
+
+func (include *Include) update(newInclude *Include) {
+	include.AccountID = newInclude.AccountID
+	include.ContractID = newInclude.ContractID
+	include.GroupID = newInclude.GroupID
+	include.IncludeID = newInclude.IncludeID
+	include.IncludeName = newInclude.IncludeName
+	include.IncludeType = newInclude.IncludeType
+	include.LatestVersion = newInclude.LatestVersion
+	include.StagingVersion = newInclude.StagingVersion
+	include.ProductionVersion = newInclude.ProductionVersion
+	if newInclude.ProductID != "" {
+		include.ProductID = newInclude.ProductID
+	}
+	if newInclude.RuleFormat != "" {
+		include.RuleFormat = newInclude.RuleFormat
+	}
+}
+
+// Save creates an include, optionally cloned from another include
+//
+// ContractID, GroupID, IncludeName, IncludeType and ProductID are required.
+//
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/post-includes
+// Endpoint: POST /papi/v1/includes{?contractId,groupId}
+func (include *Include) Save() error {
+	req, err := client.NewJSONRequest(
+		Config,
+		"POST",
+		fmt.Sprintf(
+			"/papi/v1/includes?contractId=%s&groupId=%s",
+			include.ContractID,
+			include.GroupID,
+		),
+		include,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	var location client.JSONBody
+	if err = client.BodyJSON(res, &location); err != nil {
+		return err
+	}
+
+	req, err = client.NewRequest(
+		Config,
+		"GET",
+		location["includeLink"].(string),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err = client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	includes := NewIncludes()
+	if err = client.BodyJSON(res, includes); err != nil {
+		return err
+	}
+
+	include.update(includes.Includes.Items[0])
+
+	return nil
+}
+
+// Delete an include
+//
+// The include must not be active or referenced by any property version.
+//
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/delete-include
+// Endpoint: DELETE /papi/v1/includes/{includeId}{?contractId,groupId}
+func (include *Include) Delete() error {
+	req, err := client.NewRequest(
+		Config,
+		"DELETE",
+		fmt.Sprintf(
+			"/papi/v1/includes/%s?contractId=%s&groupId=%s",
+			include.IncludeID,
+			include.ContractID,
+			include.GroupID,
+		),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return err
+	}
+
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+
+	return nil
+}
+
+// GetVersions retrieves all versions of the include
+//
+// See: IncludeVersions.GetVersions()
+func (include *Include) GetVersions() (*IncludeVersions, error) {
+	versions := NewIncludeVersions()
+	if err := versions.GetVersions(include); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// GetRules retrieves the rules of an include version, the latest if version is 0
+//
+// See: IncludeRules.GetRules()
+func (include *Include) GetRules(version int) (*IncludeRules, error) {
+	rules := NewIncludeRules()
+	if err := rules.GetRules(include, version); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// GetActivations retrieves the activations of the include
+//
+// See: IncludeActivations.GetActivations()
+func (include *Include) GetActivations() (*IncludeActivations, error) {
+	activations := NewIncludeActivations()
+	if err := activations.GetActivations(include); err != nil {
+		return nil, err
+	}
+
+	return activations, nil
+}
+
+// Activate activates an include version
+//
+// See: IncludeActivation.Save()
+func (include *Include) Activate(activation *IncludeActivation, acknowledgeWarnings bool) error {
+	return activation.Save(include, acknowledgeWarnings)
+}
+
+// IncludeParent is a property whose staging or production version
+// references an include
+type IncludeParent struct {
+	AccountID                        string `json:"accountId"`
+	ContractID                       string `json:"contractId"`
+	GroupID                          string `json:"groupId"`
+	PropertyID                       string `json:"propertyId"`
+	PropertyName                     string `json:"propertyName"`
+	StagingVersion                   int    `json:"stagingVersion,omitempty"`
+	ProductionVersion                int    `json:"productionVersion,omitempty"`
+	IsIncludeUsedInStagingVersion    bool   `json:"isIncludeUsedInStagingVersion"`
+	IsIncludeUsedInProductionVersion bool   `json:"isIncludeUsedInProductionVersion"`
+}
+
+// Property returns a *Property for the parent, suitable for further API calls
+func (parent *IncludeParent) Property() *Property {
+	property := NewProperty(NewProperties())
+	property.AccountID = parent.AccountID
+	property.PropertyID = parent.PropertyID
+	property.PropertyName = parent.PropertyName
+	property.ContractID = parent.ContractID
+	property.GroupID = parent.GroupID
+	property.Contract = NewContract(NewContracts())
+	property.Contract.ContractID = parent.ContractID
+	property.Group = NewGroup(NewGroups())
+	property.Group.GroupID = parent.GroupID
+	property.StagingVersion = parent.StagingVersion
+	property.ProductionVersion = parent.ProductionVersion
+
+	return property
+}
+
+// GetParents retrieves the properties referencing the include
+//
+// API Docs: https://techdocs.akamai.com/property-mgr/reference/get-include-parents
+// Endpoint: GET /papi/v1/includes/{includeId}/parents{?contractId,groupId}
+func (include *Include) GetParents() ([]*IncludeParent, error) {
+	req, err := client.NewRequest(
+		Config,
+		"GET",
+		fmt.Sprintf(
+			"/papi/v1/includes/%s/parents?contractId=%s&groupId=%s",
+			include.IncludeID,
+			include.ContractID,
+			include.GroupID,
+		),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(Config, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.IsError(res) {
+		return nil, client.NewAPIError(res)
+	}
+
+	parents := &struct {
+		Properties struct {
+			Items []*IncludeParent `json:"items"`
+		} `json:"properties"`
+	}{}
+	if err = client.BodyJSON(res, parents); err != nil {
+		return nil, err
+	}
+
+	return parents.Properties.Items, nil
+}
+
+// IncludeCompatibilityError lists why a property version can not use an
+// include version
+type IncludeCompatibilityError struct {
+	IncludeID       string
+	IncludeVersion  int
+	PropertyID      string
+	PropertyVersion int
+	Problems        []string
+}
+
+func (err *IncludeCompatibilityError) Error() string {
+	return fmt.Sprintf(
+		"include %s v%d is not compatible with property %s v%d: %s",
+		err.IncludeID,
+		err.IncludeVersion,
+		err.PropertyID,
+		err.PropertyVersion,
+		strings.Join(err.Problems, "; "),
+	)
+}
+
+// CheckIncludeCompatibility verifies a property rule tree can reference an
+// include rule tree
+//
+// Both must belong to the same contract and use the same rule format. A
+// frozen rule format and "latest" are never compatible, as the include
+// would change under the property at the next rule format release.
+// It returns an *IncludeCompatibilityError describing every problem found.
+func CheckIncludeCompatibility(includeRules *IncludeRules, rules *Rules) error {
+	problems := []string{}
+
+	if includeRules.ContractID != "" && rules.ContractID != "" && includeRules.ContractID != rules.ContractID {
+		problems = append(problems, fmt.Sprintf("include contract %s differs from property contract %s", includeRules.ContractID, rules.ContractID))
+	}
+
+	if includeRules.RuleFormat != rules.RuleFormat {
+		problems = append(problems, fmt.Sprintf("include rule format %s differs from property rule format %s", includeRules.RuleFormat, rules.RuleFormat))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return &IncludeCompatibilityError{
+		IncludeID:       includeRules.IncludeID,
+		IncludeVersion:  includeRules.IncludeVersion,
+		PropertyID:      rules.PropertyID,
+		PropertyVersion: rules.PropertyVersion,
+		Problems:        problems,
+	}
+}
+
+// CheckParents verifies the staging and production versions of every
+// property referencing the include can use the include version of
+// includeRules
+//
+// This is meant to be run before activating an include version, it returns
+// the incompatible property versions.
+func (include *Include) CheckParents(includeRules *IncludeRules) ([]*IncludeCompatibilityError, error) {
+	parents, err := include.GetParents()
+	if err != nil {
+		return nil, err
+	}
+
+	var incompatible []*IncludeCompatibilityError
+	for _, parent := range parents {
+		property := parent.Property()
+
+		versions := []int{}
+		if parent.IsIncludeUsedInStagingVersion && parent.StagingVersion != 0 {
+			versions = append(versions, parent.StagingVersion)
+		}
+		if parent.IsIncludeUsedInProductionVersion && parent.ProductionVersion != 0 && parent.ProductionVersion != parent.StagingVersion {
+			versions = append(versions, parent.ProductionVersion)
+		}
+
+		for _, version := range versions {
+			rules := NewRules()
+			if err := rules.GetRulesForVersion(property, version); err != nil {
+				return nil, err
+			}
+
+			if err := CheckIncludeCompatibility(includeRules, rules); err != nil {
+				incompatible = append(incompatible, err.(*IncludeCompatibilityError))
+			}
+		}
+	}
+
+	return incompatible, nil
+}
+
+// IncludeReference is an include used by an include behavior within a rule tree
+type IncludeReference struct {
+	IncludeID string
+	Path      string
+	Rule      *Rule
+	Behavior  *Behavior
+}
+
+// FindIncludeReferences returns every include referenced by an include behavior
+//
+// See: Rules.SelectBehaviors()
+func (rules *Rules) FindIncludeReferences() ([]*IncludeReference, error) {
+	matches, err := rules.SelectBehaviors("/**/include[id]")
+	if err != nil {
+		return nil, err
+	}
+
+	var references []*IncludeReference
+	for _, match := range matches {
+		id, ok := match.Behavior.Options["id"].(string)
+		if !ok || id == "" {
+			continue
+		}
+
+		references = append(references, &IncludeReference{
+			IncludeID: id,
+			Path:      match.Path,
+			Rule:      match.Rule,
+			Behavior:  match.Behavior,
+		})
+	}
+
+	return references, nil
+}
@@ -0,0 +1,237 @@
+package papi
+
+import (
+	"testing"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/jsonhooks-v1"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func newIncludeTestInclude() *Include {
+	include := NewInclude(NewIncludes())
+	include.IncludeID = "inc_1"
+	include.ContractID = "ctr_1"
+	include.GroupID = "grp_1"
+	include.LatestVersion = 2
+
+	return include
+}
+
+func TestIncludes_GetIncludes(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/includes").
+		MatchParam("contractId", "ctr_1").
+		MatchParam("groupId", "grp_1").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"includes": {"items": [
+			{"includeId": "inc_1", "includeName": "shared-origin", "includeType": "COMMON_SETTINGS", "latestVersion": 2, "productionVersion": 1},
+			{"includeId": "inc_2", "includeName": "api-routes", "includeType": "MICROSERVICES", "latestVersion": 1}
+		]}}`)
+
+	Init(config)
+
+	contract := NewContract(NewContracts())
+	contract.ContractID = "ctr_1"
+	group := NewGroup(NewGroups())
+	group.GroupID = "grp_1"
+
+	includes := NewIncludes()
+	if !assert.NoError(t, includes.GetIncludes(contract, group)) {
+		return
+	}
+	assert.True(t, gock.IsDone())
+	assert.Len(t, includes.Includes.Items, 2)
+
+	include, err := includes.FindInclude("api-routes")
+	assert.NoError(t, err)
+	assert.Equal(t, IncludeTypeMicroServices, include.IncludeType)
+
+	_, err = includes.FindInclude("inc_3")
+	assert.Error(t, err)
+}
+
+func TestInclude_Save(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Post("/papi/v1/includes").
+		JSON(map[string]interface{}{"contractId": "ctr_1", "groupId": "grp_1", "includeName": "shared-origin", "includeType": "COMMON_SETTINGS", "productId": "prd_SPM", "ruleFormat": "v2020-11-02"}).
+		Reply(201).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"includeLink": "/papi/v1/includes/inc_1?contractId=ctr_1&groupId=grp_1"}`)
+
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/includes/inc_1$").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"includes": {"items": [{"includeId": "inc_1", "includeName": "shared-origin", "includeType": "COMMON_SETTINGS", "contractId": "ctr_1", "groupId": "grp_1", "latestVersion": 1}]}}`)
+
+	Init(config)
+
+	include := NewIncludes().NewInclude()
+	include.ContractID = "ctr_1"
+	include.GroupID = "grp_1"
+	include.IncludeName = "shared-origin"
+	include.IncludeType = IncludeTypeCommonSettings
+	include.ProductID = "prd_SPM"
+	include.RuleFormat = "v2020-11-02"
+
+	assert.NoError(t, include.Save())
+	assert.True(t, gock.IsDone())
+	assert.Equal(t, "inc_1", include.IncludeID)
+	assert.Equal(t, 1, include.LatestVersion)
+	assert.Equal(t, "prd_SPM", include.ProductID)
+}
+
+func TestInclude_GetRules(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/includes/inc_1/versions/2/rules$").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{
+			"includeId": "inc_1",
+			"includeVersion": 2,
+			"includeType": "COMMON_SETTINGS",
+			"etag": "abc",
+			"ruleFormat": "v2020-11-02",
+			"rules": {"name": "default", "behaviors": [{"name": "origin", "options": {"hostname": "origin.example.com"}}]}
+		}`)
+
+	gock.New(orchestratorTestURL).
+		Put("/papi/v1/includes/inc_1/versions/2/rules$").
+		MatchHeader("If-Match", "abc").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"includeId": "inc_1", "includeVersion": 2, "etag": "def", "ruleFormat": "v2020-11-02", "rules": {"name": "default"}}`)
+
+	Init(config)
+
+	include := newIncludeTestInclude()
+	rules, err := include.GetRules(0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, rules.IncludeVersion)
+
+	behaviors, err := rules.Rules().SelectBehaviors("/origin")
+	assert.NoError(t, err)
+	assert.Len(t, behaviors, 1)
+
+	assert.NoError(t, rules.SaveStrict())
+	assert.Equal(t, "def", rules.Etag)
+	assert.True(t, gock.IsDone())
+}
+
+func TestIncludeActivation_Save(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Post("/papi/v1/includes/inc_1/activations").
+		Reply(400).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"warnings": [{"messageId": "msg_1", "detail": "check parents"}]}`)
+
+	gock.New(orchestratorTestURL).
+		Post("/papi/v1/includes/inc_1/activations").
+		JSON(map[string]interface{}{
+			"activationType":      "ACTIVATE",
+			"acknowledgeWarnings": []string{"msg_1"},
+			"complianceRecord":    map[string]string{"noncomplianceReason": "NO_PRODUCTION_TRAFFIC"},
+			"includeVersion":      2,
+			"network":             "STAGING",
+			"notifyEmails":        []string{"ops@example.com"},
+		}).
+		Reply(201).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"activationLink": "/papi/v1/includes/inc_1/activations/atv_1"}`)
+
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/includes/inc_1/activations/atv_1").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"activations": {"items": [{"activationId": "atv_1", "includeId": "inc_1", "includeVersion": 2, "network": "STAGING", "status": "PENDING"}]}}`)
+
+	Init(config)
+
+	activation := NewIncludeActivation()
+	activation.IncludeVersion = 2
+	activation.Network = NetworkStaging
+	activation.NotifyEmails = []string{"ops@example.com"}
+
+	assert.NoError(t, newIncludeTestInclude().Activate(activation, true))
+	assert.True(t, gock.IsDone())
+	assert.Equal(t, "atv_1", activation.ActivationID)
+	assert.Equal(t, StatusPending, activation.Status)
+}
+
+func TestInclude_CheckParents(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/includes/inc_1/parents").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"properties": {"items": [
+			{"propertyId": "prp_1", "contractId": "ctr_1", "stagingVersion": 3, "productionVersion": 2, "isIncludeUsedInStagingVersion": true, "isIncludeUsedInProductionVersion": true},
+			{"propertyId": "prp_2", "contractId": "ctr_1", "stagingVersion": 5, "isIncludeUsedInStagingVersion": false}
+		]}}`)
+
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_1/versions/3/rules$").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"propertyId": "prp_1", "propertyVersion": 3, "contractId": "ctr_1", "ruleFormat": "v2020-11-02", "rules": {"name": "default"}}`)
+
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_1/versions/2/rules$").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"propertyId": "prp_1", "propertyVersion": 2, "contractId": "ctr_1", "ruleFormat": "latest", "rules": {"name": "default"}}`)
+
+	Init(config)
+
+	includeRules := NewIncludeRules()
+	includeRules.IncludeID = "inc_1"
+	includeRules.IncludeVersion = 2
+	includeRules.ContractID = "ctr_1"
+	includeRules.RuleFormat = "v2020-11-02"
+
+	incompatible, err := newIncludeTestInclude().CheckParents(includeRules)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, gock.IsDone())
+
+	if assert.Len(t, incompatible, 1) {
+		assert.Equal(t, "prp_1", incompatible[0].PropertyID)
+		assert.Equal(t, 2, incompatible[0].PropertyVersion)
+		assert.Equal(t, "include inc_1 v2 is not compatible with property prp_1 v2: include rule format v2020-11-02 differs from property rule format latest", incompatible[0].Error())
+	}
+}
+
+func TestRules_FindIncludeReferences(t *testing.T) {
+	rules := NewRules()
+	err := jsonhooks.Unmarshal([]byte(`{"rules": {
+		"name": "default",
+		"behaviors": [{"name": "include", "options": {"id": "inc_1"}}],
+		"children": [{"name": "API", "behaviors": [{"name": "include", "options": {"id": "inc_2"}}]}]
+	}}`), rules)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	references, err := rules.FindIncludeReferences()
+	assert.NoError(t, err)
+	if assert.Len(t, references, 2) {
+		assert.Equal(t, "inc_1", references[0].IncludeID)
+		assert.Equal(t, "/include", references[0].Path)
+		assert.Equal(t, "inc_2", references[1].IncludeID)
+		assert.Equal(t, "/API/include", references[1].Path)
+	}
+}
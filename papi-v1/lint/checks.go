@@ -0,0 +1,507 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/papi-v1"
+)
+
+// DefaultChecks returns every check in this package with its default settings
+func DefaultChecks() []Check {
+	return []Check{
+		&DuplicateRuleNames{},
+		&EmptyRules{},
+		&UnreachableRules{},
+		&DuplicateBehaviors{},
+		&UndefinedVariables{},
+		&SensitiveVariables{},
+		&CachingTTL{MaxTTL: 365 * 24 * time.Hour},
+	}
+}
+
+// DuplicateRuleNames reports sibling rules that share a name, or names
+// containing "/", which Rules.FindRule can not tell apart
+type DuplicateRuleNames struct{}
+
+// ID implements Check
+func (check *DuplicateRuleNames) ID() string { return "duplicate-rule-name" }
+
+// Description implements Check
+func (check *DuplicateRuleNames) Description() string {
+	return "Sibling rules should have unique names so that they can be found by path"
+}
+
+// DefaultSeverity implements Check
+func (check *DuplicateRuleNames) DefaultSeverity() Severity { return SeverityWarning }
+
+// Run implements Check
+func (check *DuplicateRuleNames) Run(tree *Tree, report Reporter) error {
+	for _, node := range tree.Nodes {
+		seen := map[string]*Node{}
+		for _, child := range node.Children {
+			name := strings.ToLower(child.Rule.Name)
+			if first, ok := seen[name]; ok {
+				report(child.Path, "rule name %q is also used by %s", child.Rule.Name, first.Path)
+			} else {
+				seen[name] = child
+			}
+
+			if strings.Contains(child.Rule.Name, "/") {
+				report(child.Path, "rule name %q contains \"/\"", child.Rule.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// EmptyRules reports rules with neither behaviors nor child rules
+type EmptyRules struct{}
+
+// ID implements Check
+func (check *EmptyRules) ID() string { return "empty-rule" }
+
+// Description implements Check
+func (check *EmptyRules) Description() string {
+	return "Rules without behaviors or child rules have no effect"
+}
+
+// DefaultSeverity implements Check
+func (check *EmptyRules) DefaultSeverity() Severity { return SeverityWarning }
+
+// Run implements Check
+func (check *EmptyRules) Run(tree *Tree, report Reporter) error {
+	for _, node := range tree.Nodes {
+		if node.Parent != nil && len(node.Behaviors) == 0 && len(node.Children) == 0 {
+			report(node.Path, "rule has no behaviors and no child rules")
+		}
+	}
+
+	return nil
+}
+
+// UnreachableRules reports behaviors that can never take effect because a
+// rule evaluated later, which matches every request, sets the same behavior
+//
+// When every behavior of a rule and its children is overridden, the rule
+// itself is reported.
+type UnreachableRules struct{}
+
+// ID implements Check
+func (check *UnreachableRules) ID() string { return "unreachable-rule" }
+
+// Description implements Check
+func (check *UnreachableRules) Description() string {
+	return "Behaviors overridden by a later rule matching all requests never apply"
+}
+
+// DefaultSeverity implements Check
+func (check *UnreachableRules) DefaultSeverity() Severity { return SeverityWarning }
+
+// Run implements Check
+func (check *UnreachableRules) Run(tree *Tree, report Reporter) error {
+	if tree.Root == nil {
+		return nil
+	}
+
+	_, findings := check.run(tree.Root, map[string]string{})
+	for _, finding := range findings {
+		report(finding.path, "%s", finding.message)
+	}
+
+	return nil
+}
+
+type pendingFinding struct {
+	path    string
+	message string
+}
+
+// run finds the overridden behaviors of node, given the behaviors overridden
+// by rules after node or one of its ancestors, and reports whether every
+// behavior of node and its children is overridden
+func (check *UnreachableRules) run(node *Node, inherited map[string]string) (bool, []pendingFinding) {
+	own := copyOverrides(inherited)
+	for _, child := range node.Children {
+		if matchesAll(child.Rule) {
+			alwaysApplied(child, own)
+		}
+	}
+
+	total := 0
+	var findings []pendingFinding
+	for _, behavior := range node.Behaviors {
+		if papi.IsCumulativeBehavior(behavior.Behavior.Name) {
+			continue
+		}
+		total++
+		if by, ok := own[strings.ToLower(behavior.Behavior.Name)]; ok {
+			findings = append(findings, pendingFinding{behavior.Path, fmt.Sprintf("behavior is always overridden by %s", by)})
+		}
+	}
+	allOverridden := len(findings) == total
+
+	for key, child := range node.Children {
+		childInherited := copyOverrides(inherited)
+		for _, later := range node.Children[key+1:] {
+			if matchesAll(later.Rule) {
+				alwaysApplied(later, childInherited)
+			}
+		}
+
+		childOverridden, childFindings := check.run(child, childInherited)
+		allOverridden = allOverridden && childOverridden
+		findings = append(findings, childFindings...)
+	}
+
+	if total == 0 && len(node.Children) == 0 {
+		return false, nil
+	}
+
+	if allOverridden && node.Parent != nil {
+		findings = []pendingFinding{{node.Path, "every behavior is overridden by later rules matching all requests"}}
+	}
+
+	return allOverridden, findings
+}
+
+// alwaysApplied adds the behaviors applied whenever node matches, including
+// those of its children matching all requests
+func alwaysApplied(node *Node, overrides map[string]string) {
+	for _, behavior := range node.Behaviors {
+		if !papi.IsCumulativeBehavior(behavior.Behavior.Name) {
+			overrides[strings.ToLower(behavior.Behavior.Name)] = behavior.Path
+		}
+	}
+
+	for _, child := range node.Children {
+		if matchesAll(child.Rule) {
+			alwaysApplied(child, overrides)
+		}
+	}
+}
+
+func copyOverrides(overrides map[string]string) map[string]string {
+	newOverrides := make(map[string]string, len(overrides))
+	for name, path := range overrides {
+		newOverrides[name] = path
+	}
+
+	return newOverrides
+}
+
+// matchesAll reports whether a rule matches every request
+func matchesAll(rule *papi.Rule) bool {
+	if len(rule.Criteria) == 0 {
+		return true
+	}
+
+	matchAny := rule.CriteriaMustSatisfy == papi.RuleCriteriaMustSatisfyAny
+	for _, criteria := range rule.Criteria {
+		trivial := criteriaMatchesAll(criteria)
+		if matchAny && trivial {
+			return true
+		}
+		if !matchAny && !trivial {
+			return false
+		}
+	}
+
+	return !matchAny
+}
+
+func criteriaMatchesAll(criteria *papi.Criteria) bool {
+	operator, _ := criteria.Options["matchOperator"].(string)
+	values, _ := criteria.Options["values"].([]interface{})
+	switch {
+	case criteria.Name == "path" && operator == "MATCHES_ONE_OF",
+		criteria.Name == "hostname" && operator == "IS_ONE_OF":
+		for _, value := range values {
+			if value == "*" || value == "/*" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// DuplicateBehaviors reports behaviors set more than once in the same rule,
+// where only the last one applies
+type DuplicateBehaviors struct{}
+
+// ID implements Check
+func (check *DuplicateBehaviors) ID() string { return "duplicate-behavior" }
+
+// Description implements Check
+func (check *DuplicateBehaviors) Description() string {
+	return "A behavior should appear at most once per rule"
+}
+
+// DefaultSeverity implements Check
+func (check *DuplicateBehaviors) DefaultSeverity() Severity { return SeverityWarning }
+
+// Run implements Check
+func (check *DuplicateBehaviors) Run(tree *Tree, report Reporter) error {
+	for _, node := range tree.Nodes {
+		seen := map[string]string{}
+		for _, behavior := range node.Behaviors {
+			name := strings.ToLower(behavior.Behavior.Name)
+			if papi.IsCumulativeBehavior(name) {
+				continue
+			}
+
+			if first, ok := seen[name]; ok {
+				report(first, "behavior %s is overridden by %s in the same rule", behavior.Behavior.Name, behavior.Path)
+			}
+			seen[name] = behavior.Path
+		}
+	}
+
+	return nil
+}
+
+var variableReference = regexp.MustCompile(`\{\{\s*user\.(PMUSER_[A-Za-z0-9_]+)\s*\}\}`)
+
+// variableReferences returns the user variables referenced by options, in
+// order of appearance
+func variableReferences(options papi.OptionValue) []string {
+	var names []string
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case string:
+			for _, match := range variableReference.FindAllStringSubmatch(v, -1) {
+				names = append(names, match[1])
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(v[key])
+			}
+		case papi.OptionValue:
+			walk(map[string]interface{}(v))
+		}
+	}
+	walk(options)
+
+	return names
+}
+
+// UndefinedVariables reports {{user.PMUSER_*}} references, and setVariable
+// behaviors, using variables not defined in the rule tree
+type UndefinedVariables struct{}
+
+// ID implements Check
+func (check *UndefinedVariables) ID() string { return "undefined-variable" }
+
+// Description implements Check
+func (check *UndefinedVariables) Description() string {
+	return "User variables must be defined before they are referenced"
+}
+
+// DefaultSeverity implements Check
+func (check *UndefinedVariables) DefaultSeverity() Severity { return SeverityError }
+
+// Run implements Check
+func (check *UndefinedVariables) Run(tree *Tree, report Reporter) error {
+	for _, node := range tree.Nodes {
+		for _, behavior := range node.Behaviors {
+			names := variableReferences(behavior.Behavior.Options)
+			if strings.EqualFold(behavior.Behavior.Name, "setVariable") {
+				if name, ok := behavior.Behavior.Options["variableName"].(string); ok {
+					names = append(names, name)
+				}
+			}
+			check.report(tree, behavior.Path, names, report)
+		}
+
+		for _, criteria := range node.Criteria {
+			names := variableReferences(criteria.Criteria.Options)
+			if strings.EqualFold(criteria.Criteria.Name, "matchVariable") {
+				if name, ok := criteria.Criteria.Options["variableName"].(string); ok {
+					names = append(names, name)
+				}
+			}
+			check.report(tree, criteria.Path, names, report)
+		}
+	}
+
+	return nil
+}
+
+func (check *UndefinedVariables) report(tree *Tree, path string, names []string, report Reporter) {
+	reported := map[string]bool{}
+	for _, name := range names {
+		if _, ok := tree.Variables[name]; !ok && !reported[name] {
+			report(path, "variable %s is not defined", name)
+			reported[name] = true
+		}
+	}
+}
+
+// exposingBehaviors send option values back to the client
+var exposingBehaviors = map[string]bool{
+	"modifyoutgoingresponseheader": true,
+	"modifyincomingresponseheader": true,
+	"constructresponse":            true,
+	"redirect":                     true,
+	"redirectplus":                 true,
+}
+
+// SensitiveVariables reports sensitive variables that are not hidden, and
+// hidden or sensitive variables sent back to the client
+type SensitiveVariables struct{}
+
+// ID implements Check
+func (check *SensitiveVariables) ID() string { return "sensitive-variable" }
+
+// Description implements Check
+func (check *SensitiveVariables) Description() string {
+	return "Sensitive variables must be hidden and must not be exposed in responses"
+}
+
+// DefaultSeverity implements Check
+func (check *SensitiveVariables) DefaultSeverity() Severity { return SeverityError }
+
+// Run implements Check
+func (check *SensitiveVariables) Run(tree *Tree, report Reporter) error {
+	for _, node := range tree.Nodes {
+		for _, variable := range node.Rule.Variables {
+			if variable.Sensitive && !variable.Hidden {
+				report(node.Path, "sensitive variable %s must also be hidden", variable.Name)
+			}
+		}
+
+		for _, behavior := range node.Behaviors {
+			if !exposingBehaviors[strings.ToLower(behavior.Behavior.Name)] {
+				continue
+			}
+
+			for _, name := range variableReferences(behavior.Behavior.Options) {
+				variable, ok := tree.Variables[name]
+				switch {
+				case !ok:
+				case variable.Sensitive:
+					report(behavior.Path, "sensitive variable %s is exposed by %s", name, behavior.Behavior.Name)
+				case variable.Hidden:
+					report(behavior.Path, "hidden variable %s is exposed by %s", name, behavior.Behavior.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// CachingTTL reports caching behaviors with a missing, malformed or
+// excessive TTL, or a TTL that is ignored
+type CachingTTL struct {
+	// MaxTTL is the longest acceptable TTL, zero disables the limit
+	MaxTTL time.Duration
+}
+
+// ID implements Check
+func (check *CachingTTL) ID() string { return "caching-ttl" }
+
+// Description implements Check
+func (check *CachingTTL) Description() string {
+	return "Caching TTLs should be valid durations within a sane range"
+}
+
+// DefaultSeverity implements Check
+func (check *CachingTTL) DefaultSeverity() Severity { return SeverityWarning }
+
+// Run implements Check
+func (check *CachingTTL) Run(tree *Tree, report Reporter) error {
+	for _, node := range tree.Nodes {
+		for _, behavior := range node.Behaviors {
+			if !strings.EqualFold(behavior.Behavior.Name, "caching") {
+				continue
+			}
+
+			options := behavior.Behavior.Options
+			mode, _ := options["behavior"].(string)
+			key := "ttl"
+			switch mode {
+			case "MAX_AGE":
+			case "CACHE_CONTROL", "EXPIRES", "CACHE_CONTROL_AND_EXPIRES":
+				key = "defaultTtl"
+			case "NO_STORE", "BYPASS_CACHE":
+				if ttl, ok := options["ttl"].(string); ok && ttl != "" {
+					report(behavior.Path, "ttl %s is ignored with behavior %s", ttl, mode)
+				}
+				continue
+			default:
+				continue
+			}
+
+			ttl, ok := options[key].(string)
+			if !ok || ttl == "" {
+				report(behavior.Path, "behavior %s requires %s", mode, key)
+				continue
+			}
+
+			duration, err := ParseTTL(ttl)
+			switch {
+			case err != nil:
+				report(behavior.Path, "%s %q is not a valid TTL", key, ttl)
+			case duration == 0 && mode == "MAX_AGE":
+				report(behavior.Path, "%s of 0 revalidates every request, consider NO_STORE or BYPASS_CACHE", key)
+			case check.MaxTTL > 0 && duration > check.MaxTTL:
+				report(behavior.Path, "%s %s is longer than %s", key, ttl, FormatTTL(check.MaxTTL))
+			}
+		}
+	}
+
+	return nil
+}
+
+var ttlUnits = []struct {
+	suffix string
+	unit   time.Duration
+}{
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+// ParseTTL parses a PAPI TTL such as "30s", "5m", "1h" or "7d"
+func ParseTTL(ttl string) (time.Duration, error) {
+	for _, unit := range ttlUnits {
+		if strings.HasSuffix(ttl, unit.suffix) {
+			value, err := strconv.Atoi(strings.TrimSuffix(ttl, unit.suffix))
+			if err != nil || value < 0 {
+				break
+			}
+			return time.Duration(value) * unit.unit, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid TTL %q", ttl)
+}
+
+// FormatTTL formats a duration as a PAPI TTL, using the largest exact unit
+func FormatTTL(duration time.Duration) string {
+	for _, unit := range ttlUnits {
+		if duration%unit.unit == 0 {
+			return fmt.Sprintf("%d%s", duration/unit.unit, unit.suffix)
+		}
+	}
+
+	return fmt.Sprintf("%ds", duration/time.Second)
+}
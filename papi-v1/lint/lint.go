@@ -0,0 +1,137 @@
+// Package lint checks PAPI rule trees for common mistakes
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/papi-v1"
+)
+
+// Severity is used to create an "enum" of possible Finding.Severity values
+//
+// The values match SARIF result levels.
+type Severity string
+
+const (
+	// SeverityError the rule tree is likely broken
+	SeverityError Severity = "error"
+	// SeverityWarning the rule tree likely does not do what was intended
+	SeverityWarning Severity = "warning"
+	// SeverityNote the rule tree could be simplified
+	SeverityNote Severity = "note"
+	// SeverityOff disables a check when used in Linter.Severities
+	SeverityOff Severity = "none"
+)
+
+// Finding is a problem reported by a Check
+//
+// Path is the selector of the offending rule, behavior or criteria, see
+// papi.Rules.Select.
+type Finding struct {
+	CheckID  string   `json:"checkId"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`
+	Message  string   `json:"message"`
+}
+
+// String returns a one line description of the finding
+func (finding *Finding) String() string {
+	return fmt.Sprintf("%s: %s [%s] %s", finding.Path, finding.Severity, finding.CheckID, finding.Message)
+}
+
+// Reporter records a finding for the given path
+type Reporter func(path string, format string, args ...interface{})
+
+// Check is a single lint check
+type Check interface {
+	// ID is a short, stable identifier such as "duplicate-rule-name"
+	ID() string
+	Description() string
+	DefaultSeverity() Severity
+	Run(tree *Tree, report Reporter) error
+}
+
+// Linter runs checks against rule trees
+type Linter struct {
+	Checks []Check
+	// Severities overrides the severity of checks by ID, use SeverityOff
+	// to disable a check
+	Severities map[string]Severity
+}
+
+// New creates a new Linter running checks, or DefaultChecks if none are given
+func New(checks ...Check) *Linter {
+	if len(checks) == 0 {
+		checks = DefaultChecks()
+	}
+
+	return &Linter{Checks: checks, Severities: map[string]Severity{}}
+}
+
+// Lint runs every enabled check against rules
+func (linter *Linter) Lint(rules *papi.Rules) (*Report, error) {
+	tree, err := NewTree(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	for _, check := range linter.Checks {
+		severity := check.DefaultSeverity()
+		if override, ok := linter.Severities[check.ID()]; ok {
+			severity = override
+		}
+		if severity == SeverityOff {
+			continue
+		}
+
+		report.Checks = append(report.Checks, check)
+		err := check.Run(tree, func(path string, format string, args ...interface{}) {
+			report.Findings = append(report.Findings, &Finding{
+				CheckID:  check.ID(),
+				Severity: severity,
+				Path:     path,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("check %s: %s", check.ID(), err)
+		}
+	}
+
+	return report, nil
+}
+
+// Report holds the findings of Linter.Lint
+type Report struct {
+	Checks   []Check    `json:"-"`
+	Findings []*Finding `json:"findings"`
+}
+
+// HasErrors reports whether any finding has SeverityError
+func (report *Report) HasErrors() bool {
+	for _, finding := range report.Findings {
+		if finding.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteJSON writes the findings as a JSON document
+func (report *Report) WriteJSON(w io.Writer) error {
+	findings := report.Findings
+	if findings == nil {
+		findings = []*Finding{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(struct {
+		Findings []*Finding `json:"findings"`
+	}{findings})
+}
@@ -0,0 +1,149 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/jsonhooks-v1"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/papi-v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRules(t *testing.T) *papi.Rules {
+	rules := papi.NewRules()
+	err := jsonhooks.Unmarshal([]byte(`{
+		"rules": {
+			"name": "default",
+			"variables": [
+				{"name": "PMUSER_ORIGIN", "value": "origin.example.com", "hidden": false, "sensitive": false},
+				{"name": "PMUSER_TOKEN", "value": "secret", "hidden": false, "sensitive": true}
+			],
+			"behaviors": [
+				{"name": "origin", "options": {"hostname": "{{user.PMUSER_ORIGIN}}"}},
+				{"name": "caching", "options": {"behavior": "MAX_AGE", "ttl": "1d"}},
+				{"name": "caching", "options": {"behavior": "MAX_AGE", "ttl": "2y"}}
+			],
+			"children": [
+				{
+					"name": "Static",
+					"criteria": [
+						{"name": "path", "options": {"matchOperator": "MATCHES_ONE_OF", "values": ["/static/*"]}}
+					],
+					"behaviors": [
+						{"name": "caching", "options": {"behavior": "MAX_AGE", "ttl": "400d"}}
+					]
+				},
+				{
+					"name": "Static",
+					"behaviors": [
+						{"name": "modifyOutgoingResponseHeader", "options": {"newHeaderValue": "{{user.PMUSER_TOKEN}} {{user.PMUSER_MISSING}}"}}
+					]
+				},
+				{
+					"name": "Empty"
+				},
+				{
+					"name": "Everything",
+					"criteria": [
+						{"name": "path", "options": {"matchOperator": "MATCHES_ONE_OF", "values": ["/*"]}}
+					],
+					"behaviors": [
+						{"name": "caching", "options": {"behavior": "NO_STORE", "ttl": "1h"}},
+						{"name": "setVariable", "options": {"variableName": "PMUSER_UNDEFINED"}}
+					]
+				}
+			]
+		}
+	}`), rules)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	return rules
+}
+
+func findingsFor(report *Report, checkID string) []string {
+	var findings []string
+	for _, finding := range report.Findings {
+		if finding.CheckID == checkID {
+			findings = append(findings, finding.Path)
+		}
+	}
+
+	return findings
+}
+
+func TestLinter_Lint(t *testing.T) {
+	report, err := New().Lint(newTestRules(t))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []string{"/Static[1]"}, findingsFor(report, "duplicate-rule-name"))
+	assert.Equal(t, []string{"/Empty"}, findingsFor(report, "empty-rule"))
+	assert.Equal(t, []string{"/caching[0]", "/caching[1]", "/Static[0]"}, findingsFor(report, "unreachable-rule"))
+	assert.Equal(t, []string{"/caching[0]"}, findingsFor(report, "duplicate-behavior"))
+	assert.Equal(t, []string{"/Static[1]/modifyOutgoingResponseHeader", "/Everything/setVariable"}, findingsFor(report, "undefined-variable"))
+	assert.Equal(t, []string{"/", "/Static[1]/modifyOutgoingResponseHeader"}, findingsFor(report, "sensitive-variable"))
+	assert.Equal(t, []string{"/caching[1]", "/Static[0]/caching", "/Everything/caching"}, findingsFor(report, "caching-ttl"))
+	assert.True(t, report.HasErrors())
+}
+
+func TestLinter_Severities(t *testing.T) {
+	linter := New(&EmptyRules{}, &CachingTTL{})
+	linter.Severities["empty-rule"] = SeverityOff
+	linter.Severities["caching-ttl"] = SeverityError
+
+	report, err := linter.Lint(newTestRules(t))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, report.Checks, 1)
+	assert.Empty(t, findingsFor(report, "empty-rule"))
+	assert.Equal(t, []string{"/caching[1]", "/Everything/caching"}, findingsFor(report, "caching-ttl"))
+	assert.Equal(t, SeverityError, report.Findings[0].Severity)
+}
+
+func TestReport_WriteSARIF(t *testing.T) {
+	report, err := New(&EmptyRules{}).Lint(newTestRules(t))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, report.WriteSARIF(buf, "rules.json"))
+
+	var log map[string]interface{}
+	if assert.NoError(t, json.Unmarshal(buf.Bytes(), &log)) {
+		assert.Equal(t, "2.1.0", log["version"])
+		run := log["runs"].([]interface{})[0].(map[string]interface{})
+		results := run["results"].([]interface{})
+		if assert.Len(t, results, 1) {
+			result := results[0].(map[string]interface{})
+			assert.Equal(t, "empty-rule", result["ruleId"])
+			assert.Equal(t, "warning", result["level"])
+		}
+	}
+
+	buf.Reset()
+	assert.NoError(t, report.WriteJSON(buf))
+	assert.Contains(t, buf.String(), `"checkId": "empty-rule"`)
+}
+
+func TestParseTTL(t *testing.T) {
+	duration, err := ParseTTL("7d")
+	assert.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, duration)
+
+	_, err = ParseTTL("7w")
+	assert.Error(t, err)
+
+	_, err = ParseTTL("d")
+	assert.Error(t, err)
+
+	assert.Equal(t, "365d", FormatTTL(365*24*time.Hour))
+	assert.Equal(t, "90m", FormatTTL(90*time.Minute))
+}
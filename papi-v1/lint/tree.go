@@ -0,0 +1,86 @@
+package lint
+
+import (
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/papi-v1"
+)
+
+// Node is a rule of a Tree, with the selector paths of its content
+type Node struct {
+	Rule      *papi.Rule
+	Parent    *Node
+	Path      string
+	Children  []*Node
+	Behaviors []*papi.BehaviorMatch
+	Criteria  []*papi.CriteriaMatch
+}
+
+// Tree is a rule tree prepared for checks
+//
+// Nodes lists every rule in tree order, starting with the default rule.
+// Variables holds the variables defined anywhere in the tree, by name.
+type Tree struct {
+	Rules     *papi.Rules
+	Root      *Node
+	Nodes     []*Node
+	Variables map[string]*papi.Variable
+}
+
+// NewTree creates a new Tree
+func NewTree(rules *papi.Rules) (*Tree, error) {
+	tree := &Tree{Rules: rules, Variables: map[string]*papi.Variable{}}
+	if rules == nil || rules.Rule == nil {
+		return tree, nil
+	}
+
+	matches, err := rules.Select("/**")
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := map[*papi.Rule]*Node{}
+	for _, match := range matches {
+		node := &Node{Rule: match.Rule, Path: match.Path}
+		if parent, ok := nodes[match.Parent]; ok && match.Parent != nil {
+			node.Parent = parent
+			parent.Children = append(parent.Children, node)
+		} else {
+			tree.Root = node
+		}
+		nodes[match.Rule] = node
+		tree.Nodes = append(tree.Nodes, node)
+
+		for _, variable := range match.Rule.Variables {
+			tree.Variables[variable.Name] = variable
+		}
+	}
+
+	behaviors, err := rules.SelectBehaviors("/**/*")
+	if err != nil {
+		return nil, err
+	}
+	for _, behavior := range behaviors {
+		node := nodes[behavior.Rule]
+		node.Behaviors = append(node.Behaviors, behavior)
+	}
+
+	criteria, err := rules.SelectCriteria("/**/*")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range criteria {
+		node := nodes[c.Rule]
+		node.Criteria = append(node.Criteria, c)
+	}
+
+	return tree, nil
+}
+
+// Descendants returns the node and every node below it, in tree order
+func (node *Node) Descendants() []*Node {
+	nodes := []*Node{node}
+	for _, child := range node.Children {
+		nodes = append(nodes, child.Descendants()...)
+	}
+
+	return nodes
+}
@@ -74,6 +74,23 @@ func (ruleFormats *RuleFormats) GetLatest() (string, error) {
 // API Docs: https://developer.akamai.com/api/luna/papi/resources.html#getaruleformatsschema
 // Endpoint: /papi/v1/schemas/products/{productId}/{ruleFormat}
 func (ruleFormats *RuleFormats) GetSchema(product string, ruleFormat string) (*gojsonschema.Schema, error) {
+	schemaBytes, err := ruleFormats.GetSchemaJSON(product, ruleFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	loader := gojsonschema.NewBytesLoader(schemaBytes)
+	schema, err := gojsonschema.NewSchema(loader)
+
+	return schema, err
+}
+
+// GetSchemaJSON fetches the raw JSON schema for a given product and rule format
+//
+// See: RuleFormats.GetSchema()
+// API Docs: https://developer.akamai.com/api/luna/papi/resources.html#getaruleformatsschema
+// Endpoint: /papi/v1/schemas/products/{productId}/{ruleFormat}
+func (ruleFormats *RuleFormats) GetSchemaJSON(product string, ruleFormat string) ([]byte, error) {
 	req, err := client.NewRequest(
 		Config,
 		"GET",
@@ -97,10 +114,5 @@ func (ruleFormats *RuleFormats) GetSchema(product string, ruleFormat string) (*g
 		return nil, client.NewAPIError(res)
 	}
 
-	schemaBytes, _ := ioutil.ReadAll(res.Body)
-	schemaBody := string(schemaBytes)
-	loader := gojsonschema.NewStringLoader(schemaBody)
-	schema, err := gojsonschema.NewSchema(loader)
-
-	return schema, err
+	return ioutil.ReadAll(res.Body)
 }
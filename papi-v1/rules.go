@@ -1,6 +1,7 @@
 package papi
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -41,17 +42,28 @@ func (rules *Rules) PreMarshalJSON() error {
 
 // GetRules populates Rules with rule data for a given property
 //
+// The property's latest version is used, see Rules.GetRulesForVersion() to
+// retrieve another version.
+//
 // See: Property.GetRules
 // API Docs: https://developer.akamai.com/api/luna/papi/resources.html#getaruletree
 // Endpoint: GET /papi/v1/properties/{propertyId}/versions/{propertyVersion}/rules/{?contractId,groupId}
 func (rules *Rules) GetRules(property *Property) error {
+	return rules.GetRulesForVersion(property, property.LatestVersion)
+}
+
+// GetRulesForVersion populates Rules with rule data for a given property version
+//
+// API Docs: https://developer.akamai.com/api/luna/papi/resources.html#getaruletree
+// Endpoint: GET /papi/v1/properties/{propertyId}/versions/{propertyVersion}/rules/{?contractId,groupId}
+func (rules *Rules) GetRulesForVersion(property *Property, version int) error {
 	req, err := client.NewRequest(
 		Config,
 		"GET",
 		fmt.Sprintf(
 			"/papi/v1/properties/%s/versions/%d/rules",
 			property.PropertyID,
-			property.LatestVersion,
+			version,
 		),
 		nil,
 	)
@@ -112,6 +124,25 @@ func (rules *Rules) GetRulesDigest(property *Property) (string, error) {
 // API Docs: https://developer.akamai.com/api/luna/papi/resources.html#putpropertyversionrules
 // Endpoint: PUT /papi/v1/properties/{propertyId}/versions/{propertyVersion}/rules{?contractId,groupId}
 func (rules *Rules) Save() error {
+	return rules.save(false)
+}
+
+// SaveStrict updates a rule tree only if it is unchanged since it was retrieved
+//
+// Rules.Etag is sent as If-Match, so the API rejects the update with
+// 412 Precondition Failed when the rule tree was modified concurrently.
+//
+// API Docs: https://developer.akamai.com/api/luna/papi/resources.html#putpropertyversionrules
+// Endpoint: PUT /papi/v1/properties/{propertyId}/versions/{propertyVersion}/rules{?contractId,groupId}
+func (rules *Rules) SaveStrict() error {
+	if rules.Etag == "" {
+		return errors.New("rules have no etag, did you call GetRules()?")
+	}
+
+	return rules.save(true)
+}
+
+func (rules *Rules) save(strict bool) error {
 	rules.Errors = []*RuleErrors{}
 
 	req, err := client.NewJSONRequest(
@@ -128,6 +159,10 @@ func (rules *Rules) Save() error {
 		return err
 	}
 
+	if strict {
+		req.Header.Set("If-Match", rules.Etag)
+	}
+
 	res, err := client.Do(Config, req)
 	if err != nil {
 		return err
@@ -335,6 +370,8 @@ func (rule *Rule) AddVariable(variable *Variable) {
 }
 
 // FindBehavior locates a specific behavior by path
+//
+// See: Rules.SelectBehaviors() for duplicate names and wildcards
 func (rules *Rules) FindBehavior(path string) (*Behavior, error) {
 	if len(path) <= 1 {
 		return nil, ErrorMap[ErrInvalidPath]
@@ -404,6 +441,8 @@ func (rules *Rules) FindVariable(path string) (*Variable, error) {
 }
 
 // FindRule locates a specific rule by path
+//
+// See: Rules.Select() for duplicate names and wildcards
 func (rules *Rules) FindRule(path string) (*Rule, error) {
 	if path == "" {
 		return rules.Rule, nil
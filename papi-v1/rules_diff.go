@@ -0,0 +1,339 @@
+package papi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeTypeValue is used to create an "enum" of possible change types
+type ChangeTypeValue string
+
+const (
+	// ChangeTypeAdded the item exists only in the new version
+	ChangeTypeAdded ChangeTypeValue = "ADDED"
+	// ChangeTypeRemoved the item exists only in the old version
+	ChangeTypeRemoved ChangeTypeValue = "REMOVED"
+	// ChangeTypeModified the item exists in both versions with different values
+	ChangeTypeModified ChangeTypeValue = "MODIFIED"
+)
+
+// RuleChange is a single difference between two rule trees
+//
+// Kind is one of "rule", "behavior", "criteria" or "variable". Path is the
+// selector of the changed item (see Rules.Select), and Field names the
+// modified rule attribute, if any.
+type RuleChange struct {
+	Type  ChangeTypeValue
+	Kind  string
+	Path  string
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// String returns a one line description of the change
+func (change *RuleChange) String() string {
+	switch change.Type {
+	case ChangeTypeAdded:
+		return fmt.Sprintf("+ %s %s", change.Kind, change.Path)
+	case ChangeTypeRemoved:
+		return fmt.Sprintf("- %s %s", change.Kind, change.Path)
+	}
+
+	if change.Field != "" {
+		return fmt.Sprintf("~ %s %s %s: %s => %s", change.Kind, change.Path, change.Field, diffValue(change.Old), diffValue(change.New))
+	}
+
+	return fmt.Sprintf("~ %s %s: %s => %s", change.Kind, change.Path, diffValue(change.Old), diffValue(change.New))
+}
+
+// HostnameChange is a single difference between two hostname lists
+type HostnameChange struct {
+	Type      ChangeTypeValue
+	CnameFrom string
+	Old       *Hostname
+	New       *Hostname
+}
+
+// String returns a one line description of the change
+func (change *HostnameChange) String() string {
+	switch change.Type {
+	case ChangeTypeAdded:
+		return fmt.Sprintf("+ hostname %s => %s", change.CnameFrom, hostnameTarget(change.New))
+	case ChangeTypeRemoved:
+		return fmt.Sprintf("- hostname %s => %s", change.CnameFrom, hostnameTarget(change.Old))
+	}
+
+	return fmt.Sprintf("~ hostname %s: %s => %s", change.CnameFrom, hostnameTarget(change.Old), hostnameTarget(change.New))
+}
+
+// VersionDiff holds the differences between two property versions
+type VersionDiff struct {
+	Rules     []*RuleChange
+	Hostnames []*HostnameChange
+}
+
+// Empty reports whether the versions are identical
+func (diff *VersionDiff) Empty() bool {
+	return len(diff.Rules) == 0 && len(diff.Hostnames) == 0
+}
+
+// String returns the differences, one per line
+func (diff *VersionDiff) String() string {
+	var lines []string
+	for _, change := range diff.Rules {
+		lines = append(lines, change.String())
+	}
+
+	for _, change := range diff.Hostnames {
+		lines = append(lines, change.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// DiffRules compares two rule trees
+//
+// Rules, behaviors and criteria are matched by name and position among
+// siblings of the same name, so a renamed rule is reported as removed and
+// added. Changes are returned in tree order.
+func DiffRules(old *Rules, new *Rules) []*RuleChange {
+	var changes []*RuleChange
+	var oldRule, newRule *Rule
+	if old != nil {
+		oldRule = old.Rule
+	}
+	if new != nil {
+		newRule = new.Rule
+	}
+
+	return diffRule(changes, "/", oldRule, newRule)
+}
+
+// DiffHostnames compares two hostname lists, matching hostnames by CnameFrom
+func DiffHostnames(old *Hostnames, new *Hostnames) []*HostnameChange {
+	oldItems := map[string]*Hostname{}
+	newItems := map[string]*Hostname{}
+	var names []string
+	if old != nil {
+		for _, hostname := range old.Hostnames.Items {
+			oldItems[strings.ToLower(hostname.CnameFrom)] = hostname
+			names = append(names, strings.ToLower(hostname.CnameFrom))
+		}
+	}
+	if new != nil {
+		for _, hostname := range new.Hostnames.Items {
+			if _, ok := oldItems[strings.ToLower(hostname.CnameFrom)]; !ok {
+				names = append(names, strings.ToLower(hostname.CnameFrom))
+			}
+			newItems[strings.ToLower(hostname.CnameFrom)] = hostname
+		}
+	}
+	sort.Strings(names)
+
+	var changes []*HostnameChange
+	for _, name := range names {
+		oldHostname, newHostname := oldItems[name], newItems[name]
+		switch {
+		case oldHostname == nil:
+			changes = append(changes, &HostnameChange{Type: ChangeTypeAdded, CnameFrom: newHostname.CnameFrom, New: newHostname})
+		case newHostname == nil:
+			changes = append(changes, &HostnameChange{Type: ChangeTypeRemoved, CnameFrom: oldHostname.CnameFrom, Old: oldHostname})
+		case oldHostname.CnameType != newHostname.CnameType ||
+			oldHostname.EdgeHostnameID != newHostname.EdgeHostnameID ||
+			oldHostname.CnameTo != newHostname.CnameTo ||
+			oldHostname.CertEnrollmentId != newHostname.CertEnrollmentId:
+			changes = append(changes, &HostnameChange{Type: ChangeTypeModified, CnameFrom: newHostname.CnameFrom, Old: oldHostname, New: newHostname})
+		}
+	}
+
+	return changes
+}
+
+func diffRule(changes []*RuleChange, path string, old *Rule, new *Rule) []*RuleChange {
+	switch {
+	case old == nil && new == nil:
+		return changes
+	case old == nil:
+		return append(changes, &RuleChange{Type: ChangeTypeAdded, Kind: "rule", Path: path, New: new})
+	case new == nil:
+		return append(changes, &RuleChange{Type: ChangeTypeRemoved, Kind: "rule", Path: path, Old: old})
+	}
+
+	fields := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"criteriaMustSatisfy", old.CriteriaMustSatisfy, new.CriteriaMustSatisfy},
+		{"comments", old.Comments, new.Comments},
+		{"criteriaLocked", old.CriteriaLocked, new.CriteriaLocked},
+		{"advancedOverride", old.AdvancedOverride, new.AdvancedOverride},
+		{"options", old.Options, new.Options},
+		{"customOverride", old.CustomOverride, new.CustomOverride},
+	}
+	for _, field := range fields {
+		if !equalJSON(field.old, field.new) {
+			changes = append(changes, &RuleChange{Type: ChangeTypeModified, Kind: "rule", Path: path, Field: field.name, Old: field.old, New: field.new})
+		}
+	}
+
+	changes = diffNamed(changes, "criteria", path, criteriaItems(old.Criteria), criteriaItems(new.Criteria))
+	changes = diffNamed(changes, "behavior", path, behaviorItems(old.Behaviors), behaviorItems(new.Behaviors))
+	changes = diffNamed(changes, "variable", path, variableItems(old.Variables), variableItems(new.Variables))
+
+	oldChildren := childPaths(path, old.Children)
+	newChildren := childPaths(path, new.Children)
+	for _, child := range mergeKeys(oldChildren.keys, newChildren.keys) {
+		changes = diffRule(changes, child, oldChildren.rules[child], newChildren.rules[child])
+	}
+
+	return changes
+}
+
+type namedItem struct {
+	name  string
+	value interface{}
+}
+
+type pathIndex struct {
+	keys  []string
+	items map[string]interface{}
+	rules map[string]*Rule
+}
+
+func diffNamed(changes []*RuleChange, kind string, path string, old []namedItem, new []namedItem) []*RuleChange {
+	oldIndex := namedPaths(path, old)
+	newIndex := namedPaths(path, new)
+	for _, key := range mergeKeys(oldIndex.keys, newIndex.keys) {
+		oldItem, inOld := oldIndex.items[key]
+		newItem, inNew := newIndex.items[key]
+		switch {
+		case !inOld:
+			changes = append(changes, &RuleChange{Type: ChangeTypeAdded, Kind: kind, Path: key, New: newItem})
+		case !inNew:
+			changes = append(changes, &RuleChange{Type: ChangeTypeRemoved, Kind: kind, Path: key, Old: oldItem})
+		case !equalJSON(oldItem, newItem):
+			changes = append(changes, &RuleChange{Type: ChangeTypeModified, Kind: kind, Path: key, Old: oldItem, New: newItem})
+		}
+	}
+
+	return changes
+}
+
+func namedPaths(path string, items []namedItem) pathIndex {
+	index := pathIndex{items: map[string]interface{}{}}
+	names := make([]string, len(items))
+	for key, item := range items {
+		names[key] = item.name
+	}
+
+	for key, item := range items {
+		itemPath := joinSelectorPath(path, item.name, occurrence(names, key))
+		index.keys = append(index.keys, itemPath)
+		index.items[itemPath] = item.value
+	}
+
+	return index
+}
+
+func childPaths(path string, children []*Rule) pathIndex {
+	index := pathIndex{rules: map[string]*Rule{}}
+	names := make([]string, len(children))
+	for key, child := range children {
+		names[key] = child.Name
+	}
+
+	for key, child := range children {
+		childPath := joinSelectorPath(path, child.Name, occurrence(names, key))
+		index.keys = append(index.keys, childPath)
+		index.rules[childPath] = child
+	}
+
+	return index
+}
+
+// mergeKeys returns the keys of old followed by keys only found in new
+func mergeKeys(old []string, new []string) []string {
+	seen := map[string]bool{}
+	keys := make([]string, 0, len(old)+len(new))
+	for _, key := range old {
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	for _, key := range new {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+func criteriaItems(criteria []*Criteria) []namedItem {
+	items := make([]namedItem, len(criteria))
+	for key, c := range criteria {
+		items[key] = namedItem{name: c.Name, value: c}
+	}
+
+	return items
+}
+
+func behaviorItems(behaviors []*Behavior) []namedItem {
+	items := make([]namedItem, len(behaviors))
+	for key, behavior := range behaviors {
+		items[key] = namedItem{name: behavior.Name, value: behavior}
+	}
+
+	return items
+}
+
+func variableItems(variables []*Variable) []namedItem {
+	items := make([]namedItem, len(variables))
+	for key, variable := range variables {
+		items[key] = namedItem{name: variable.Name, value: variable}
+	}
+
+	return items
+}
+
+// equalJSON compares values by their JSON encoding, so that numbers decoded
+// as float64 compare equal to ints set in code
+func equalJSON(a interface{}, b interface{}) bool {
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	if aErr != nil || bErr != nil {
+		return false
+	}
+
+	return bytes.Equal(aJSON, bJSON)
+}
+
+func diffValue(value interface{}) string {
+	switch v := value.(type) {
+	case *Behavior:
+		value = v.Options
+	case *Criteria:
+		value = v.Options
+	case *Variable:
+		value = v.Value
+	}
+
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return string(valueJSON)
+}
+
+func hostnameTarget(hostname *Hostname) string {
+	if hostname.EdgeHostnameID != "" {
+		return hostname.EdgeHostnameID
+	}
+
+	return hostname.CnameTo
+}
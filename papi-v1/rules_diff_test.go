@@ -0,0 +1,72 @@
+package papi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffRules(t *testing.T) {
+	old := newQueryTestRules(t)
+	new := copyRules(old)
+
+	assert.Empty(t, DiffRules(old, new))
+
+	_, err := new.SetBehaviorOption("/Static/caching", "ttl", "2d")
+	assert.NoError(t, err)
+	_, err = new.RemoveRules("/Static/Images[0]")
+	assert.NoError(t, err)
+
+	gzip := NewBehavior()
+	gzip.Name = "gzipResponse"
+	_, err = new.AddBehaviors("/", gzip)
+	assert.NoError(t, err)
+
+	child := NewRule()
+	child.Name = "Fonts"
+	new.Rule.Children[0].AddChildRule(child)
+	new.Rule.Children[1].CriteriaMustSatisfy = RuleCriteriaMustSatisfyAny
+
+	var lines []string
+	for _, change := range DiffRules(old, new) {
+		lines = append(lines, change.String())
+	}
+
+	assert.Equal(t, []string{
+		"+ behavior /gzipResponse",
+		`~ behavior /Static/caching: {"behavior":"MAX_AGE","ttl":"1d"} => {"behavior":"MAX_AGE","ttl":"2d"}`,
+		"- rule /Static/Images[0]",
+		"- rule /Static/Images[1]",
+		"+ rule /Static/Images",
+		"+ rule /Static/Fonts",
+		`~ rule /API\/v1 criteriaMustSatisfy: "" => "any"`,
+	}, lines)
+}
+
+func TestDiffHostnames(t *testing.T) {
+	old := NewHostnames()
+	www := old.NewHostname()
+	www.CnameFrom = "www.example.com"
+	www.EdgeHostnameID = "ehn_1"
+	api := old.NewHostname()
+	api.CnameFrom = "api.example.com"
+	api.EdgeHostnameID = "ehn_1"
+
+	new := copyHostnames(old)
+	new.Hostnames.Items[0].EdgeHostnameID = "ehn_2"
+	new.Hostnames.Items = new.Hostnames.Items[:1]
+	static := new.NewHostname()
+	static.CnameFrom = "static.example.com"
+	static.EdgeHostnameID = "ehn_3"
+
+	var lines []string
+	for _, change := range DiffHostnames(old, new) {
+		lines = append(lines, change.String())
+	}
+
+	assert.Equal(t, []string{
+		"- hostname api.example.com => ehn_1",
+		"+ hostname static.example.com => ehn_3",
+		"~ hostname www.example.com: ehn_1 => ehn_2",
+	}, lines)
+}
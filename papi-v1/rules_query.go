@@ -0,0 +1,810 @@
+package papi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RuleMatch is a rule located by Rules.Select
+type RuleMatch struct {
+	Rule   *Rule
+	Parent *Rule
+	Index  int
+	Path   string
+}
+
+// BehaviorMatch is a behavior located by Rules.SelectBehaviors
+type BehaviorMatch struct {
+	Behavior *Behavior
+	Rule     *Rule
+	Index    int
+	Path     string
+}
+
+// CriteriaMatch is a criteria located by Rules.SelectCriteria
+type CriteriaMatch struct {
+	Criteria *Criteria
+	Rule     *Rule
+	Index    int
+	Path     string
+}
+
+// Select locates all rules matching a selector
+//
+// A selector is a slash-separated list of segments evaluated from the
+// default rule, where each segment selects child rules:
+//
+//	Static          child rules named "Static" (case-insensitive)
+//	Static[1]       the second child rule named "Static"
+//	[0], [-1]       the first and last child rule, whatever its name
+//	*, Static*      any child rule, child rules whose name starts with "Static"
+//	**              the current rules and all of their descendants
+//
+// Use a backslash to escape "/", "[", "]", "*", "?" or "\" within a name.
+// An empty selector, or "/", selects the default rule.
+//
+// Unlike FindRule, every matching rule is returned, in tree order. Each
+// match carries a canonical Path that selects only that rule.
+func (rules *Rules) Select(selector string) ([]*RuleMatch, error) {
+	steps, err := parseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(steps) > 0 && steps[len(steps)-1].hasPredicates() {
+		return nil, fmt.Errorf("%s: option predicates are only allowed on behaviors and criteria", ErrorMap[ErrInvalidSelector])
+	}
+
+	return rules.selectRules(steps)
+}
+
+// SelectBehaviors locates all behaviors matching a selector
+//
+// The last segment of the selector matches behavior names within the rules
+// selected by the preceding segments (see Select), and may be followed by
+// predicates on option values:
+//
+//	/Static/caching                       caching behaviors in the Static rule
+//	/**/caching[behavior=MAX_AGE]         caching behaviors with a given option value
+//	/**/origin[hostname!=a.example.com]   origin behaviors with any other hostname
+//	/**/cpCode[value.id]                  cpCode behaviors with a nested option set
+//	/**/*[0]                              the first behavior of every rule
+//
+// Predicates and indexes are applied left to right, per rule.
+func (rules *Rules) SelectBehaviors(selector string) ([]*BehaviorMatch, error) {
+	steps, err := parseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(steps) == 0 || steps[len(steps)-1].recursive {
+		return nil, fmt.Errorf("%s: selector must end with a behavior name", ErrorMap[ErrInvalidSelector])
+	}
+
+	parents, err := rules.selectRules(steps[:len(steps)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := steps[len(steps)-1]
+	var matches []*BehaviorMatch
+	for _, parent := range parents {
+		candidates := make([]int, 0, len(parent.Rule.Behaviors))
+		names := make([]string, 0, len(parent.Rule.Behaviors))
+		options := make([]OptionValue, 0, len(parent.Rule.Behaviors))
+		for key, behavior := range parent.Rule.Behaviors {
+			candidates = append(candidates, key)
+			names = append(names, behavior.Name)
+			options = append(options, behavior.Options)
+		}
+
+		for _, key := range last.filter(candidates, names, options) {
+			behavior := parent.Rule.Behaviors[key]
+			matches = append(matches, &BehaviorMatch{
+				Behavior: behavior,
+				Rule:     parent.Rule,
+				Index:    key,
+				Path:     joinSelectorPath(parent.Path, behavior.Name, occurrence(names, key)),
+			})
+		}
+	}
+
+	return matches, nil
+}
+
+// SelectCriteria locates all criteria matching a selector
+//
+// Selectors follow the same rules as SelectBehaviors, with the last segment
+// matching criteria names.
+func (rules *Rules) SelectCriteria(selector string) ([]*CriteriaMatch, error) {
+	steps, err := parseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(steps) == 0 || steps[len(steps)-1].recursive {
+		return nil, fmt.Errorf("%s: selector must end with a criteria name", ErrorMap[ErrInvalidSelector])
+	}
+
+	parents, err := rules.selectRules(steps[:len(steps)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := steps[len(steps)-1]
+	var matches []*CriteriaMatch
+	for _, parent := range parents {
+		candidates := make([]int, 0, len(parent.Rule.Criteria))
+		names := make([]string, 0, len(parent.Rule.Criteria))
+		options := make([]OptionValue, 0, len(parent.Rule.Criteria))
+		for key, criteria := range parent.Rule.Criteria {
+			candidates = append(candidates, key)
+			names = append(names, criteria.Name)
+			options = append(options, criteria.Options)
+		}
+
+		for _, key := range last.filter(candidates, names, options) {
+			criteria := parent.Rule.Criteria[key]
+			matches = append(matches, &CriteriaMatch{
+				Criteria: criteria,
+				Rule:     parent.Rule,
+				Index:    key,
+				Path:     joinSelectorPath(parent.Path, criteria.Name, occurrence(names, key)),
+			})
+		}
+	}
+
+	return matches, nil
+}
+
+// UpdateBehaviors calls fn for every behavior matching selector, returning
+// the number of behaviors visited
+//
+// Iteration stops at the first error returned by fn.
+func (rules *Rules) UpdateBehaviors(selector string, fn func(match *BehaviorMatch) error) (int, error) {
+	matches, err := rules.SelectBehaviors(selector)
+	if err != nil {
+		return 0, err
+	}
+
+	for count, match := range matches {
+		if err := fn(match); err != nil {
+			return count, err
+		}
+	}
+
+	return len(matches), nil
+}
+
+// SetBehaviorOption sets an option on every behavior matching selector
+//
+// The option name may use dots to address nested options, e.g. "value.id".
+// Intermediate options are created as needed.
+//
+//	rules.SetBehaviorOption("/Static/**/caching", "ttl", "7d")
+func (rules *Rules) SetBehaviorOption(selector string, option string, value interface{}) (int, error) {
+	keys, err := splitOptionKey(option)
+	if err != nil {
+		return 0, err
+	}
+
+	return rules.UpdateBehaviors(selector, func(match *BehaviorMatch) error {
+		if match.Behavior.Options == nil {
+			match.Behavior.Options = OptionValue{}
+		}
+
+		return setOption(match.Behavior.Options, keys, value)
+	})
+}
+
+// RemoveBehaviorOption removes an option from every behavior matching selector
+//
+// Only behaviors that had the option set are counted.
+func (rules *Rules) RemoveBehaviorOption(selector string, option string) (int, error) {
+	keys, err := splitOptionKey(option)
+	if err != nil {
+		return 0, err
+	}
+
+	matches, err := rules.SelectBehaviors(selector)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, match := range matches {
+		if deleteOption(match.Behavior.Options, keys) {
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+// AddBehaviors adds a copy of behavior to every rule matching selector
+func (rules *Rules) AddBehaviors(selector string, behavior *Behavior) (int, error) {
+	matches, err := rules.Select(selector)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, match := range matches {
+		match.Rule.AddBehavior(copyBehavior(behavior))
+	}
+
+	return len(matches), nil
+}
+
+// RemoveBehaviors removes every behavior matching selector from its rule
+func (rules *Rules) RemoveBehaviors(selector string) (int, error) {
+	matches, err := rules.SelectBehaviors(selector)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, match := range matches {
+		for key, behavior := range match.Rule.Behaviors {
+			if behavior == match.Behavior {
+				match.Rule.Behaviors = append(match.Rule.Behaviors[:key], match.Rule.Behaviors[key+1:]...)
+				break
+			}
+		}
+	}
+
+	return len(matches), nil
+}
+
+// RemoveRules removes every rule matching selector from its parent
+//
+// The default rule cannot be removed.
+func (rules *Rules) RemoveRules(selector string) (int, error) {
+	matches, err := rules.Select(selector)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, match := range matches {
+		if match.Parent == nil {
+			continue
+		}
+
+		for key, child := range match.Parent.Children {
+			if child == match.Rule {
+				match.Parent.Children = append(match.Parent.Children[:key], match.Parent.Children[key+1:]...)
+				removed++
+				break
+			}
+		}
+	}
+
+	return removed, nil
+}
+
+func (rules *Rules) selectRules(steps []selectorStep) ([]*RuleMatch, error) {
+	if rules.Rule == nil {
+		return nil, ErrorMap[ErrRuleNotFound]
+	}
+
+	current := []*RuleMatch{{Rule: rules.Rule, Path: "/"}}
+	for _, step := range steps {
+		var next []*RuleMatch
+		if step.recursive {
+			seen := map[*Rule]bool{}
+			for _, match := range current {
+				next = appendDescendants(next, match, seen)
+			}
+			current = next
+			continue
+		}
+
+		for _, parent := range current {
+			candidates := make([]int, 0, len(parent.Rule.Children))
+			names := make([]string, 0, len(parent.Rule.Children))
+			for key, child := range parent.Rule.Children {
+				candidates = append(candidates, key)
+				names = append(names, child.Name)
+			}
+
+			for _, key := range step.filter(candidates, names, nil) {
+				child := parent.Rule.Children[key]
+				next = append(next, &RuleMatch{
+					Rule:   child,
+					Parent: parent.Rule,
+					Index:  key,
+					Path:   joinSelectorPath(parent.Path, child.Name, occurrence(names, key)),
+				})
+			}
+		}
+		current = next
+	}
+
+	return current, nil
+}
+
+func appendDescendants(matches []*RuleMatch, match *RuleMatch, seen map[*Rule]bool) []*RuleMatch {
+	if seen[match.Rule] {
+		return matches
+	}
+	seen[match.Rule] = true
+	matches = append(matches, match)
+
+	names := make([]string, len(match.Rule.Children))
+	for key, child := range match.Rule.Children {
+		names[key] = child.Name
+	}
+
+	for key, child := range match.Rule.Children {
+		matches = appendDescendants(matches, &RuleMatch{
+			Rule:   child,
+			Parent: match.Rule,
+			Index:  key,
+			Path:   joinSelectorPath(match.Path, child.Name, occurrence(names, key)),
+		}, seen)
+	}
+
+	return matches
+}
+
+// selectorStep is a single parsed selector segment
+type selectorStep struct {
+	recursive bool
+	pattern   []globToken
+	filters   []selectorFilter
+}
+
+// selectorFilter is a bracketed index or option predicate
+type selectorFilter struct {
+	isIndex bool
+	index   int
+	key     []string
+	op      string
+	value   string
+}
+
+type globToken struct {
+	r        rune
+	wildcard bool
+}
+
+func (step selectorStep) hasPredicates() bool {
+	for _, filter := range step.filters {
+		if !filter.isIndex {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filter returns the candidates whose name matches the step pattern and
+// which pass all of the step filters, in order
+func (step selectorStep) filter(candidates []int, names []string, options []OptionValue) []int {
+	var matched []int
+	for _, key := range candidates {
+		if matchGlob(step.pattern, strings.ToLower(names[key])) {
+			matched = append(matched, key)
+		}
+	}
+
+	for _, filter := range step.filters {
+		if filter.isIndex {
+			index := filter.index
+			if index < 0 {
+				index += len(matched)
+			}
+			if index < 0 || index >= len(matched) {
+				matched = nil
+			} else {
+				matched = []int{matched[index]}
+			}
+			continue
+		}
+
+		var kept []int
+		for _, key := range matched {
+			if options != nil && filter.matchOptions(options[key]) {
+				kept = append(kept, key)
+			}
+		}
+		matched = kept
+	}
+
+	return matched
+}
+
+func (filter selectorFilter) matchOptions(options OptionValue) bool {
+	value, ok := lookupOption(options, filter.key)
+	switch filter.op {
+	case "":
+		return ok
+	case "=":
+		return ok && formatOptionValue(value) == filter.value
+	case "!=":
+		return !ok || formatOptionValue(value) != filter.value
+	}
+
+	return false
+}
+
+func parseSelector(selector string) ([]selectorStep, error) {
+	segments, err := splitSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]selectorStep, 0, len(segments))
+	for _, segment := range segments {
+		step, err := parseSelectorSegment(segment)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+
+	for key, step := range steps {
+		if key < len(steps)-1 && step.hasPredicates() {
+			return nil, fmt.Errorf("%s: option predicates are only allowed in the last segment", ErrorMap[ErrInvalidSelector])
+		}
+	}
+
+	return steps, nil
+}
+
+// splitSelector splits a selector on unescaped slashes outside of brackets
+func splitSelector(selector string) ([]string, error) {
+	selector = strings.TrimPrefix(selector, "/")
+	if selector == "" {
+		return nil, nil
+	}
+
+	var segments []string
+	var current strings.Builder
+	depth := 0
+	var quote rune
+	for pos := 0; pos < len(selector); {
+		r, size := utf8.DecodeRuneInString(selector[pos:])
+		switch {
+		case r == '\\':
+			if pos+size >= len(selector) {
+				return nil, fmt.Errorf("%s: trailing escape", ErrorMap[ErrInvalidSelector])
+			}
+			next, nextSize := utf8.DecodeRuneInString(selector[pos+size:])
+			current.WriteRune(r)
+			current.WriteRune(next)
+			pos += size + nextSize
+			continue
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case depth > 0 && (r == '"' || r == '\''):
+			quote = r
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("%s: unexpected \"]\" at position %d", ErrorMap[ErrInvalidSelector], pos)
+			}
+		case r == '/' && depth == 0:
+			if current.Len() == 0 {
+				return nil, fmt.Errorf("%s: empty segment at position %d", ErrorMap[ErrInvalidSelector], pos)
+			}
+			segments = append(segments, current.String())
+			current.Reset()
+			pos += size
+			continue
+		}
+
+		current.WriteRune(r)
+		pos += size
+	}
+
+	if depth != 0 || quote != 0 {
+		return nil, fmt.Errorf("%s: unterminated \"[\"", ErrorMap[ErrInvalidSelector])
+	}
+
+	if current.Len() == 0 {
+		return nil, fmt.Errorf("%s: trailing \"/\"", ErrorMap[ErrInvalidSelector])
+	}
+
+	return append(segments, current.String()), nil
+}
+
+func parseSelectorSegment(segment string) (selectorStep, error) {
+	step := selectorStep{}
+	if segment == "**" {
+		step.recursive = true
+		return step, nil
+	}
+
+	pos := 0
+	for pos < len(segment) {
+		r, size := utf8.DecodeRuneInString(segment[pos:])
+		if r == '[' {
+			break
+		}
+
+		if r == '\\' {
+			r, size = utf8.DecodeRuneInString(segment[pos+1:])
+			step.pattern = append(step.pattern, globToken{r: unicodeLower(r)})
+			pos += size + 1
+			continue
+		}
+
+		step.pattern = append(step.pattern, globToken{r: unicodeLower(r), wildcard: r == '*' || r == '?'})
+		pos += size
+	}
+
+	if len(step.pattern) == 0 {
+		step.pattern = []globToken{{r: '*', wildcard: true}}
+	}
+
+	for pos < len(segment) {
+		if segment[pos] != '[' {
+			return step, fmt.Errorf("%s: unexpected %q in segment %q", ErrorMap[ErrInvalidSelector], segment[pos:], segment)
+		}
+
+		end := closingBracket(segment, pos)
+		if end < 0 {
+			return step, fmt.Errorf("%s: unterminated \"[\" in segment %q", ErrorMap[ErrInvalidSelector], segment)
+		}
+
+		filter, err := parseSelectorFilter(segment[pos+1 : end])
+		if err != nil {
+			return step, err
+		}
+		step.filters = append(step.filters, filter)
+		pos = end + 1
+	}
+
+	return step, nil
+}
+
+func closingBracket(segment string, open int) int {
+	var quote byte
+	for pos := open + 1; pos < len(segment); pos++ {
+		switch c := segment[pos]; {
+		case c == '\\':
+			pos++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ']':
+			return pos
+		}
+	}
+
+	return -1
+}
+
+func parseSelectorFilter(expr string) (selectorFilter, error) {
+	expr = strings.TrimSpace(expr)
+	if index, err := strconv.Atoi(expr); err == nil {
+		return selectorFilter{isIndex: true, index: index}, nil
+	}
+
+	filter := selectorFilter{}
+	key := expr
+	if pos := strings.Index(expr, "!="); pos >= 0 {
+		filter.op, key, filter.value = "!=", expr[:pos], expr[pos+2:]
+	} else if pos := strings.Index(expr, "="); pos >= 0 {
+		filter.op, key, filter.value = "=", expr[:pos], expr[pos+1:]
+	}
+
+	keys, err := splitOptionKey(strings.TrimSpace(key))
+	if err != nil {
+		return filter, err
+	}
+	filter.key = keys
+
+	value := strings.TrimSpace(filter.value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	filter.value = strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\'`, `'`, `\]`, `]`).Replace(value)
+
+	return filter, nil
+}
+
+func splitOptionKey(option string) ([]string, error) {
+	if option == "" {
+		return nil, fmt.Errorf("%s: empty option name", ErrorMap[ErrInvalidSelector])
+	}
+
+	keys := strings.Split(option, ".")
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("%s: invalid option name %q", ErrorMap[ErrInvalidSelector], option)
+		}
+	}
+
+	return keys, nil
+}
+
+// matchGlob reports whether name matches a pattern of literal runes and
+// "*"/"?" wildcards
+func matchGlob(pattern []globToken, name string) bool {
+	runes := []rune(name)
+	p, n := 0, 0
+	star, mark := -1, 0
+	for n < len(runes) {
+		switch {
+		case p < len(pattern) && pattern[p].wildcard && pattern[p].r == '*':
+			star, mark = p, n
+			p++
+		case p < len(pattern) && (pattern[p].r == runes[n] || (pattern[p].wildcard && pattern[p].r == '?')):
+			p++
+			n++
+		case star >= 0:
+			p = star + 1
+			mark++
+			n = mark
+		default:
+			return false
+		}
+	}
+
+	for p < len(pattern) && pattern[p].wildcard && pattern[p].r == '*' {
+		p++
+	}
+
+	return p == len(pattern)
+}
+
+func unicodeLower(r rune) rune {
+	lower := []rune(strings.ToLower(string(r)))
+	return lower[0]
+}
+
+// occurrence returns how many earlier siblings share the name at key, or -1
+// if the name is unique among its siblings
+func occurrence(names []string, key int) int {
+	count, total := 0, 0
+	name := strings.ToLower(names[key])
+	for index, sibling := range names {
+		if strings.ToLower(sibling) == name {
+			if index < key {
+				count++
+			}
+			total++
+		}
+	}
+
+	if total == 1 {
+		return -1
+	}
+
+	return count
+}
+
+func joinSelectorPath(parent string, name string, index int) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `/`, `\/`, `[`, `\[`, `]`, `\]`, `*`, `\*`, `?`, `\?`).Replace(name)
+	if index >= 0 {
+		escaped += fmt.Sprintf("[%d]", index)
+	}
+
+	return strings.TrimSuffix(parent, "/") + "/" + escaped
+}
+
+func lookupOption(options OptionValue, keys []string) (interface{}, bool) {
+	var current interface{} = map[string]interface{}(options)
+	for _, key := range keys {
+		var value interface{}
+		var ok bool
+		switch m := current.(type) {
+		case map[string]interface{}:
+			value, ok = m[key]
+		case OptionValue:
+			value, ok = m[key]
+		}
+
+		if !ok {
+			return nil, false
+		}
+		current = value
+	}
+
+	return current, true
+}
+
+// formatOptionValue formats an option value for comparison with a string
+//
+// Numbers decoded from JSON are float64, formatted without exponent so that
+// a CP code such as 1234567 reads as written in the rule tree.
+func formatOptionValue(value interface{}) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+func setOption(options OptionValue, keys []string, value interface{}) error {
+	current := map[string]interface{}(options)
+	for _, key := range keys[:len(keys)-1] {
+		switch next := current[key].(type) {
+		case map[string]interface{}:
+			current = next
+		case OptionValue:
+			current = next
+		case nil:
+			child := map[string]interface{}{}
+			current[key] = child
+			current = child
+		default:
+			return fmt.Errorf("option %q is not an object", key)
+		}
+	}
+
+	current[keys[len(keys)-1]] = value
+
+	return nil
+}
+
+func deleteOption(options OptionValue, keys []string) bool {
+	current := map[string]interface{}(options)
+	for _, key := range keys[:len(keys)-1] {
+		switch next := current[key].(type) {
+		case map[string]interface{}:
+			current = next
+		case OptionValue:
+			current = next
+		default:
+			return false
+		}
+	}
+
+	if _, ok := current[keys[len(keys)-1]]; !ok {
+		return false
+	}
+	delete(current, keys[len(keys)-1])
+
+	return true
+}
+
+func copyBehavior(behavior *Behavior) *Behavior {
+	newBehavior := NewBehavior()
+	newBehavior.Name = behavior.Name
+	newBehavior.Locked = behavior.Locked
+	newBehavior.Options = copyOptionValue(behavior.Options)
+
+	return newBehavior
+}
+
+func copyOptionValue(options OptionValue) OptionValue {
+	if options == nil {
+		return OptionValue{}
+	}
+
+	return OptionValue(copyOption(map[string]interface{}(options)).(map[string]interface{}))
+}
+
+func copyOption(value interface{}) interface{} {
+	switch v := value.(type) {
+	case OptionValue:
+		return copyOption(map[string]interface{}(v))
+	case map[string]interface{}:
+		newMap := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			newMap[key] = copyOption(item)
+		}
+		return newMap
+	case []interface{}:
+		newSlice := make([]interface{}, len(v))
+		for key, item := range v {
+			newSlice[key] = copyOption(item)
+		}
+		return newSlice
+	case []string:
+		return append([]string(nil), v...)
+	}
+
+	return value
+}
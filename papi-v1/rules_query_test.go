@@ -0,0 +1,225 @@
+package papi
+
+import (
+	"testing"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/jsonhooks-v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func newQueryTestRules(t *testing.T) *Rules {
+	rules := NewRules()
+	err := jsonhooks.Unmarshal([]byte(`{
+		"rules": {
+			"name": "default",
+			"behaviors": [
+				{"name": "origin", "options": {"hostname": "origin.example.com"}},
+				{"name": "cpCode", "options": {"value": {"id": 12345}}}
+			],
+			"children": [
+				{
+					"name": "Static",
+					"behaviors": [
+						{"name": "caching", "options": {"behavior": "MAX_AGE", "ttl": "1d"}}
+					],
+					"children": [
+						{
+							"name": "Images",
+							"behaviors": [
+								{"name": "caching", "options": {"behavior": "NO_STORE"}}
+							]
+						},
+						{
+							"name": "Images",
+							"behaviors": [
+								{"name": "caching", "options": {"behavior": "MAX_AGE", "ttl": "7d"}},
+								{"name": "caching", "options": {"behavior": "MAX_AGE", "ttl": "30d"}}
+							]
+						}
+					]
+				},
+				{
+					"name": "API/v1",
+					"criteria": [
+						{"name": "path", "options": {"matchOperator": "MATCHES_ONE_OF", "values": ["/api/v1/*"]}}
+					],
+					"behaviors": [
+						{"name": "caching", "options": {"behavior": "NO_STORE"}}
+					]
+				}
+			]
+		}
+	}`), rules)
+	assert.NoError(t, err)
+
+	return rules
+}
+
+func TestRules_Select(t *testing.T) {
+	tests := []struct {
+		Selector string
+		Paths    []string
+	}{
+		{"", []string{"/"}},
+		{"/", []string{"/"}},
+		{"/static", []string{"/Static"}},
+		{"/Static/Images", []string{"/Static/Images[0]", "/Static/Images[1]"}},
+		{"/Static/Images[1]", []string{"/Static/Images[1]"}},
+		{"/Static/Images[-1]", []string{"/Static/Images[1]"}},
+		{"/Static/[0]", []string{"/Static/Images[0]"}},
+		{"/*", []string{"/Static", "/API\\/v1"}},
+		{"/Stat*", []string{"/Static"}},
+		{"/API\\/v1", []string{"/API\\/v1"}},
+		{"/**", []string{"/", "/Static", "/Static/Images[0]", "/Static/Images[1]", "/API\\/v1"}},
+		{"/**/Images", []string{"/Static/Images[0]", "/Static/Images[1]"}},
+		{"/Missing", nil},
+	}
+
+	rules := newQueryTestRules(t)
+	for _, test := range tests {
+		matches, err := rules.Select(test.Selector)
+		assert.NoError(t, err, test.Selector)
+
+		var paths []string
+		for _, match := range matches {
+			paths = append(paths, match.Path)
+
+			roundTrip, err := rules.Select(match.Path)
+			assert.NoError(t, err, match.Path)
+			if assert.Len(t, roundTrip, 1, match.Path) {
+				assert.Equal(t, match.Rule, roundTrip[0].Rule)
+			}
+		}
+		assert.Equal(t, test.Paths, paths, test.Selector)
+	}
+}
+
+func TestRules_SelectBehaviors(t *testing.T) {
+	tests := []struct {
+		Selector string
+		Paths    []string
+	}{
+		{"/origin", []string{"/origin"}},
+		{"/**/caching", []string{"/Static/caching", "/Static/Images[0]/caching", "/Static/Images[1]/caching[0]", "/Static/Images[1]/caching[1]", "/API\\/v1/caching"}},
+		{"/Static/**/caching[behavior=MAX_AGE]", []string{"/Static/caching", "/Static/Images[1]/caching[0]", "/Static/Images[1]/caching[1]"}},
+		{"/**/caching[behavior=MAX_AGE][-1]", []string{"/Static/caching", "/Static/Images[1]/caching[1]"}},
+		{"/**/caching[ttl!='7d'][behavior=\"MAX_AGE\"]", []string{"/Static/caching", "/Static/Images[1]/caching[1]"}},
+		{"/**/caching[ttl]", []string{"/Static/caching", "/Static/Images[1]/caching[0]", "/Static/Images[1]/caching[1]"}},
+		{"/cpCode[value.id=12345]", []string{"/cpCode"}},
+		{"/cpCode[value.id=1]", nil},
+		{"/**/*[0]", []string{"/origin", "/Static/caching", "/Static/Images[0]/caching", "/Static/Images[1]/caching[0]", "/API\\/v1/caching"}},
+	}
+
+	rules := newQueryTestRules(t)
+	for _, test := range tests {
+		matches, err := rules.SelectBehaviors(test.Selector)
+		assert.NoError(t, err, test.Selector)
+
+		var paths []string
+		for _, match := range matches {
+			paths = append(paths, match.Path)
+		}
+		assert.Equal(t, test.Paths, paths, test.Selector)
+	}
+}
+
+func TestRules_SelectBehaviors_Numbers(t *testing.T) {
+	rules := NewRules()
+	err := jsonhooks.Unmarshal([]byte(`{
+		"rules": {
+			"name": "default",
+			"behaviors": [
+				{"name": "cpCode", "options": {"value": {"id": 1234567}}},
+				{"name": "caching", "options": {"behavior": "MAX_AGE", "ttl": 1.5}}
+			]
+		}
+	}`), rules)
+	assert.NoError(t, err)
+
+	matches, err := rules.SelectBehaviors("/**/cpCode[value.id=1234567]")
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+
+	matches, err = rules.SelectBehaviors("/caching[ttl=1.5]")
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+
+	matches, err = rules.SelectBehaviors("/cpCode[value.id!=1234567]")
+	assert.NoError(t, err)
+	assert.Len(t, matches, 0)
+}
+
+func TestRules_SelectCriteria(t *testing.T) {
+	rules := newQueryTestRules(t)
+
+	matches, err := rules.SelectCriteria("/**/path[matchOperator=MATCHES_ONE_OF]")
+	assert.NoError(t, err)
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, "API/v1", matches[0].Rule.Name)
+		assert.Equal(t, "/API\\/v1/path", matches[0].Path)
+	}
+}
+
+func TestRules_Select_Invalid(t *testing.T) {
+	rules := newQueryTestRules(t)
+
+	for _, selector := range []string{"/Static//Images", "/Static/", "/Static[0", "/Static]", "/Static[behavior=MAX_AGE]/caching", "/Static\\"} {
+		_, err := rules.SelectBehaviors(selector)
+		assert.Error(t, err, selector)
+	}
+
+	_, err := rules.Select("/Static[behavior=MAX_AGE]")
+	assert.Error(t, err)
+
+	_, err = rules.SelectBehaviors("/**")
+	assert.Error(t, err)
+}
+
+func TestRules_SetBehaviorOption(t *testing.T) {
+	rules := newQueryTestRules(t)
+
+	count, err := rules.SetBehaviorOption("/Static/**/caching[behavior=MAX_AGE]", "ttl", "2d")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, "2d", rules.Rule.Children[0].Behaviors[0].Options["ttl"])
+	assert.Equal(t, "2d", rules.Rule.Children[0].Children[1].Behaviors[1].Options["ttl"])
+	assert.Nil(t, rules.Rule.Children[0].Children[0].Behaviors[0].Options["ttl"])
+
+	count, err = rules.SetBehaviorOption("/cpCode", "value.description", "static")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "static", rules.Rule.Behaviors[1].Options["value"].(map[string]interface{})["description"])
+
+	count, err = rules.RemoveBehaviorOption("/**/caching", "ttl")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestRules_AddRemoveBehaviors(t *testing.T) {
+	rules := newQueryTestRules(t)
+
+	behavior := NewBehavior()
+	behavior.Name = "gzipResponse"
+	behavior.Options = OptionValue{"behavior": "ALWAYS"}
+
+	count, err := rules.AddBehaviors("/Static/Images", behavior)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	added := rules.Rule.Children[0].Children[0].Behaviors[1]
+	assert.Equal(t, "gzipResponse", added.Name)
+	assert.NotEqual(t, behavior, added)
+	added.Options["behavior"] = "NEVER"
+	assert.Equal(t, "ALWAYS", rules.Rule.Children[0].Children[1].Behaviors[2].Options["behavior"])
+
+	count, err = rules.RemoveBehaviors("/**/caching[behavior=NO_STORE]")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Len(t, rules.Rule.Children[1].Behaviors, 0)
+
+	count, err = rules.RemoveRules("/**/Images[0]")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Len(t, rules.Rule.Children[0].Children, 1)
+	assert.Len(t, rules.Rule.Children[0].Children[0].Behaviors, 3)
+}
@@ -0,0 +1,480 @@
+package papi
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// SimulatedRequest is a client request evaluated by RuleSimulator
+//
+// Header names are case-insensitive, as with http.Header. Country,
+// Continent and Region are the ISO codes used by the userLocation criteria.
+type SimulatedRequest struct {
+	Scheme    string
+	Host      string
+	Path      string
+	Method    string
+	Headers   http.Header
+	Cookies   map[string]string
+	Query     url.Values
+	ClientIP  string
+	Country   string
+	Continent string
+	Region    string
+}
+
+// NewSimulatedRequest creates a new SimulatedRequest from a URL
+func NewSimulatedRequest(method string, rawURL string) (*SimulatedRequest, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == "" {
+		method = "GET"
+	}
+
+	requestPath := u.EscapedPath()
+	if requestPath == "" {
+		requestPath = "/"
+	}
+
+	return &SimulatedRequest{
+		Scheme:  strings.ToUpper(u.Scheme),
+		Host:    u.Hostname(),
+		Path:    requestPath,
+		Method:  strings.ToUpper(method),
+		Headers: http.Header{},
+		Cookies: map[string]string{},
+		Query:   u.Query(),
+	}, nil
+}
+
+// CriteriaResult is the evaluation of a single criteria by RuleSimulator
+//
+// Err is set when the criteria could not be evaluated, in which case
+// Matched is the value assumed by the simulator.
+type CriteriaResult struct {
+	*CriteriaMatch
+	Matched bool
+	Err     error
+}
+
+// SimulationResult is the outcome of RuleSimulator.Simulate
+//
+// Rules lists the matched rules in evaluation order, starting with the
+// default rule. Behaviors holds the effective behaviors: a behavior in a
+// later rule replaces an earlier behavior of the same name, in the position
+// of the earlier one, except for behaviors that may be applied more than
+// once, such as header modifications, which accumulate.
+type SimulationResult struct {
+	Rules       []*RuleMatch
+	Behaviors   []*BehaviorMatch
+	Criteria    []*CriteriaResult
+	Unsupported []*CriteriaResult
+}
+
+// Behavior returns the effective behavior with the given name, if any
+func (result *SimulationResult) Behavior(name string) *BehaviorMatch {
+	for _, behavior := range result.Behaviors {
+		if strings.EqualFold(behavior.Behavior.Name, name) {
+			return behavior
+		}
+	}
+
+	return nil
+}
+
+// RuleSimulator evaluates a rule tree against a SimulatedRequest
+//
+// Only criteria that can be decided from the request are supported: path,
+// hostname, fileExtension, requestHeader, queryStringParameter, cookie,
+// requestMethod, requestProtocol, clientIp and userLocation. Any other
+// criteria is reported in SimulationResult.Unsupported and assumed to be
+// UnsupportedMatches.
+//
+//	simulator := papi.NewRuleSimulator(rules)
+//	request, _ := papi.NewSimulatedRequest("GET", "https://www.example.com/images/a.png")
+//	result := simulator.Simulate(request)
+//	for _, behavior := range result.Behaviors {
+//		fmt.Println(behavior.Path)
+//	}
+type RuleSimulator struct {
+	Rules              *Rules
+	UnsupportedMatches bool
+}
+
+// NewRuleSimulator creates a new RuleSimulator
+func NewRuleSimulator(rules *Rules) *RuleSimulator {
+	return &RuleSimulator{Rules: rules}
+}
+
+// cumulativeBehaviors may appear several times in the effective behaviors
+var cumulativeBehaviors = map[string]bool{
+	"modifyincomingrequestheader":  true,
+	"modifyincomingresponseheader": true,
+	"modifyoutgoingrequestheader":  true,
+	"modifyoutgoingresponseheader": true,
+	"setvariable":                  true,
+}
+
+// IsCumulativeBehavior reports whether a behavior may be applied more than
+// once to a request, rather than being replaced by later occurrences
+func IsCumulativeBehavior(name string) bool {
+	return cumulativeBehaviors[strings.ToLower(name)]
+}
+
+type criteriaEvaluator func(request *SimulatedRequest, options OptionValue) (bool, error)
+
+var criteriaEvaluators = map[string]criteriaEvaluator{
+	"path":                 evaluatePath,
+	"hostname":             evaluateHostname,
+	"fileextension":        evaluateFileExtension,
+	"requestheader":        evaluateRequestHeader,
+	"querystringparameter": evaluateQueryStringParameter,
+	"cookie":               evaluateCookie,
+	"requestmethod":        evaluateRequestMethod,
+	"requestprotocol":      evaluateRequestProtocol,
+	"clientip":             evaluateClientIP,
+	"userlocation":         evaluateUserLocation,
+}
+
+// Simulate evaluates the rule tree
+//
+// Child rules are only evaluated when their parent matched.
+func (simulator *RuleSimulator) Simulate(request *SimulatedRequest) *SimulationResult {
+	result := &SimulationResult{}
+	if simulator.Rules == nil || simulator.Rules.Rule == nil {
+		return result
+	}
+
+	effective := map[string]int{}
+	simulator.simulate(result, effective, request, &RuleMatch{Rule: simulator.Rules.Rule, Path: "/"})
+
+	return result
+}
+
+func (simulator *RuleSimulator) simulate(result *SimulationResult, effective map[string]int, request *SimulatedRequest, match *RuleMatch) {
+	rule := match.Rule
+	if !simulator.matchRule(result, request, match) {
+		return
+	}
+
+	result.Rules = append(result.Rules, match)
+
+	names := make([]string, len(rule.Behaviors))
+	for key, behavior := range rule.Behaviors {
+		names[key] = behavior.Name
+	}
+
+	for key, behavior := range rule.Behaviors {
+		behaviorMatch := &BehaviorMatch{
+			Behavior: behavior,
+			Rule:     rule,
+			Index:    key,
+			Path:     joinSelectorPath(match.Path, behavior.Name, occurrence(names, key)),
+		}
+
+		name := strings.ToLower(behavior.Name)
+		if index, ok := effective[name]; ok && !IsCumulativeBehavior(name) {
+			result.Behaviors[index] = behaviorMatch
+			continue
+		}
+
+		effective[name] = len(result.Behaviors)
+		result.Behaviors = append(result.Behaviors, behaviorMatch)
+	}
+
+	names = make([]string, len(rule.Children))
+	for key, child := range rule.Children {
+		names[key] = child.Name
+	}
+
+	for key, child := range rule.Children {
+		simulator.simulate(result, effective, request, &RuleMatch{
+			Rule:   child,
+			Parent: rule,
+			Index:  key,
+			Path:   joinSelectorPath(match.Path, child.Name, occurrence(names, key)),
+		})
+	}
+}
+
+func (simulator *RuleSimulator) matchRule(result *SimulationResult, request *SimulatedRequest, match *RuleMatch) bool {
+	rule := match.Rule
+	if len(rule.Criteria) == 0 {
+		return true
+	}
+
+	names := make([]string, len(rule.Criteria))
+	for key, criteria := range rule.Criteria {
+		names[key] = criteria.Name
+	}
+
+	matchAny := rule.CriteriaMustSatisfy == RuleCriteriaMustSatisfyAny
+	matched := !matchAny
+	for key, criteria := range rule.Criteria {
+		criteriaResult := &CriteriaResult{
+			CriteriaMatch: &CriteriaMatch{
+				Criteria: criteria,
+				Rule:     rule,
+				Index:    key,
+				Path:     joinSelectorPath(match.Path, criteria.Name, occurrence(names, key)),
+			},
+		}
+
+		evaluate, ok := criteriaEvaluators[strings.ToLower(criteria.Name)]
+		if ok {
+			criteriaResult.Matched, criteriaResult.Err = evaluate(request, criteria.Options)
+		} else {
+			criteriaResult.Err = fmt.Errorf("criteria %s is not supported", criteria.Name)
+		}
+
+		if criteriaResult.Err != nil {
+			criteriaResult.Matched = simulator.UnsupportedMatches
+			result.Unsupported = append(result.Unsupported, criteriaResult)
+		}
+		result.Criteria = append(result.Criteria, criteriaResult)
+
+		if matchAny {
+			matched = matched || criteriaResult.Matched
+		} else {
+			matched = matched && criteriaResult.Matched
+		}
+	}
+
+	return matched
+}
+
+func evaluatePath(request *SimulatedRequest, options OptionValue) (bool, error) {
+	matched := matchOneOf(optionStrings(options, "values"), request.Path, true, optionBool(options, "matchCaseSensitive", false))
+
+	return matchOperator(options, matched, "MATCHES_ONE_OF", "DOES_NOT_MATCH_ONE_OF")
+}
+
+func evaluateHostname(request *SimulatedRequest, options OptionValue) (bool, error) {
+	host := request.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	matched := matchOneOf(optionStrings(options, "values"), host, true, false)
+
+	return matchOperator(options, matched, "IS_ONE_OF", "IS_NOT_ONE_OF")
+}
+
+func evaluateFileExtension(request *SimulatedRequest, options OptionValue) (bool, error) {
+	extension := strings.TrimPrefix(path.Ext(path.Base(request.Path)), ".")
+	if extension == "" {
+		extension = "EMPTY_STRING"
+	}
+	matched := matchOneOf(optionStrings(options, "values"), extension, false, optionBool(options, "matchCaseSensitive", false))
+
+	return matchOperator(options, matched, "IS_ONE_OF", "IS_NOT_ONE_OF")
+}
+
+func evaluateRequestHeader(request *SimulatedRequest, options OptionValue) (bool, error) {
+	var pairs [][2]string
+	for name, values := range request.Headers {
+		for _, value := range values {
+			pairs = append(pairs, [2]string{name, value})
+		}
+	}
+
+	return matchNamedValue(options, "headerName", pairs, false)
+}
+
+func evaluateQueryStringParameter(request *SimulatedRequest, options OptionValue) (bool, error) {
+	var pairs [][2]string
+	for name, values := range request.Query {
+		for _, value := range values {
+			pairs = append(pairs, [2]string{name, value})
+		}
+	}
+
+	return matchNamedValue(options, "parameterName", pairs, optionBool(options, "matchCaseSensitiveName", true))
+}
+
+func evaluateCookie(request *SimulatedRequest, options OptionValue) (bool, error) {
+	var pairs [][2]string
+	for name, value := range request.Cookies {
+		pairs = append(pairs, [2]string{name, value})
+	}
+
+	return matchNamedValue(options, "cookieName", pairs, optionBool(options, "matchCaseSensitiveName", true))
+}
+
+func evaluateRequestMethod(request *SimulatedRequest, options OptionValue) (bool, error) {
+	value, _ := options["value"].(string)
+	matched := strings.EqualFold(request.Method, value)
+
+	return matchOperator(options, matched, "IS", "IS_NOT")
+}
+
+func evaluateRequestProtocol(request *SimulatedRequest, options OptionValue) (bool, error) {
+	if request.Scheme == "" {
+		return false, fmt.Errorf("request has no scheme")
+	}
+	value, _ := options["value"].(string)
+
+	return strings.EqualFold(request.Scheme, value), nil
+}
+
+func evaluateClientIP(request *SimulatedRequest, options OptionValue) (bool, error) {
+	ip := net.ParseIP(request.ClientIP)
+	if ip == nil {
+		return false, fmt.Errorf("request has no client IP")
+	}
+
+	matched := false
+	for _, value := range optionStrings(options, "values") {
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			matched = matched || network.Contains(ip)
+		} else if valueIP := net.ParseIP(value); valueIP != nil {
+			matched = matched || valueIP.Equal(ip)
+		}
+	}
+
+	return matchOperator(options, matched, "IS_ONE_OF", "IS_NOT_ONE_OF")
+}
+
+func evaluateUserLocation(request *SimulatedRequest, options OptionValue) (bool, error) {
+	field, _ := options["field"].(string)
+
+	var actual, key string
+	switch field {
+	case "COUNTRY":
+		actual, key = request.Country, "countryValues"
+	case "CONTINENT":
+		actual, key = request.Continent, "continentValues"
+	case "REGION":
+		actual, key = request.Region, "regionValues"
+	default:
+		return false, fmt.Errorf("userLocation field %s is not supported", field)
+	}
+
+	if actual == "" {
+		return false, fmt.Errorf("request has no %s", strings.ToLower(field))
+	}
+	matched := matchOneOf(optionStrings(options, key), actual, false, false)
+
+	return matchOperator(options, matched, "IS_ONE_OF", "IS_NOT_ONE_OF")
+}
+
+// matchOperator applies a positive or negative matchOperator to a match
+func matchOperator(options OptionValue, matched bool, positive string, negative string) (bool, error) {
+	operator, _ := options["matchOperator"].(string)
+	switch operator {
+	case positive:
+		return matched, nil
+	case negative:
+		return !matched, nil
+	}
+
+	return false, fmt.Errorf("matchOperator %s is not supported", operator)
+}
+
+// matchNamedValue evaluates the name/value criteria shared by requestHeader,
+// queryStringParameter and cookie
+func matchNamedValue(options OptionValue, nameKey string, pairs [][2]string, nameCaseSensitive bool) (bool, error) {
+	name, _ := options[nameKey].(string)
+	wildcardName := optionBool(options, "matchWildcardName", false)
+
+	var values []string
+	for _, pair := range pairs {
+		if matchValue(name, pair[0], wildcardName, nameCaseSensitive) {
+			values = append(values, pair[1])
+		}
+	}
+
+	operator, _ := options["matchOperator"].(string)
+	switch operator {
+	case "EXISTS":
+		return len(values) > 0, nil
+	case "DOES_NOT_EXIST":
+		return len(values) == 0, nil
+	case "IS_ONE_OF", "IS_NOT_ONE_OF":
+		wildcardValue := optionBool(options, "matchWildcardValue", false)
+		caseSensitiveValue := optionBool(options, "matchCaseSensitiveValue", true)
+		matched := false
+		for _, value := range values {
+			matched = matched || matchOneOf(optionStrings(options, "values"), value, wildcardValue, caseSensitiveValue)
+		}
+		return matched == (operator == "IS_ONE_OF"), nil
+	case "IS_LESS_THAN", "IS_MORE_THAN", "IS_BETWEEN":
+		lower, hasLower := optionInt(options["lowerBound"])
+		upper, hasUpper := optionInt(options["upperBound"])
+		for _, value := range values {
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			switch {
+			case operator == "IS_LESS_THAN" && hasUpper && number < upper,
+				operator == "IS_MORE_THAN" && hasLower && number > lower,
+				operator == "IS_BETWEEN" && hasLower && hasUpper && number >= lower && number <= upper:
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	return false, fmt.Errorf("matchOperator %s is not supported", operator)
+}
+
+func matchOneOf(patterns []string, value string, wildcard bool, caseSensitive bool) bool {
+	for _, pattern := range patterns {
+		if matchValue(pattern, value, wildcard, caseSensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchValue(pattern string, value string, wildcard bool, caseSensitive bool) bool {
+	if !caseSensitive {
+		pattern = strings.ToLower(pattern)
+		value = strings.ToLower(value)
+	}
+
+	if !wildcard {
+		return pattern == value
+	}
+
+	tokens := make([]globToken, 0, len(pattern))
+	for _, r := range pattern {
+		tokens = append(tokens, globToken{r: r, wildcard: r == '*' || r == '?'})
+	}
+
+	return matchGlob(tokens, value)
+}
+
+func optionStrings(options OptionValue, key string) []string {
+	switch values := options[key].(type) {
+	case []string:
+		return values
+	case []interface{}:
+		strs := make([]string, 0, len(values))
+		for _, value := range values {
+			strs = append(strs, fmt.Sprintf("%v", value))
+		}
+		return strs
+	case string:
+		return []string{values}
+	}
+
+	return nil
+}
+
+func optionBool(options OptionValue, key string, fallback bool) bool {
+	if value, ok := options[key].(bool); ok {
+		return value
+	}
+
+	return fallback
+}
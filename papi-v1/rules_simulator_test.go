@@ -0,0 +1,148 @@
+package papi
+
+import (
+	"testing"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/jsonhooks-v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSimulatorTestRules(t *testing.T) *Rules {
+	rules := NewRules()
+	err := jsonhooks.Unmarshal([]byte(`{
+		"rules": {
+			"name": "default",
+			"behaviors": [
+				{"name": "origin", "options": {"hostname": "origin.example.com"}},
+				{"name": "caching", "options": {"behavior": "NO_STORE"}},
+				{"name": "modifyOutgoingResponseHeader", "options": {"customHeaderName": "X-Default"}}
+			],
+			"children": [
+				{
+					"name": "Images",
+					"criteriaMustSatisfy": "any",
+					"criteria": [
+						{"name": "fileExtension", "options": {"matchOperator": "IS_ONE_OF", "values": ["png", "jpg"]}},
+						{"name": "path", "options": {"matchOperator": "MATCHES_ONE_OF", "values": ["/img/*"]}}
+					],
+					"behaviors": [
+						{"name": "caching", "options": {"behavior": "MAX_AGE", "ttl": "7d"}},
+						{"name": "modifyOutgoingResponseHeader", "options": {"customHeaderName": "X-Images"}}
+					],
+					"children": [
+						{
+							"name": "Mobile",
+							"criteria": [
+								{"name": "requestHeader", "options": {"headerName": "X-Device", "matchOperator": "IS_ONE_OF", "values": ["mobile*"], "matchWildcardValue": true}},
+								{"name": "queryStringParameter", "options": {"parameterName": "w", "matchOperator": "IS_LESS_THAN", "upperBound": 500}}
+							],
+							"behaviors": [
+								{"name": "imageManager", "options": {"enabled": true}}
+							]
+						}
+					]
+				},
+				{
+					"name": "API",
+					"criteria": [
+						{"name": "hostname", "options": {"matchOperator": "IS_ONE_OF", "values": ["api.*"]}},
+						{"name": "requestMethod", "options": {"matchOperator": "IS_NOT", "value": "GET"}}
+					],
+					"behaviors": [
+						{"name": "origin", "options": {"hostname": "api-origin.example.com"}}
+					]
+				},
+				{
+					"name": "Geo",
+					"criteria": [
+						{"name": "userLocation", "options": {"field": "COUNTRY", "matchOperator": "IS_ONE_OF", "countryValues": ["DE"]}},
+						{"name": "edgeWorkersFailure", "options": {"execStatus": "FAILURE"}}
+					],
+					"behaviors": [
+						{"name": "denyAccess", "options": {"enabled": true}}
+					]
+				}
+			]
+		}
+	}`), rules)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	return rules
+}
+
+func simulatedPaths(matches []*RuleMatch) []string {
+	paths := make([]string, len(matches))
+	for key, match := range matches {
+		paths[key] = match.Path
+	}
+
+	return paths
+}
+
+func TestRuleSimulator_Simulate(t *testing.T) {
+	simulator := NewRuleSimulator(newSimulatorTestRules(t))
+
+	request, err := NewSimulatedRequest("GET", "https://www.example.com/static/logo.PNG?w=300")
+	assert.NoError(t, err)
+	request.Headers.Set("x-device", "mobile-ios")
+	request.Country = "FR"
+
+	result := simulator.Simulate(request)
+	assert.Equal(t, []string{"/", "/Images", "/Images/Mobile"}, simulatedPaths(result.Rules))
+
+	var behaviors []string
+	for _, behavior := range result.Behaviors {
+		behaviors = append(behaviors, behavior.Path)
+	}
+	assert.Equal(t, []string{
+		"/origin",
+		"/Images/caching",
+		"/modifyOutgoingResponseHeader",
+		"/Images/modifyOutgoingResponseHeader",
+		"/Images/Mobile/imageManager",
+	}, behaviors)
+	assert.Equal(t, "7d", result.Behavior("caching").Behavior.Options["ttl"])
+
+	if assert.Len(t, result.Unsupported, 1) {
+		assert.Equal(t, "/Geo/edgeWorkersFailure", result.Unsupported[0].Path)
+		assert.Error(t, result.Unsupported[0].Err)
+	}
+}
+
+func TestRuleSimulator_Simulate_NoMatch(t *testing.T) {
+	simulator := NewRuleSimulator(newSimulatorTestRules(t))
+
+	request, err := NewSimulatedRequest("POST", "https://api.example.com/v1/orders")
+	assert.NoError(t, err)
+	request.Country = "DE"
+
+	result := simulator.Simulate(request)
+	assert.Equal(t, []string{"/", "/API"}, simulatedPaths(result.Rules))
+	assert.Equal(t, "api-origin.example.com", result.Behavior("origin").Behavior.Options["hostname"])
+	assert.Equal(t, "NO_STORE", result.Behavior("caching").Behavior.Options["behavior"])
+
+	simulator.UnsupportedMatches = true
+	result = simulator.Simulate(request)
+	assert.Equal(t, []string{"/", "/API", "/Geo"}, simulatedPaths(result.Rules))
+}
+
+func TestMatchNamedValue(t *testing.T) {
+	pairs := [][2]string{{"Session", "abc"}, {"count", "12"}}
+
+	matched, err := matchNamedValue(OptionValue{"cookieName": "session", "matchOperator": "EXISTS"}, "cookieName", pairs, true)
+	assert.NoError(t, err)
+	assert.False(t, matched)
+
+	matched, err = matchNamedValue(OptionValue{"cookieName": "session", "matchOperator": "EXISTS", "matchCaseSensitiveName": false}, "cookieName", pairs, false)
+	assert.NoError(t, err)
+	assert.True(t, matched)
+
+	matched, err = matchNamedValue(OptionValue{"cookieName": "count", "matchOperator": "IS_BETWEEN", "lowerBound": 10.0, "upperBound": 20.0}, "cookieName", pairs, true)
+	assert.NoError(t, err)
+	assert.True(t, matched)
+
+	_, err = matchNamedValue(OptionValue{"cookieName": "count", "matchOperator": "MATCHES_REGEX"}, "cookieName", pairs, true)
+	assert.Error(t, err)
+}
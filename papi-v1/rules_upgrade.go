@@ -0,0 +1,536 @@
+package papi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// SchemaOption describes a behavior or criteria option in a rule format schema
+type SchemaOption struct {
+	Type     string
+	Enum     []interface{}
+	Default  interface{}
+	Required bool
+}
+
+// SchemaCatalog lists the behaviors and criteria of a rule format schema,
+// with their options, keyed by name
+type SchemaCatalog struct {
+	RuleFormat string
+	Behaviors  map[string]map[string]*SchemaOption
+	Criteria   map[string]map[string]*SchemaOption
+}
+
+// ParseSchemaCatalog extracts the behavior and criteria catalog from a rule
+// format schema, as returned by RuleFormats.GetSchemaJSON
+func ParseSchemaCatalog(ruleFormat string, schema []byte) (*SchemaCatalog, error) {
+	var document struct {
+		Definitions struct {
+			Catalog struct {
+				Behaviors map[string]schemaNode `json:"behaviors"`
+				Criteria  map[string]schemaNode `json:"criteria"`
+			} `json:"catalog"`
+		} `json:"definitions"`
+	}
+
+	if err := json.Unmarshal(schema, &document); err != nil {
+		return nil, err
+	}
+
+	return &SchemaCatalog{
+		RuleFormat: ruleFormat,
+		Behaviors:  catalogOptions(document.Definitions.Catalog.Behaviors),
+		Criteria:   catalogOptions(document.Definitions.Catalog.Criteria),
+	}, nil
+}
+
+type schemaNode struct {
+	Type       interface{}           `json:"type"`
+	Ref        string                `json:"$ref"`
+	Enum       []interface{}         `json:"enum"`
+	Default    interface{}           `json:"default"`
+	Required   []string              `json:"required"`
+	Properties map[string]schemaNode `json:"properties"`
+}
+
+func (node schemaNode) typeName() string {
+	switch t := node.Type.(type) {
+	case string:
+		return t
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			types = append(types, fmt.Sprintf("%v", item))
+		}
+		sort.Strings(types)
+		return strings.Join(types, "|")
+	}
+
+	if node.Ref != "" {
+		return node.Ref[strings.LastIndex(node.Ref, "/")+1:]
+	}
+
+	if len(node.Enum) > 0 {
+		return "enum"
+	}
+
+	return ""
+}
+
+func catalogOptions(items map[string]schemaNode) map[string]map[string]*SchemaOption {
+	catalog := make(map[string]map[string]*SchemaOption, len(items))
+	for name, item := range items {
+		options := item.Properties["options"]
+		required := map[string]bool{}
+		for _, option := range options.Required {
+			required[option] = true
+		}
+
+		catalog[name] = make(map[string]*SchemaOption, len(options.Properties))
+		for option, node := range options.Properties {
+			catalog[name][option] = &SchemaOption{
+				Type:     node.typeName(),
+				Enum:     node.Enum,
+				Default:  node.Default,
+				Required: required[option],
+			}
+		}
+	}
+
+	return catalog
+}
+
+// SchemaChange is a difference between two rule format catalogs
+//
+// Kind is "behavior" or "criteria". Option is empty when the whole behavior
+// or criteria was added or removed.
+type SchemaChange struct {
+	Type    ChangeTypeValue
+	Kind    string
+	Name    string
+	Option  string
+	OldType string
+	NewType string
+}
+
+// String returns a one line description of the change
+func (change *SchemaChange) String() string {
+	name := change.Name
+	if change.Option != "" {
+		name += "." + change.Option
+	}
+
+	switch change.Type {
+	case ChangeTypeAdded:
+		return fmt.Sprintf("+ %s %s", change.Kind, name)
+	case ChangeTypeRemoved:
+		return fmt.Sprintf("- %s %s", change.Kind, name)
+	}
+
+	if change.OldType == change.NewType {
+		return fmt.Sprintf("~ %s %s", change.Kind, name)
+	}
+
+	return fmt.Sprintf("~ %s %s: %s => %s", change.Kind, name, change.OldType, change.NewType)
+}
+
+// DiffSchemaCatalogs compares two rule format catalogs
+//
+// Options are reported as modified when their type, allowed values or
+// requiredness changed. Changes are sorted by kind, name and option.
+func DiffSchemaCatalogs(old *SchemaCatalog, new *SchemaCatalog) []*SchemaChange {
+	var changes []*SchemaChange
+	changes = diffCatalog(changes, "behavior", old.Behaviors, new.Behaviors)
+	changes = diffCatalog(changes, "criteria", old.Criteria, new.Criteria)
+
+	return changes
+}
+
+func diffCatalog(changes []*SchemaChange, kind string, old map[string]map[string]*SchemaOption, new map[string]map[string]*SchemaOption) []*SchemaChange {
+	for _, name := range sortedCatalogKeys(old, new) {
+		oldOptions, inOld := old[name]
+		newOptions, inNew := new[name]
+		switch {
+		case !inOld:
+			changes = append(changes, &SchemaChange{Type: ChangeTypeAdded, Kind: kind, Name: name})
+			continue
+		case !inNew:
+			changes = append(changes, &SchemaChange{Type: ChangeTypeRemoved, Kind: kind, Name: name})
+			continue
+		}
+
+		options := map[string]bool{}
+		for option := range oldOptions {
+			options[option] = true
+		}
+		for option := range newOptions {
+			options[option] = true
+		}
+		keys := make([]string, 0, len(options))
+		for option := range options {
+			keys = append(keys, option)
+		}
+		sort.Strings(keys)
+
+		for _, option := range keys {
+			oldOption, newOption := oldOptions[option], newOptions[option]
+			switch {
+			case oldOption == nil:
+				changes = append(changes, &SchemaChange{Type: ChangeTypeAdded, Kind: kind, Name: name, Option: option, NewType: newOption.Type})
+			case newOption == nil:
+				changes = append(changes, &SchemaChange{Type: ChangeTypeRemoved, Kind: kind, Name: name, Option: option, OldType: oldOption.Type})
+			case oldOption.Type != newOption.Type ||
+				oldOption.Required != newOption.Required ||
+				!equalJSON(oldOption.Enum, newOption.Enum):
+				changes = append(changes, &SchemaChange{Type: ChangeTypeModified, Kind: kind, Name: name, Option: option, OldType: oldOption.Type, NewType: newOption.Type})
+			}
+		}
+	}
+
+	return changes
+}
+
+func sortedCatalogKeys(old map[string]map[string]*SchemaOption, new map[string]map[string]*SchemaOption) []string {
+	keys := make([]string, 0, len(old)+len(new))
+	for name := range old {
+		keys = append(keys, name)
+	}
+	for name := range new {
+		if _, ok := old[name]; !ok {
+			keys = append(keys, name)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// RuleMigration is a known automatic change applied by RuleFormatUpgrade
+//
+// Migrate is called with the options of every behavior or criteria of Kind
+// ("behavior" or "criteria") and Name found in the rule tree, and reports
+// whether it changed them.
+type RuleMigration struct {
+	Kind        string
+	Name        string
+	Description string
+	Migrate     func(options OptionValue) (bool, error)
+}
+
+// UpgradeAction is an item of an UpgradeReport
+type UpgradeAction struct {
+	Path    string
+	Message string
+}
+
+// String returns a one line description of the action
+func (action *UpgradeAction) String() string {
+	if action.Path == "" {
+		return action.Message
+	}
+
+	return fmt.Sprintf("%s: %s", action.Path, action.Message)
+}
+
+// UpgradeReport is the outcome of RuleFormatUpgrade.Plan
+//
+// Rules is a migrated copy of the rule tree. Applied lists the automatic
+// migrations made to it, and Manual the problems that must be fixed before
+// it can be saved under the new rule format.
+type UpgradeReport struct {
+	From          string
+	To            string
+	SchemaChanges []*SchemaChange
+	Applied       []*UpgradeAction
+	Manual        []*UpgradeAction
+	Rules         *Rules
+}
+
+// Save freezes the migrated rules on the target rule format
+//
+// Save fails while Manual is not empty; fix the items in Rules and clear
+// Manual first.
+func (report *UpgradeReport) Save() error {
+	if len(report.Manual) > 0 {
+		return fmt.Errorf("%d items need manual attention before upgrading to %s", len(report.Manual), report.To)
+	}
+
+	return report.Rules.Freeze(report.To)
+}
+
+// RuleFormatUpgrade moves a rule tree from one rule format to another
+//
+//	upgrade := papi.NewRuleFormatUpgrade("prd_SPM", "v2017-06-19", "v2018-02-27")
+//	report, err := upgrade.Plan(rules)
+//	for _, action := range report.Manual {
+//		fmt.Println(action)
+//	}
+//	err = report.Save()
+type RuleFormatUpgrade struct {
+	ProductID  string
+	From       string
+	To         string
+	Migrations []*RuleMigration
+}
+
+// NewRuleFormatUpgrade creates a new RuleFormatUpgrade
+func NewRuleFormatUpgrade(productID string, from string, to string) *RuleFormatUpgrade {
+	return &RuleFormatUpgrade{ProductID: productID, From: from, To: to}
+}
+
+// Plan fetches both rule format schemas and migrates a copy of rules
+//
+// See: RuleFormatUpgrade.PlanWithSchemas()
+func (upgrade *RuleFormatUpgrade) Plan(rules *Rules) (*UpgradeReport, error) {
+	ruleFormats := NewRuleFormats()
+	from, err := ruleFormats.GetSchemaJSON(upgrade.ProductID, upgrade.From)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := ruleFormats.GetSchemaJSON(upgrade.ProductID, upgrade.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return upgrade.PlanWithSchemas(rules, from, to)
+}
+
+// PlanWithSchemas migrates a copy of rules using the given schemas
+//
+// Custom Migrations run first. Then, for every behavior and criteria:
+// options whose type changed are converted when the value allows it,
+// required options missing a value are set to their default, and anything
+// else the target schema does not allow is reported as manual. Finally, the
+// migrated tree is validated against the target schema.
+func (upgrade *RuleFormatUpgrade) PlanWithSchemas(rules *Rules, from []byte, to []byte) (*UpgradeReport, error) {
+	fromCatalog, err := ParseSchemaCatalog(upgrade.From, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toCatalog, err := ParseSchemaCatalog(upgrade.To, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UpgradeReport{
+		From:          upgrade.From,
+		To:            upgrade.To,
+		SchemaChanges: DiffSchemaCatalogs(fromCatalog, toCatalog),
+		Rules:         copyRules(rules),
+	}
+	report.Rules.RuleFormat = upgrade.To
+
+	behaviors, err := report.Rules.SelectBehaviors("/**/*")
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range behaviors {
+		upgrade.migrate(report, "behavior", match.Path, match.Behavior.Name, match.Behavior.Options, fromCatalog.Behaviors, toCatalog.Behaviors)
+	}
+
+	criteria, err := report.Rules.SelectCriteria("/**/*")
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range criteria {
+		upgrade.migrate(report, "criteria", match.Path, match.Criteria.Name, match.Criteria.Options, fromCatalog.Criteria, toCatalog.Criteria)
+	}
+
+	if err := validateUpgrade(report, to); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (upgrade *RuleFormatUpgrade) migrate(report *UpgradeReport, kind string, path string, name string, options OptionValue, from map[string]map[string]*SchemaOption, to map[string]map[string]*SchemaOption) {
+	for _, migration := range upgrade.Migrations {
+		if migration.Kind != kind || !strings.EqualFold(migration.Name, name) {
+			continue
+		}
+
+		changed, err := migration.Migrate(options)
+		switch {
+		case err != nil:
+			report.Manual = append(report.Manual, &UpgradeAction{Path: path, Message: fmt.Sprintf("%s failed: %s", migration.Description, err)})
+		case changed:
+			report.Applied = append(report.Applied, &UpgradeAction{Path: path, Message: migration.Description})
+		}
+	}
+
+	target, ok := to[name]
+	if !ok {
+		if _, known := from[name]; known {
+			report.Manual = append(report.Manual, &UpgradeAction{Path: path, Message: fmt.Sprintf("%s %s is not available in %s", kind, name, upgrade.To)})
+		} else {
+			report.Manual = append(report.Manual, &UpgradeAction{Path: path, Message: fmt.Sprintf("%s %s is unknown to both rule formats", kind, name)})
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(options))
+	for option := range options {
+		keys = append(keys, option)
+	}
+	sort.Strings(keys)
+
+	for _, option := range keys {
+		schemaOption, ok := target[option]
+		if !ok {
+			report.Manual = append(report.Manual, &UpgradeAction{Path: path, Message: fmt.Sprintf("option %s is not available in %s", option, upgrade.To)})
+			continue
+		}
+
+		value := options[option]
+		if !matchesSchemaType(value, schemaOption.Type) {
+			converted, ok := convertSchemaType(value, schemaOption.Type)
+			if !ok {
+				report.Manual = append(report.Manual, &UpgradeAction{Path: path, Message: fmt.Sprintf("option %s must be %s in %s, got %s", option, schemaOption.Type, upgrade.To, diffValue(value))})
+				continue
+			}
+			options[option] = converted
+			value = converted
+			report.Applied = append(report.Applied, &UpgradeAction{Path: path, Message: fmt.Sprintf("converted option %s to %s", option, schemaOption.Type)})
+		}
+
+		if len(schemaOption.Enum) > 0 && !enumContains(schemaOption.Enum, value) {
+			report.Manual = append(report.Manual, &UpgradeAction{Path: path, Message: fmt.Sprintf("option %s value %s is not allowed in %s", option, diffValue(value), upgrade.To)})
+		}
+	}
+
+	required := make([]string, 0, len(target))
+	for option, schemaOption := range target {
+		if _, ok := options[option]; schemaOption.Required && !ok {
+			required = append(required, option)
+		}
+	}
+	sort.Strings(required)
+
+	for _, option := range required {
+		if target[option].Default == nil {
+			report.Manual = append(report.Manual, &UpgradeAction{Path: path, Message: fmt.Sprintf("option %s is required in %s", option, upgrade.To)})
+			continue
+		}
+
+		options[option] = copyOption(target[option].Default)
+		report.Applied = append(report.Applied, &UpgradeAction{Path: path, Message: fmt.Sprintf("set required option %s to default %s", option, diffValue(target[option].Default))})
+	}
+}
+
+// validateUpgrade validates the migrated rules against the target schema,
+// adding every violation not already reported to report.Manual
+func validateUpgrade(report *UpgradeReport, schema []byte) error {
+	if len(report.Manual) > 0 {
+		return nil
+	}
+
+	document, err := json.Marshal(struct {
+		Rules *Rule `json:"rules"`
+	}{report.Rules.Rule})
+	if err != nil {
+		return err
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewBytesLoader(document))
+	if err != nil {
+		return err
+	}
+
+	for _, resultErr := range result.Errors() {
+		report.Manual = append(report.Manual, &UpgradeAction{Message: resultErr.String()})
+	}
+
+	return nil
+}
+
+func matchesSchemaType(value interface{}, schemaType string) bool {
+	if value == nil || schemaType == "" || strings.Contains(schemaType, "|") {
+		return true
+	}
+
+	switch schemaType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		switch v := value.(type) {
+		case int, int64:
+			return true
+		case float64:
+			return v == float64(int64(v))
+		}
+		return false
+	case "number":
+		switch value.(type) {
+		case int, int64, float64:
+			return true
+		}
+		return false
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		switch value.(type) {
+		case map[string]interface{}, OptionValue:
+			return true
+		}
+		return false
+	}
+
+	return true
+}
+
+func convertSchemaType(value interface{}, schemaType string) (interface{}, bool) {
+	switch schemaType {
+	case "string":
+		switch v := value.(type) {
+		case bool:
+			return strconv.FormatBool(v), true
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		case int:
+			return strconv.Itoa(v), true
+		}
+	case "boolean":
+		if v, ok := value.(string); ok {
+			b, err := strconv.ParseBool(v)
+			return b, err == nil
+		}
+	case "integer":
+		if v, ok := value.(string); ok {
+			i, err := strconv.Atoi(v)
+			return i, err == nil
+		}
+	case "number":
+		if v, ok := value.(string); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			return f, err == nil
+		}
+	case "array":
+		if value != nil {
+			return []interface{}{value}, true
+		}
+	}
+
+	return nil, false
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, item := range enum {
+		if equalJSON(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,183 @@
+package papi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/jsonhooks-v1"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const upgradeFromSchema = `{
+	"definitions": {
+		"catalog": {
+			"behaviors": {
+				"caching": {"properties": {"name": {"enum": ["caching"]}, "options": {"type": "object", "properties": {
+					"behavior": {"type": "string", "enum": ["MAX_AGE", "NO_STORE"]},
+					"ttl": {"type": "string"}
+				}}}},
+				"origin": {"properties": {"options": {"type": "object", "properties": {
+					"host": {"type": "string"},
+					"port": {"type": "string"}
+				}}}},
+				"gzipResponse": {"properties": {"options": {"type": "object", "properties": {
+					"behavior": {"type": "string"}
+				}}}}
+			},
+			"criteria": {
+				"path": {"properties": {"options": {"type": "object", "properties": {
+					"values": {"type": "array"}
+				}}}}
+			}
+		}
+	}
+}`
+
+const upgradeToSchema = `{
+	"definitions": {
+		"catalog": {
+			"behaviors": {
+				"caching": {"properties": {"name": {"enum": ["caching"]}, "options": {"type": "object", "required": ["mustRevalidate"], "properties": {
+					"behavior": {"type": "string", "enum": ["MAX_AGE", "NO_STORE", "BYPASS_CACHE"]},
+					"ttl": {"type": "string"},
+					"mustRevalidate": {"type": "boolean", "default": false}
+				}}}},
+				"origin": {"properties": {"options": {"type": "object", "properties": {
+					"hostname": {"type": "string"},
+					"port": {"type": "integer"}
+				}}}}
+			},
+			"criteria": {
+				"path": {"properties": {"options": {"type": "object", "properties": {
+					"values": {"type": "array"}
+				}}}}
+			}
+		}
+	}
+}`
+
+func newUpgradeTestRules(t *testing.T) *Rules {
+	rules := NewRules()
+	err := jsonhooks.Unmarshal([]byte(`{
+		"propertyId": "prp_123",
+		"propertyVersion": 2,
+		"ruleFormat": "v2017-06-19",
+		"rules": {
+			"name": "default",
+			"behaviors": [
+				{"name": "origin", "options": {"host": "origin.example.com", "port": "80"}},
+				{"name": "caching", "options": {"behavior": "MAX_AGE", "ttl": "1d"}}
+			],
+			"children": [
+				{
+					"name": "Compress",
+					"criteria": [{"name": "path", "options": {"values": ["*.js"]}}],
+					"behaviors": [{"name": "gzipResponse", "options": {"behavior": "ALWAYS"}}]
+				}
+			]
+		}
+	}`), rules)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	return rules
+}
+
+func TestDiffSchemaCatalogs(t *testing.T) {
+	from, err := ParseSchemaCatalog("v2017-06-19", []byte(upgradeFromSchema))
+	assert.NoError(t, err)
+	to, err := ParseSchemaCatalog("v2018-02-27", []byte(upgradeToSchema))
+	assert.NoError(t, err)
+
+	var changes []string
+	for _, change := range DiffSchemaCatalogs(from, to) {
+		changes = append(changes, change.String())
+	}
+
+	assert.Equal(t, []string{
+		"~ behavior caching.behavior",
+		"+ behavior caching.mustRevalidate",
+		"- behavior gzipResponse",
+		"- behavior origin.host",
+		"+ behavior origin.hostname",
+		"~ behavior origin.port: string => integer",
+	}, changes)
+}
+
+func TestRuleFormatUpgrade_PlanWithSchemas(t *testing.T) {
+	rules := newUpgradeTestRules(t)
+	upgrade := NewRuleFormatUpgrade("prd_SPM", "v2017-06-19", "v2018-02-27")
+	upgrade.Migrations = []*RuleMigration{{
+		Kind:        "behavior",
+		Name:        "origin",
+		Description: "renamed option host to hostname",
+		Migrate: func(options OptionValue) (bool, error) {
+			host, ok := options["host"]
+			if ok {
+				options["hostname"] = host
+				delete(options, "host")
+			}
+			return ok, nil
+		},
+	}}
+
+	report, err := upgrade.PlanWithSchemas(rules, []byte(upgradeFromSchema), []byte(upgradeToSchema))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var applied, manual []string
+	for _, action := range report.Applied {
+		applied = append(applied, action.String())
+	}
+	for _, action := range report.Manual {
+		manual = append(manual, action.String())
+	}
+
+	assert.Equal(t, []string{
+		"/origin: renamed option host to hostname",
+		"/origin: converted option port to integer",
+		"/caching: set required option mustRevalidate to default false",
+	}, applied)
+	assert.Equal(t, []string{
+		"/Compress/gzipResponse: behavior gzipResponse is not available in v2018-02-27",
+	}, manual)
+
+	assert.Equal(t, "v2018-02-27", report.Rules.RuleFormat)
+	assert.Equal(t, 80, report.Rules.Rule.Behaviors[0].Options["port"])
+	assert.Equal(t, "80", rules.Rule.Behaviors[0].Options["port"])
+
+	err = report.Save()
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "manual attention"))
+	}
+}
+
+func TestRuleFormatUpgrade_Plan(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/schemas/products/prd_SPM/v2017-06-19").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(upgradeFromSchema)
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/schemas/products/prd_SPM/v2018-02-27").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(upgradeToSchema)
+
+	Init(config)
+
+	rules := newUpgradeTestRules(t)
+	rules.Rule.Children = nil
+	rules.Rule.Behaviors[0].Options = OptionValue{"hostname": "origin.example.com", "port": 80}
+
+	report, err := NewRuleFormatUpgrade("prd_SPM", "v2017-06-19", "v2018-02-27").Plan(rules)
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+	assert.Empty(t, report.Manual)
+	assert.Len(t, report.Applied, 1)
+}
@@ -0,0 +1,428 @@
+package papi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/jsonhooks-v1"
+)
+
+// Labels of an IndexedVersion
+const (
+	IndexLabelLatest     = "latest"
+	IndexLabelStaging    = "staging"
+	IndexLabelProduction = "production"
+)
+
+// IndexedProperty is a property stored in a SearchIndex
+type IndexedProperty struct {
+	AccountID    string            `json:"accountId"`
+	ContractID   string            `json:"contractId"`
+	GroupID      string            `json:"groupId"`
+	PropertyID   string            `json:"propertyId"`
+	PropertyName string            `json:"propertyName"`
+	Versions     []*IndexedVersion `json:"versions"`
+}
+
+// IndexedVersion is a property version stored in a SearchIndex
+//
+// Labels tells which of the latest, staging and production versions it is.
+type IndexedVersion struct {
+	PropertyVersion  int         `json:"propertyVersion"`
+	Labels           []string    `json:"labels"`
+	Etag             string      `json:"etag"`
+	StagingStatus    StatusValue `json:"stagingStatus,omitempty"`
+	ProductionStatus StatusValue `json:"productionStatus,omitempty"`
+	Rules            *Rules      `json:"rules"`
+}
+
+// immutable reports whether the version was ever activated, in which case
+// its rules can no longer change
+func (version *IndexedVersion) immutable() bool {
+	return (version.StagingStatus != "" && version.StagingStatus != StatusInactive) ||
+		(version.ProductionStatus != "" && version.ProductionStatus != StatusInactive)
+}
+
+// SearchHit is a behavior found by a SearchIndex query
+type SearchHit struct {
+	PropertyID      string
+	PropertyName    string
+	PropertyVersion int
+	Labels          []string
+	Path            string
+	Behavior        *Behavior
+}
+
+// String returns a one line description of the hit
+func (hit *SearchHit) String() string {
+	return fmt.Sprintf("%s (%s) v%d [%s] %s", hit.PropertyName, hit.PropertyID, hit.PropertyVersion, strings.Join(hit.Labels, ","), hit.Path)
+}
+
+// IndexRefresh summarizes the changes made by SearchIndex.Refresh
+//
+// Fetched counts the rule trees downloaded.
+type IndexRefresh struct {
+	Added     []string
+	Updated   []string
+	Unchanged []string
+	Removed   []string
+	Fetched   int
+}
+
+// SearchIndex is a local, on-disk copy of the rule trees of many properties
+//
+// The latest, staging and production versions of every property are kept,
+// one JSON file per property in Dir. Refresh only downloads rule trees of
+// versions that are new, or whose etag changed.
+//
+//	index, err := papi.OpenSearchIndex("/var/cache/papi-index")
+//	groups, err := papi.GetGroups()
+//	_, err = index.RefreshGroups(groups.Groups.Items)
+//	hits, err := index.FindOrigin("origin.example.com")
+type SearchIndex struct {
+	Dir        string
+	Properties map[string]*IndexedProperty
+}
+
+// OpenSearchIndex loads the index stored in dir, creating dir if needed
+func OpenSearchIndex(dir string) (*SearchIndex, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	index := &SearchIndex{Dir: dir, Properties: map[string]*IndexedProperty{}}
+
+	files, err := filepath.Glob(filepath.Join(dir, "prp_*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		body, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		property := &IndexedProperty{}
+		if err := jsonhooks.Unmarshal(body, property); err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+		index.Properties[property.PropertyID] = property
+	}
+
+	return index, nil
+}
+
+// RefreshGroups refreshes every group, for each of its contracts
+func (index *SearchIndex) RefreshGroups(groups []*Group) (*IndexRefresh, error) {
+	refresh := &IndexRefresh{}
+	for _, group := range groups {
+		for _, contractID := range group.ContractIDs {
+			contract := NewContract(NewContracts())
+			contract.ContractID = contractID
+
+			groupRefresh, err := index.Refresh(contract, group)
+			if err != nil {
+				return refresh, err
+			}
+
+			refresh.Added = append(refresh.Added, groupRefresh.Added...)
+			refresh.Updated = append(refresh.Updated, groupRefresh.Updated...)
+			refresh.Unchanged = append(refresh.Unchanged, groupRefresh.Unchanged...)
+			refresh.Removed = append(refresh.Removed, groupRefresh.Removed...)
+			refresh.Fetched += groupRefresh.Fetched
+		}
+	}
+
+	return refresh, nil
+}
+
+// Refresh updates the index with the properties of a contract and group
+//
+// Indexed properties of the contract and group that no longer exist are
+// removed. Versions that were activated are never fetched again; other
+// versions are fetched again only when their etag changed.
+func (index *SearchIndex) Refresh(contract *Contract, group *Group) (*IndexRefresh, error) {
+	properties, err := GetProperties(contract, group)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh := &IndexRefresh{}
+	seen := map[string]bool{}
+	for _, property := range properties.Properties.Items {
+		if property.ContractID == "" {
+			property.ContractID = contract.ContractID
+		}
+		if property.GroupID == "" {
+			property.GroupID = group.GroupID
+		}
+		seen[property.PropertyID] = true
+
+		previous := index.Properties[property.PropertyID]
+		indexed, fetched, err := index.refreshProperty(property, previous)
+		if err != nil {
+			return refresh, err
+		}
+		refresh.Fetched += fetched
+
+		switch {
+		case previous == nil:
+			refresh.Added = append(refresh.Added, property.PropertyID)
+		case fetched > 0 || previous.PropertyName != indexed.PropertyName ||
+			!equalJSON(versionStates(previous), versionStates(indexed)):
+			refresh.Updated = append(refresh.Updated, property.PropertyID)
+		default:
+			refresh.Unchanged = append(refresh.Unchanged, property.PropertyID)
+			continue
+		}
+
+		if err := index.write(indexed); err != nil {
+			return refresh, err
+		}
+		index.Properties[property.PropertyID] = indexed
+	}
+
+	for _, id := range index.PropertyIDs() {
+		property := index.Properties[id]
+		if property.ContractID != contract.ContractID || property.GroupID != group.GroupID || seen[id] {
+			continue
+		}
+
+		if err := os.Remove(index.file(id)); err != nil && !os.IsNotExist(err) {
+			return refresh, err
+		}
+		delete(index.Properties, id)
+		refresh.Removed = append(refresh.Removed, id)
+	}
+
+	return refresh, nil
+}
+
+func (index *SearchIndex) refreshProperty(property *Property, previous *IndexedProperty) (*IndexedProperty, int, error) {
+	indexed := &IndexedProperty{
+		AccountID:    property.AccountID,
+		ContractID:   property.ContractID,
+		GroupID:      property.GroupID,
+		PropertyID:   property.PropertyID,
+		PropertyName: property.PropertyName,
+	}
+
+	labels := map[int][]string{}
+	var numbers []int
+	for _, label := range []struct {
+		name    string
+		version int
+	}{
+		{IndexLabelLatest, property.LatestVersion},
+		{IndexLabelStaging, property.StagingVersion},
+		{IndexLabelProduction, property.ProductionVersion},
+	} {
+		if label.version == 0 {
+			continue
+		}
+		if _, ok := labels[label.version]; !ok {
+			numbers = append(numbers, label.version)
+		}
+		labels[label.version] = append(labels[label.version], label.name)
+	}
+	sort.Ints(numbers)
+
+	old := map[int]*IndexedVersion{}
+	if previous != nil {
+		for _, version := range previous.Versions {
+			old[version.PropertyVersion] = version
+		}
+	}
+
+	fetched := 0
+	for _, number := range numbers {
+		indexedVersion := old[number]
+		if indexedVersion != nil {
+			reused := *indexedVersion
+			indexedVersion = &reused
+		}
+
+		if indexedVersion == nil || !indexedVersion.immutable() {
+			version := &Version{}
+			if err := version.GetVersion(property, number); err != nil {
+				return nil, fetched, err
+			}
+
+			if indexedVersion == nil || indexedVersion.Etag != version.Etag {
+				rules := NewRules()
+				if err := rules.GetRulesForVersion(property, number); err != nil {
+					return nil, fetched, err
+				}
+				fetched++
+				indexedVersion = &IndexedVersion{PropertyVersion: number, Etag: version.Etag, Rules: rules}
+			}
+			indexedVersion.StagingStatus = version.StagingStatus
+			indexedVersion.ProductionStatus = version.ProductionStatus
+		}
+
+		indexedVersion.Labels = labels[number]
+		indexed.Versions = append(indexed.Versions, indexedVersion)
+	}
+
+	return indexed, fetched, nil
+}
+
+// versionStates summarizes what may change on indexed versions without
+// their rules being fetched again
+func versionStates(property *IndexedProperty) map[int]string {
+	states := map[int]string{}
+	for _, version := range property.Versions {
+		states[version.PropertyVersion] = fmt.Sprintf("%s %s %s", strings.Join(version.Labels, ","), version.StagingStatus, version.ProductionStatus)
+	}
+
+	return states
+}
+
+func (index *SearchIndex) file(propertyID string) string {
+	return filepath.Join(index.Dir, propertyID+".json")
+}
+
+// write stores a property, replacing the previous file atomically
+func (index *SearchIndex) write(property *IndexedProperty) error {
+	body, err := json.Marshal(property)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(index.Dir, property.PropertyID+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), index.file(property.PropertyID))
+}
+
+// PropertyIDs returns the IDs of every indexed property, sorted
+func (index *SearchIndex) PropertyIDs() []string {
+	ids := make([]string, 0, len(index.Properties))
+	for id := range index.Properties {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// Select runs Rules.SelectBehaviors against every indexed version
+func (index *SearchIndex) Select(selector string) ([]*SearchHit, error) {
+	return index.find(func(rules *Rules) ([]*BehaviorMatch, error) {
+		return rules.SelectBehaviors(selector)
+	})
+}
+
+// FindBehaviors finds behaviors by name and, optionally, option value
+//
+// option may be a dotted path such as "value.id". When option is empty,
+// every behavior named name matches. When value is empty, any behavior with
+// option set matches; otherwise value is compared case-insensitively and
+// may contain "*" wildcards.
+func (index *SearchIndex) FindBehaviors(name string, option string, value string) ([]*SearchHit, error) {
+	var keys []string
+	if option != "" {
+		var err error
+		if keys, err = splitOptionKey(option); err != nil {
+			return nil, err
+		}
+	}
+
+	return index.find(func(rules *Rules) ([]*BehaviorMatch, error) {
+		matches, err := rules.SelectBehaviors("/**/*")
+		if err != nil {
+			return nil, err
+		}
+
+		var found []*BehaviorMatch
+		for _, match := range matches {
+			if !strings.EqualFold(match.Behavior.Name, name) {
+				continue
+			}
+
+			if keys != nil {
+				actual, ok := lookupOption(match.Behavior.Options, keys)
+				if !ok || (value != "" && !matchValue(value, formatOptionValue(actual), true, false)) {
+					continue
+				}
+			}
+
+			found = append(found, match)
+		}
+
+		return found, nil
+	})
+}
+
+// FindOrigin finds origin behaviors pointing at hostname
+func (index *SearchIndex) FindOrigin(hostname string) ([]*SearchHit, error) {
+	return index.FindBehaviors("origin", "hostname", hostname)
+}
+
+// FindCpCode finds cpCode behaviors using a CP code
+func (index *SearchIndex) FindCpCode(cpCodeID int) ([]*SearchHit, error) {
+	return index.find(func(rules *Rules) ([]*BehaviorMatch, error) {
+		references, err := rules.FindCpCodeReferences()
+		if err != nil {
+			return nil, err
+		}
+
+		var found []*BehaviorMatch
+		for _, reference := range references {
+			if reference.CpCodeID == cpCodeID {
+				found = append(found, &BehaviorMatch{Behavior: reference.Behavior, Rule: reference.Rule, Path: reference.Path})
+			}
+		}
+
+		return found, nil
+	})
+}
+
+func (index *SearchIndex) find(match func(rules *Rules) ([]*BehaviorMatch, error)) ([]*SearchHit, error) {
+	var hits []*SearchHit
+	for _, id := range index.PropertyIDs() {
+		property := index.Properties[id]
+		for _, version := range property.Versions {
+			if version.Rules == nil {
+				continue
+			}
+
+			matches, err := match(version.Rules)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, behavior := range matches {
+				hits = append(hits, &SearchHit{
+					PropertyID:      property.PropertyID,
+					PropertyName:    property.PropertyName,
+					PropertyVersion: version.PropertyVersion,
+					Labels:          version.Labels,
+					Path:            behavior.Path,
+					Behavior:        behavior.Behavior,
+				})
+			}
+		}
+	}
+
+	return hits, nil
+}
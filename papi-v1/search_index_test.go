@@ -0,0 +1,129 @@
+package papi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func mockIndexVersion(version int, status StatusValue, etag string) {
+	gock.New(orchestratorTestURL).
+		Get(fmt.Sprintf("/papi/v1/properties/prp_1/versions/%d$", version)).
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(fmt.Sprintf(`{"versions": {"items": [{"propertyVersion": %d, "productionStatus": "%s", "stagingStatus": "INACTIVE", "etag": "%s"}]}}`, version, status, etag))
+}
+
+func mockIndexRules(version int, origin string, cpCode int) {
+	gock.New(orchestratorTestURL).
+		Get(fmt.Sprintf("/papi/v1/properties/prp_1/versions/%d/rules$", version)).
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(fmt.Sprintf(`{
+			"propertyId": "prp_1",
+			"propertyVersion": %d,
+			"rules": {
+				"name": "default",
+				"behaviors": [
+					{"name": "origin", "options": {"hostname": "%s"}},
+					{"name": "cpCode", "options": {"value": {"id": %d}}}
+				]
+			}
+		}`, version, origin, cpCode))
+}
+
+func mockIndexProperties() {
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"properties": {"items": [{
+			"contractId": "ctr_1",
+			"groupId": "grp_1",
+			"propertyId": "prp_1",
+			"propertyName": "www.example.com",
+			"latestVersion": 2,
+			"productionVersion": 1
+		}]}}`)
+}
+
+func TestSearchIndex_Refresh(t *testing.T) {
+	defer gock.Off()
+
+	mockIndexProperties()
+	mockIndexVersion(1, StatusActive, "e1")
+	mockIndexRules(1, "old.example.com", 1234567)
+	mockIndexVersion(2, StatusInactive, "e2")
+	mockIndexRules(2, "new.example.com", 7654321)
+
+	Init(config)
+
+	dir := t.TempDir()
+	index, err := OpenSearchIndex(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	contract := NewContract(NewContracts())
+	contract.ContractID = "ctr_1"
+	group := NewGroup(NewGroups())
+	group.GroupID = "grp_1"
+
+	refresh, err := index.Refresh(contract, group)
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+	assert.Equal(t, []string{"prp_1"}, refresh.Added)
+	assert.Equal(t, 2, refresh.Fetched)
+
+	// version 1 is active and version 2 has the same etag, nothing is fetched
+	mockIndexProperties()
+	mockIndexVersion(2, StatusInactive, "e2")
+
+	refresh, err = index.Refresh(contract, group)
+	assert.NoError(t, err)
+	assert.True(t, gock.IsDone())
+	assert.Equal(t, []string{"prp_1"}, refresh.Unchanged)
+	assert.Equal(t, 0, refresh.Fetched)
+
+	index, err = OpenSearchIndex(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	hits, err := index.FindOrigin("*.EXAMPLE.com")
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 2) {
+		assert.Equal(t, []string{IndexLabelProduction}, hits[0].Labels)
+		assert.Equal(t, "/origin", hits[0].Path)
+		assert.Equal(t, 2, hits[1].PropertyVersion)
+		assert.Equal(t, []string{IndexLabelLatest}, hits[1].Labels)
+	}
+
+	hits, err = index.FindCpCode(7654321)
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, "www.example.com (prp_1) v2 [latest] /cpCode", hits[0].String())
+	}
+
+	hits, err = index.FindBehaviors("cpCode", "value.id", "1234567")
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+
+	hits, err = index.Select("/**/origin[hostname=old.example.com]")
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+
+	// the property is gone
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"properties": {"items": []}}`)
+
+	refresh, err = index.Refresh(contract, group)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"prp_1"}, refresh.Removed)
+	assert.Empty(t, index.PropertyIDs())
+}
@@ -0,0 +1,377 @@
+package papi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
+)
+
+// PropertyVersionSession edits a new property version created from a base version
+//
+// Rules and Hostnames are working copies of the new version and may be edited
+// freely; nothing is sent to the API until Save is called. Every update is
+// made with the etag the session read, so concurrent edits cause Save to
+// fail rather than be overwritten.
+//
+//	session, err := papi.NewPropertyVersionSession(property, nil)
+//	session.Rules.SetBehaviorOption("/**/caching", "ttl", "1d")
+//	session.AddHostname("www.example.com", "ehn_123")
+//	fmt.Println(session.Diff())
+//	err = session.Save()
+//	result, err := session.Activate(ctx, nil)
+type PropertyVersionSession struct {
+	Property  *Property
+	Base      *Version
+	Version   *Version
+	Rules     *Rules
+	Hostnames *Hostnames
+
+	baseRules     *Rules
+	baseHostnames *Hostnames
+	savedRules    *Rules
+	savedHosts    *Hostnames
+}
+
+// NewPropertyVersionSession creates a new version of property from base and
+// opens a session on it
+//
+// When base is nil the latest version is used. The version is created with
+// its etag, so creation fails if base changed since it was retrieved.
+//
+// API Docs: https://developer.akamai.com/api/luna/papi/resources.html#createanewversion
+// Endpoint: POST /papi/v1/properties/{propertyId}/versions/{?contractId,groupId}
+func NewPropertyVersionSession(property *Property, base *Version) (*PropertyVersionSession, error) {
+	versions := NewVersions()
+	versions.PropertyID = property.PropertyID
+	versions.ContractID = propertyContractID(property)
+	versions.GroupID = propertyGroupID(property)
+
+	if base == nil {
+		var err error
+		base, err = versions.GetLatestVersion("")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if base.Etag == "" {
+		if err := base.GetVersion(property, base.PropertyVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	version := versions.NewVersion(base, true)
+	if err := version.Save(); err != nil {
+		return nil, err
+	}
+
+	session := &PropertyVersionSession{
+		Property: property,
+		Base:     base,
+		Version:  version,
+	}
+
+	if err := session.load(); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// OpenPropertyVersionSession opens a session on an existing, editable version
+//
+// The base is the version it was created from, or the version itself when
+// that is unknown.
+func OpenPropertyVersionSession(property *Property, version *Version) (*PropertyVersionSession, error) {
+	if (version.StagingStatus != "" && version.StagingStatus != StatusInactive) ||
+		(version.ProductionStatus != "" && version.ProductionStatus != StatusInactive) {
+		return nil, fmt.Errorf("version %d has been activated and can not be edited", version.PropertyVersion)
+	}
+
+	base := version
+	if version.CreateFromVersion != 0 {
+		base = NewVersion(version.parent)
+		if err := base.GetVersion(property, version.CreateFromVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	session := &PropertyVersionSession{
+		Property: property,
+		Base:     base,
+		Version:  version,
+	}
+
+	if err := session.load(); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (session *PropertyVersionSession) load() error {
+	var err error
+	if session.baseRules, err = session.getRules(session.Base.PropertyVersion); err != nil {
+		return err
+	}
+
+	if session.baseHostnames, err = session.getHostnames(session.Base.PropertyVersion); err != nil {
+		return err
+	}
+
+	if session.Rules, err = session.getRules(session.Version.PropertyVersion); err != nil {
+		return err
+	}
+
+	if session.Hostnames, err = session.getHostnames(session.Version.PropertyVersion); err != nil {
+		return err
+	}
+
+	session.savedRules = copyRules(session.Rules)
+	session.savedHosts = copyHostnames(session.Hostnames)
+
+	return nil
+}
+
+func (session *PropertyVersionSession) getRules(version int) (*Rules, error) {
+	rules := NewRules()
+	if err := rules.GetRulesForVersion(session.Property, version); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (session *PropertyVersionSession) getHostnames(version int) (*Hostnames, error) {
+	hostnames := NewHostnames()
+	hostnames.PropertyID = session.Property.PropertyID
+	hostnames.ContractID = propertyContractID(session.Property)
+	hostnames.GroupID = propertyGroupID(session.Property)
+
+	v := &Version{PropertyVersion: version}
+	if err := hostnames.GetHostnames(v); err != nil {
+		return nil, err
+	}
+
+	hostnames.PropertyVersion = version
+
+	return hostnames, nil
+}
+
+// AddHostname adds or replaces a hostname pointing at an edge hostname
+func (session *PropertyVersionSession) AddHostname(cnameFrom string, edgeHostnameID string) *Hostname {
+	for _, hostname := range session.Hostnames.Hostnames.Items {
+		if strings.EqualFold(hostname.CnameFrom, cnameFrom) {
+			hostname.CnameType = CnameTypeEdgeHostname
+			hostname.EdgeHostnameID = edgeHostnameID
+			hostname.CnameTo = ""
+			return hostname
+		}
+	}
+
+	hostname := session.Hostnames.NewHostname()
+	hostname.CnameFrom = cnameFrom
+	hostname.EdgeHostnameID = edgeHostnameID
+
+	return hostname
+}
+
+// RemoveHostname removes a hostname, reporting whether it was present
+func (session *PropertyVersionSession) RemoveHostname(cnameFrom string) bool {
+	return removeHostname(session.Hostnames, cnameFrom)
+}
+
+// Diff compares the working copy against the base version
+func (session *PropertyVersionSession) Diff() *VersionDiff {
+	return &VersionDiff{
+		Rules:     DiffRules(session.baseRules, session.Rules),
+		Hostnames: DiffHostnames(session.baseHostnames, session.Hostnames),
+	}
+}
+
+// Pending compares the working copy against the last saved state
+func (session *PropertyVersionSession) Pending() *VersionDiff {
+	return &VersionDiff{
+		Rules:     DiffRules(session.savedRules, session.Rules),
+		Hostnames: DiffHostnames(session.savedHosts, session.Hostnames),
+	}
+}
+
+// Save sends pending rule and hostname changes
+//
+// Both updates are etag-checked. If the hostname update fails after the
+// rules were saved, the previous rules are restored before the error is
+// returned, so the version is left as it was.
+//
+// Rules with validation errors are still stored by the API: they are then
+// considered saved, the hostnames are saved too, and ErrInvalidRules is
+// returned with the details in Rules.Errors.
+func (session *PropertyVersionSession) Save() error {
+	pending := session.Pending()
+	if pending.Empty() {
+		return nil
+	}
+
+	var restore *Rules
+	var rulesErr error
+	if len(pending.Rules) > 0 {
+		restore = copyRules(session.savedRules)
+		if err := session.Rules.SaveStrict(); err != nil {
+			if err != ErrorMap[ErrInvalidRules] {
+				return err
+			}
+			rulesErr = err
+		}
+		restore.Etag = session.Rules.Etag
+		session.savedRules = copyRules(session.Rules)
+	}
+
+	if len(pending.Hostnames) > 0 {
+		session.Hostnames.PropertyVersion = session.Version.PropertyVersion
+		if err := session.Hostnames.SaveStrict(); err != nil {
+			if restore != nil {
+				if restoreErr := restore.SaveStrict(); restoreErr != nil && restoreErr != ErrorMap[ErrInvalidRules] {
+					return fmt.Errorf("%s (restoring rules: %s)", err, restoreErr)
+				}
+				session.Rules.Etag = restore.Etag
+				session.savedRules = copyRules(restore)
+			}
+			return err
+		}
+		session.savedHosts = copyHostnames(session.Hostnames)
+	}
+
+	return rulesErr
+}
+
+// Activate saves pending changes and activates the session version
+//
+// When orchestrator is nil, NewActivationOrchestrator is used to activate on
+// staging, then production.
+func (session *PropertyVersionSession) Activate(ctx context.Context, orchestrator *ActivationOrchestrator) (*ActivationResult, error) {
+	if err := session.Save(); err != nil {
+		return nil, err
+	}
+
+	if orchestrator == nil {
+		orchestrator = NewActivationOrchestrator(session.Property, session.Version.PropertyVersion)
+	}
+	orchestrator.Property = session.Property
+	orchestrator.PropertyVersion = session.Version.PropertyVersion
+
+	return orchestrator.Run(ctx)
+}
+
+// IsPreconditionFailed reports whether err is an etag mismatch returned by
+// SaveStrict
+func IsPreconditionFailed(err error) bool {
+	var apiErr client.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status == 412
+	}
+
+	return false
+}
+
+func propertyContractID(property *Property) string {
+	if property.ContractID == "" && property.Contract != nil {
+		return property.Contract.ContractID
+	}
+
+	return property.ContractID
+}
+
+func propertyGroupID(property *Property) string {
+	if property.GroupID == "" && property.Group != nil {
+		return property.Group.GroupID
+	}
+
+	return property.GroupID
+}
+
+// copyRules deep copies a rule tree, including its identifying fields
+func copyRules(rules *Rules) *Rules {
+	newRules := NewRules()
+	newRules.AccountID = rules.AccountID
+	newRules.ContractID = rules.ContractID
+	newRules.GroupID = rules.GroupID
+	newRules.PropertyID = rules.PropertyID
+	newRules.PropertyVersion = rules.PropertyVersion
+	newRules.Etag = rules.Etag
+	newRules.RuleFormat = rules.RuleFormat
+	newRules.Rule = copyRule(rules.Rule)
+
+	return newRules
+}
+
+func copyRule(rule *Rule) *Rule {
+	if rule == nil {
+		return nil
+	}
+
+	newRule := NewRule()
+	newRule.Depth = rule.Depth
+	newRule.Name = rule.Name
+	newRule.Comments = rule.Comments
+	newRule.CriteriaLocked = rule.CriteriaLocked
+	newRule.CriteriaMustSatisfy = rule.CriteriaMustSatisfy
+	newRule.UUID = rule.UUID
+	newRule.AdvancedOverride = rule.AdvancedOverride
+	newRule.Options = rule.Options
+
+	if rule.CustomOverride != nil {
+		customOverride := *rule.CustomOverride
+		newRule.CustomOverride = &customOverride
+	}
+
+	for _, criteria := range rule.Criteria {
+		newCriteria := NewCriteria()
+		newCriteria.Name = criteria.Name
+		newCriteria.UUID = criteria.UUID
+		newCriteria.Locked = criteria.Locked
+		newCriteria.Options = copyOptionValue(criteria.Options)
+		newRule.Criteria = append(newRule.Criteria, newCriteria)
+	}
+
+	for _, behavior := range rule.Behaviors {
+		newBehavior := copyBehavior(behavior)
+		newBehavior.UUID = behavior.UUID
+		newRule.Behaviors = append(newRule.Behaviors, newBehavior)
+	}
+
+	for _, variable := range rule.Variables {
+		newVariable := *variable
+		newRule.Variables = append(newRule.Variables, &newVariable)
+	}
+
+	for _, child := range rule.Children {
+		newRule.Children = append(newRule.Children, copyRule(child))
+	}
+
+	return newRule
+}
+
+func copyHostnames(hostnames *Hostnames) *Hostnames {
+	newHostnames := NewHostnames()
+	newHostnames.AccountID = hostnames.AccountID
+	newHostnames.ContractID = hostnames.ContractID
+	newHostnames.GroupID = hostnames.GroupID
+	newHostnames.PropertyID = hostnames.PropertyID
+	newHostnames.PropertyVersion = hostnames.PropertyVersion
+	newHostnames.Etag = hostnames.Etag
+
+	for _, hostname := range hostnames.Hostnames.Items {
+		newHostname := NewHostname(newHostnames)
+		newHostname.CnameType = hostname.CnameType
+		newHostname.EdgeHostnameID = hostname.EdgeHostnameID
+		newHostname.CnameFrom = hostname.CnameFrom
+		newHostname.CnameTo = hostname.CnameTo
+		newHostname.CertEnrollmentId = hostname.CertEnrollmentId
+		newHostnames.Hostnames.Items = append(newHostnames.Hostnames.Items, newHostname)
+	}
+
+	return newHostnames
+}
@@ -0,0 +1,229 @@
+package papi
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func sessionRulesBody(version int, etag string, ttl string) string {
+	return fmt.Sprintf(`{
+		"propertyId": "prp_123",
+		"propertyVersion": %d,
+		"etag": "%s",
+		"ruleFormat": "v2018-02-27",
+		"rules": {
+			"name": "default",
+			"behaviors": [{"name": "caching", "options": {"behavior": "MAX_AGE", "ttl": "%s"}}]
+		}
+	}`, version, etag, ttl)
+}
+
+func sessionHostnamesBody(version int, etag string) string {
+	return fmt.Sprintf(`{
+		"propertyId": "prp_123",
+		"propertyVersion": %d,
+		"etag": "%s",
+		"hostnames": {"items": [
+			{"cnameType": "EDGE_HOSTNAME", "edgeHostnameId": "ehn_1", "cnameFrom": "www.example.com"}
+		]}
+	}`, version, etag)
+}
+
+func mockSessionLoad(version int, rulesEtag string, hostnamesEtag string) {
+	gock.New(orchestratorTestURL).
+		Get(fmt.Sprintf("/papi/v1/properties/prp_123/versions/%d/rules$", version)).
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(sessionRulesBody(version, rulesEtag, "1d"))
+	gock.New(orchestratorTestURL).
+		Get(fmt.Sprintf("/papi/v1/properties/prp_123/versions/%d/hostnames/", version)).
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(sessionHostnamesBody(version, hostnamesEtag))
+}
+
+// bodyContains matches requests whose body contains s
+func bodyContains(s string) gock.MatchFunc {
+	return func(req *http.Request, _ *gock.Request) (bool, error) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return false, err
+		}
+		return strings.Contains(string(body), s), nil
+	}
+}
+
+func openTestSession(t *testing.T) *PropertyVersionSession {
+	mockSessionLoad(2, "r2", "h2")
+	mockSessionLoad(2, "r2", "h2")
+
+	session, err := OpenPropertyVersionSession(newOrchestratorTestProperty(), &Version{PropertyVersion: 2})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	_, err = session.Rules.SetBehaviorOption("/caching", "ttl", "7d")
+	assert.NoError(t, err)
+	session.AddHostname("api.example.com", "ehn_1")
+
+	return session
+}
+
+func TestNewPropertyVersionSession_PreconditionFailed(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Post("/papi/v1/properties/prp_123/versions").
+		AddMatcher(bodyContains(`"createFromVersionEtag":"e1"`)).
+		Reply(412).
+		SetHeader("Content-Type", "application/problem+json").
+		BodyString(`{"type": "/papi/v1/errors/precondition-failed", "title": "Precondition Failed", "status": 412}`)
+
+	Init(config)
+
+	_, err := NewPropertyVersionSession(newOrchestratorTestProperty(), &Version{PropertyVersion: 1, Etag: "e1"})
+	assert.True(t, IsPreconditionFailed(err))
+	assert.True(t, gock.IsDone())
+}
+
+func TestPropertyVersionSession_Save(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(orchestratorTestURL).
+		Post("/papi/v1/properties/prp_123/versions").
+		AddMatcher(bodyContains(`"createFromVersionEtag":"e1"`)).
+		Reply(201).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"versionLink": "/papi/v1/properties/prp_123/versions/2?contractId=ctr_TEST&groupId=grp_123"}`)
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_123/versions/2$").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"versions": {"items": [{"propertyVersion": 2, "createFromVersion": 1, "etag": "e2", "stagingStatus": "INACTIVE", "productionStatus": "INACTIVE"}]}}`)
+	mockSessionLoad(1, "r1", "h1")
+	mockSessionLoad(2, "r2", "h2")
+	gock.New(orchestratorTestURL).
+		Put("/papi/v1/properties/prp_123/versions/2/rules").
+		MatchHeader("If-Match", "r2").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(sessionRulesBody(2, "r3", "7d"))
+	gock.New(orchestratorTestURL).
+		Put("/papi/v1/properties/prp_123/versions/2/hostnames").
+		MatchHeader("If-Match", "h2").
+		AddMatcher(bodyContains(`"cnameFrom":"api.example.com"`)).
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(sessionHostnamesBody(2, "h3"))
+	gock.New(orchestratorTestURL).
+		Post("/papi/v1/properties/prp_123/activations").
+		Reply(201).
+		SetHeader("Content-Type", "application/json").
+		BodyString(`{"activationLink": "/papi/v1/properties/prp_123/activations/atv_1?contractId=ctr_TEST&groupId=grp_123"}`)
+	gock.New(orchestratorTestURL).
+		Get("/papi/v1/properties/prp_123/activations/atv_1").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(activationBody(StatusActive))
+
+	Init(config)
+
+	session, err := NewPropertyVersionSession(newOrchestratorTestProperty(), &Version{PropertyVersion: 1, Etag: "e1"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, session.Version.PropertyVersion)
+
+	_, err = session.Rules.SetBehaviorOption("/caching", "ttl", "7d")
+	assert.NoError(t, err)
+	session.AddHostname("api.example.com", "ehn_1")
+	assert.False(t, session.Diff().Empty())
+
+	assert.NoError(t, session.Save())
+	assert.True(t, session.Pending().Empty())
+	assert.Equal(t, "r3", session.Rules.Etag)
+
+	orchestrator := NewActivationOrchestrator(session.Property, 0)
+	orchestrator.Networks = []NetworkValue{NetworkStaging}
+	orchestrator.MinPollInterval = 0
+	orchestrator.MaxPollInterval = time.Millisecond
+
+	result, err := session.Activate(context.Background(), orchestrator)
+	assert.NoError(t, err)
+	if assert.Len(t, result.Activations, 1) {
+		assert.Equal(t, StatusActive, result.Activations[0].Status)
+	}
+	assert.True(t, gock.IsDone())
+}
+
+func TestPropertyVersionSession_Save_HostnamesFailed(t *testing.T) {
+	defer gock.Off()
+
+	Init(config)
+	session := openTestSession(t)
+
+	gock.New(orchestratorTestURL).
+		Put("/papi/v1/properties/prp_123/versions/2/rules").
+		MatchHeader("If-Match", "r2").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(sessionRulesBody(2, "r3", "7d"))
+	gock.New(orchestratorTestURL).
+		Put("/papi/v1/properties/prp_123/versions/2/hostnames").
+		MatchHeader("If-Match", "h2").
+		Reply(412).
+		SetHeader("Content-Type", "application/problem+json").
+		BodyString(`{"type": "/papi/v1/errors/precondition-failed", "title": "Precondition Failed", "status": 412}`)
+	gock.New(orchestratorTestURL).
+		Put("/papi/v1/properties/prp_123/versions/2/rules").
+		MatchHeader("If-Match", "r3").
+		AddMatcher(bodyContains(`"ttl":"1d"`)).
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(sessionRulesBody(2, "r4", "1d"))
+
+	err := session.Save()
+	assert.True(t, IsPreconditionFailed(err))
+	assert.True(t, gock.IsDone())
+
+	// the rules were restored, so both changes are still pending
+	assert.Equal(t, "r4", session.Rules.Etag)
+	pending := session.Pending()
+	assert.NotEmpty(t, pending.Rules)
+	assert.NotEmpty(t, pending.Hostnames)
+}
+
+func TestPropertyVersionSession_Save_InvalidRules(t *testing.T) {
+	defer gock.Off()
+
+	Init(config)
+	session := openTestSession(t)
+
+	gock.New(orchestratorTestURL).
+		Put("/papi/v1/properties/prp_123/versions/2/rules").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(strings.Replace(sessionRulesBody(2, "r3", "7d"), `"ruleFormat"`, `"errors": [{"type": "/papi/v1/errors/validation.required_behavior", "title": "Missing required behavior", "errorLocation": "#/rules"}], "ruleFormat"`, 1))
+	gock.New(orchestratorTestURL).
+		Put("/papi/v1/properties/prp_123/versions/2/hostnames").
+		Reply(200).
+		SetHeader("Content-Type", "application/json").
+		BodyString(sessionHostnamesBody(2, "h3"))
+
+	err := session.Save()
+	assert.Equal(t, ErrorMap[ErrInvalidRules], err)
+	assert.Len(t, session.Rules.Errors, 1)
+	assert.True(t, gock.IsDone())
+
+	// the rules were stored despite their errors
+	assert.True(t, session.Pending().Empty())
+	assert.Equal(t, "r3", session.Rules.Etag)
+}